@@ -11,9 +11,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -22,11 +24,16 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/config"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/eventbus"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/fetch"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/handlers"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/middleware"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/openapi"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -69,10 +76,95 @@ func main() {
 
 	// Initialize webhook sender BEFORE client manager
 	webhookSender := webhooks.NewSender(cfg.LaravelWebhookBase, cfg.SigningSecret)
-	log.Info().Str("webhook_base", cfg.LaravelWebhookBase).Msg("Webhook sender initialized")
+	webhookFormat, err := webhooks.ParseFormat(cfg.WebhookFormat)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid WEBHOOK_FORMAT")
+	}
+	webhookSender.SetFormat(webhookFormat)
+	webhookSender.SetEventSource(cfg.CloudEventsSource)
+
+	var deliveryLog *wa.DeliveryLog
+	if cfg.DeliveryLogEnabled {
+		deliveryLog = wa.NewDeliveryLog(dbStore, cfg.DeliveryLogRetention)
+		webhookSender.SetRecorder(deliveryLog)
+		log.Info().Dur("retention", cfg.DeliveryLogRetention).Msg("Webhook delivery log enabled")
+	}
+
+	// Event sinks, the in-process bus goes first so live streams aren't held up by webhook round trips
+	var sinks []wa.EventSink
+	eventBus := eventbus.New(cfg.EventBufferSize)
+	if cfg.EventStreamEnabled {
+		sinks = append(sinks, wa.NewBusSink(eventBus))
+		log.Info().Int("buffer_size", cfg.EventBufferSize).Msg("Event stream sink enabled")
+	}
+	if cfg.EventLogFile != "" {
+		fileSink, err := wa.NewFileSink(cfg.EventLogFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize event log sink")
+		}
+		sinks = append(sinks, fileSink)
+		log.Info().Str("path", cfg.EventLogFile).Msg("Event log sink enabled")
+	}
+	webhookBatch := webhooks.BatchConfig{MaxItems: cfg.WebhookBatchSize, MaxDelay: cfg.WebhookBatchInterval}
+	if webhookBatch.Enabled() {
+		log.Info().Int("max_items", webhookBatch.MaxItems).Dur("max_delay", webhookBatch.MaxDelay).Msg("Webhook batching enabled")
+	}
+	if cfg.WebhookSinkEnabled {
+		sinks = append(sinks, wa.NewWebhookSink(webhookSender, webhookBatch))
+		log.Info().Str("webhook_base", cfg.LaravelWebhookBase).Msg("Webhook sink enabled")
+	}
+	// Subscription endpoints come from API callers, deliveries to them are kept off internal addresses
+	subscriptionNetworks, err := fetch.ParseNetworks(cfg.SubscriptionNetworks)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid WEBHOOK_SUBSCRIPTION_ALLOWED_NETWORKS")
+	}
+	var subscriptionSink *wa.SubscriptionSink
+	if cfg.SubscriptionsEnabled {
+		subscriptionSender := webhooks.NewSender("", "")
+		subscriptionSender.SetEventSource(cfg.CloudEventsSource)
+		subscriptionSender.SetTransport(fetch.NewTransport(subscriptionNetworks))
+		if deliveryLog != nil {
+			subscriptionSender.SetRecorder(deliveryLog)
+		}
+		subscriptionSink = wa.NewSubscriptionSink(dbStore, subscriptionSender, webhookBatch)
+		sinks = append(sinks, subscriptionSink)
+		log.Info().Msg("Webhook subscription sink enabled")
+	}
+
+	// Uploaded media, sent by reference via media_id
+	mediaStore, err := media.NewStore(cfg.MediaDir, cfg.MaxMediaSize, cfg.MediaTTL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize media store")
+	}
+	defer mediaStore.Close()
+
+	// Downloads of media URLs, kept off internal addresses
+	mediaNetworks, err := fetch.ParseNetworks(cfg.MediaAllowedNetworks)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid MEDIA_ALLOWED_NETWORKS")
+	}
+	fetchOptions := fetch.Options{
+		MaxSize:         cfg.MaxMediaSize,
+		Timeout:         cfg.MediaTimeout,
+		AllowedNetworks: mediaNetworks,
+	}
+	// Media URLs sent again reuse their content instead of being downloaded, for as long as their uploads are reused
+	if cfg.MediaUploadCacheTTL > 0 {
+		urlCache, err := wa.NewMediaURLCache(dbStore, filepath.Join(cfg.MediaDir, "url-cache"), cfg.MediaUploadCacheTTL)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize media URL cache")
+		}
+		defer urlCache.Close()
+		fetchOptions.Cache = urlCache
+	}
+	mediaFetcher := fetch.New(fetchOptions)
 
-	// Initialize WhatsApp client manager WITH webhook sender
-	clientManager := wa.NewClientManager(dbStore, cfg, webhookSender)
+	// Uploads to WhatsApp, reused when the same content is sent again
+	uploadCache := wa.NewUploadCache(dbStore, cfg.MediaUploadCacheTTL)
+	defer uploadCache.Close()
+
+	// Initialize WhatsApp client manager WITH event sinks
+	clientManager := wa.NewClientManager(dbStore, cfg, sinks...)
 	log.Info().Msg("WhatsApp client manager initialized")
 
 	// Setup Gin router
@@ -89,13 +181,29 @@ func main() {
 	// Apply rate limiting to message endpoints
 	rateLimiter := middleware.NewRateLimiter(cfg.SendRatePerMinute, cfg.SendJitterMinMS, cfg.SendJitterMaxMS)
 
+	// Sends messages for the REST, WebSocket and gRPC APIs alike
+	messageHandler := handlers.NewMessageHandler(clientManager, webhookSender, mediaStore, mediaFetcher, uploadCache)
+
 	// Health endpoints (no rate limiting)
 	router.GET("/healthz", handlers.HealthCheck(dbStore))
 	router.GET("/readyz", handlers.ReadinessCheck(clientManager))
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// OpenAPI document, also used to validate every /v1 request
+	apiDoc, err := openapi.Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load OpenAPI document")
+	}
+	validateRequests, err := middleware.ValidateRequests(apiDoc)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to build request validator")
+	}
+	router.GET("/openapi.json", handlers.OpenAPISpec(apiDoc))
+	router.GET("/docs", handlers.APIDocs)
+
 	// API v1 routes
 	v1 := router.Group("/v1")
+	v1.Use(validateRequests)
 	{
 		// Session management
 		sessions := v1.Group("/sessions")
@@ -106,18 +214,85 @@ func main() {
 			sessions.POST("/:waAccountId/reconnect", h.Reconnect)
 			sessions.POST("/:waAccountId/logout", h.Logout)
 			sessions.GET("/:waAccountId/status", h.GetStatus)
+			sessions.GET("/:waAccountId/call_policy", h.GetCallPolicy)
+			sessions.POST("/:waAccountId/call_policy", h.SetCallPolicy)
+			sessions.GET("/:waAccountId/history_sync", h.GetHistorySyncProgress)
+
+			if cfg.EventStreamEnabled {
+				eh := handlers.NewEventStreamHandler(eventBus, cfg.EventHeartbeatInterval)
+				sessions.GET("/:waAccountId/events", eh.Stream)
+			}
 		}
 
+		// Bodies may carry media inline, capped before the rate limiter reads them
+		bodyLimit := middleware.LimitBody(media.RequestSizeLimit(cfg.MaxMediaSize))
+
 		// Message operations (WITH rate limiting)
 		messages := v1.Group("/messages")
-		messages.Use(rateLimiter.Limit())
+		messages.Use(bodyLimit, rateLimiter.Limit())
 		{
-			h := handlers.NewMessageHandler(clientManager, webhookSender)
+			h := messageHandler
 			messages.POST("", h.SendMessage)
 			messages.POST("/:messageId/delete", h.DeleteMessage)
 			messages.POST("/:messageId/revoke", h.RevokeMessage)
 			messages.POST("/:messageId/react", h.ReactToMessage)
 			messages.POST("/:messageId/update", h.UpdateMessage)
+
+			// Status lookups are reads without a JSON body, keep them out of the send rate limit
+			v1.GET("/messages/:messageId/status", h.GetMessageStatus)
+		}
+
+		// Media uploads, referenced by media_id when sending messages
+		mediaGroup := v1.Group("/media")
+		{
+			h := handlers.NewMediaHandler(mediaStore)
+			mediaGroup.POST("", bodyLimit, h.Upload)
+			mediaGroup.GET("/:mediaId", h.GetMedia)
+			mediaGroup.DELETE("/:mediaId", h.DeleteMedia)
+		}
+
+		// Message templates, sent with template and variables in place of a body
+		templatesGroup := v1.Group("/templates")
+		{
+			h := handlers.NewTemplateHandler(clientManager)
+			templatesGroup.GET("", h.ListTemplates)
+			templatesGroup.POST("", h.CreateTemplate)
+			templatesGroup.GET("/:templateName", h.GetTemplate)
+			templatesGroup.PUT("/:templateName", h.UpdateTemplate)
+			templatesGroup.DELETE("/:templateName", h.DeleteTemplate)
+			templatesGroup.GET("/:templateName/versions", h.ListVersions)
+		}
+
+		// Real-time WebSocket API, authorized by a token signed with the signing secret
+		if cfg.EventStreamEnabled {
+			ws := handlers.NewWebSocketHandler(clientManager, eventBus, messageHandler, rateLimiter, cfg.SigningSecret, cfg.WebSocketOrigins)
+			v1.GET("/ws", ws.Serve)
+		}
+
+		// JSON Schema documents of the webhook payloads
+		v1.GET("/schemas", handlers.ListSchemas)
+		v1.GET("/schemas/:version/:eventType", handlers.GetSchema)
+
+		// Webhook delivery log
+		if deliveryLog != nil {
+			h := handlers.NewWebhookDeliveryHandler(deliveryLog)
+			v1.GET("/webhooks/deliveries", h.ListDeliveries)
+			v1.GET("/webhooks/deliveries/:deliveryId", h.GetDelivery)
+		}
+
+		// Webhook subscriptions
+		if subscriptionSink != nil {
+			webhooksGroup := v1.Group("/webhooks/subscriptions")
+			h := handlers.NewWebhookHandler(subscriptionSink, subscriptionNetworks)
+			webhooksGroup.GET("", h.ListSubscriptions)
+			webhooksGroup.POST("", h.CreateSubscription)
+			webhooksGroup.GET("/:subscriptionId", h.GetSubscription)
+			webhooksGroup.PUT("/:subscriptionId", h.UpdateSubscription)
+			webhooksGroup.DELETE("/:subscriptionId", h.DeleteSubscription)
+
+			// Subscriptions scoped to a tenant receive the events of its accounts
+			v1.GET("/webhooks/tenants/:waAccountId", h.GetAccountTenant)
+			v1.PUT("/webhooks/tenants/:waAccountId", h.SetAccountTenant)
 		}
 
 		// Group operations
@@ -164,6 +339,7 @@ func main() {
 			chats.POST("/:chatId/read", h.MarkAsRead)
 			chats.POST("/:chatId/archive", h.ArchiveChat)
 			chats.POST("/:chatId/mute", h.MuteChat)
+			chats.GET("/:chatId/state", h.GetChatState)
 		}
 
 		// Contact operations
@@ -172,6 +348,7 @@ func main() {
 			h := handlers.NewContactHandler(clientManager)
 			contacts.GET("", h.GetContacts)
 			contacts.POST("/sync", h.SyncContacts)
+			contacts.GET("/directory", h.GetContactDirectory)
 		}
 
 		// Newsletter operations
@@ -182,6 +359,30 @@ func main() {
 		}
 	}
 
+	// gRPC API alongside the REST API, sharing the client manager, rate limiter and token auth
+	var grpcServer *grpc.Server
+	if cfg.GRPCPort != "" {
+		var grpcBus *eventbus.Bus
+		if cfg.EventStreamEnabled {
+			grpcBus = eventBus
+		}
+		gs := handlers.NewGRPCServer(clientManager, grpcBus, messageHandler, rateLimiter, cfg.SigningSecret)
+		grpcServer = grpc.NewServer(gs.ServerOptions()...)
+		gs.Register(grpcServer)
+
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
+		if err != nil {
+			log.Fatal().Err(err).Str("port", cfg.GRPCPort).Msg("Failed to listen for gRPC")
+		}
+
+		go func() {
+			log.Info().Str("port", cfg.GRPCPort).Msg("gRPC server started")
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatal().Err(err).Msg("Failed to start gRPC server")
+			}
+		}()
+	}
+
 	// Create server
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Port),
@@ -218,6 +419,16 @@ func main() {
 	log.Info().Msg("Disconnecting all WhatsApp clients...")
 	clientManager.DisconnectAll()
 
+	if err := clientManager.CloseEventSinks(); err != nil {
+		log.Error().Err(err).Msg("Error closing event sinks")
+	}
+	if deliveryLog != nil {
+		// After the sinks, so the deliveries they flushed are recorded
+		if err := deliveryLog.Close(); err != nil {
+			log.Error().Err(err).Msg("Error closing webhook delivery log")
+		}
+	}
+
 	// Shutdown HTTP server
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Error().Err(err).Msg("Server forced to shutdown")
@@ -225,5 +436,19 @@ func main() {
 		log.Info().Msg("Server exited gracefully")
 	}
 
+	if grpcServer != nil {
+		// Event streams never finish on their own, don't wait for them past the deadline
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+
 	log.Info().Msg("Shutdown complete")
 }
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Token grants access to a set of accounts. It is issued by the backend and
+// encoded as base64url(JSON) + "." + hex HMAC-SHA256 of the encoded JSON,
+// signed with the shared signing secret.
+type Token struct {
+	Accounts  []string `json:"accounts"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// ParseToken verifies a token's signature and expiry
+func ParseToken(secret, token string) (*Token, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if token == "" || !ok {
+		return nil, errors.New("missing or malformed token")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errors.New("malformed token payload")
+	}
+
+	var claims Token
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, errors.New("malformed token payload")
+	}
+
+	if claims.ExpiresAt == 0 || time.Now().Unix() > claims.ExpiresAt {
+		return nil, errors.New("token expired")
+	}
+
+	if len(claims.Accounts) == 0 {
+		return nil, errors.New("token grants no accounts")
+	}
+
+	return &claims, nil
+}
+
+// BearerToken extracts the token from an Authorization header value
+func BearerToken(header string) string {
+	if strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return ""
+}
+
+func (t *Token) Allows(waAccountID string) bool {
+	for _, id := range t.Accounts {
+		if id == waAccountID {
+			return true
+		}
+	}
+	return false
+}
@@ -9,12 +9,15 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
 	Port                    string
+	GRPCPort                string
 	Env                     string
 	DatabaseURL             string
 	LaravelWebhookBase      string
@@ -27,11 +30,35 @@ type Config struct {
 	WebhookTimeout          time.Duration
 	WebhookRetryMax         int
 	WebhookRetryBackoffBase time.Duration
+	WebhookBatchSize        int
+	WebhookBatchInterval    time.Duration
+	WebhookFormat           string
+	CloudEventsSource       string
+	DeliveryLogEnabled      bool
+	DeliveryLogRetention    time.Duration
+	CallAutoReject          bool
+	CallRejectMessage       string
+	EventBufferSize         int
+	EventHeartbeatInterval  time.Duration
+	WebSocketOrigins        []string
+	WebhookSinkEnabled      bool
+	SubscriptionsEnabled    bool
+	SubscriptionNetworks    []string
+	EventStreamEnabled      bool
+	EventLogFile            string
+	MaxMediaSize            int64
+	MediaDir                string
+	MediaTTL                time.Duration
+	MediaTimeout            time.Duration
+	MediaAllowedNetworks    []string
+	MediaUploadCacheTTL     time.Duration
+	LiveMessageRetention    time.Duration
 }
 
 func Load() (*Config, error) {
 	cfg := &Config{
 		Port:                    getEnv("PORT", "4001"),
+		GRPCPort:                getEnv("GRPC_PORT", ""),
 		Env:                     getEnv("APP_ENV", "production"),
 		DatabaseURL:             getEnv("DATABASE_URL", ""),
 		LaravelWebhookBase:      getEnv("LARAVEL_WEBHOOK_BASE", ""),
@@ -44,13 +71,36 @@ func Load() (*Config, error) {
 		WebhookTimeout:          getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
 		WebhookRetryMax:         getIntEnv("WEBHOOK_RETRY_MAX", 3),
 		WebhookRetryBackoffBase: getDurationEnv("WEBHOOK_RETRY_BACKOFF_BASE", 2*time.Second),
+		WebhookBatchSize:        getIntEnv("WEBHOOK_BATCH_SIZE", 0),
+		WebhookBatchInterval:    getDurationEnv("WEBHOOK_BATCH_INTERVAL", 250*time.Millisecond),
+		WebhookFormat:           getEnv("WEBHOOK_FORMAT", "native"),
+		CloudEventsSource:       getEnv("CLOUDEVENTS_SOURCE", "/go-whatsapp-service"),
+		DeliveryLogEnabled:      getBoolEnv("WEBHOOK_DELIVERY_LOG_ENABLED", true),
+		DeliveryLogRetention:    getDurationEnv("WEBHOOK_DELIVERY_LOG_RETENTION", 72*time.Hour),
+		CallAutoReject:          getBoolEnv("CALL_AUTO_REJECT", false),
+		CallRejectMessage:       getEnv("CALL_REJECT_MESSAGE", ""),
+		EventBufferSize:         getIntEnv("EVENT_BUFFER_SIZE", 500),
+		EventHeartbeatInterval:  getDurationEnv("EVENT_HEARTBEAT_INTERVAL", 15*time.Second),
+		WebSocketOrigins:        getListEnv("WS_ALLOWED_ORIGINS"),
+		WebhookSinkEnabled:      getBoolEnv("WEBHOOK_SINK_ENABLED", true),
+		SubscriptionsEnabled:    getBoolEnv("WEBHOOK_SUBSCRIPTIONS_ENABLED", true),
+		SubscriptionNetworks:    getListEnv("WEBHOOK_SUBSCRIPTION_ALLOWED_NETWORKS"),
+		EventStreamEnabled:      getBoolEnv("EVENT_STREAM_ENABLED", true),
+		EventLogFile:            getEnv("EVENT_LOG_FILE", ""),
+		MaxMediaSize:            int64(getIntEnv("MAX_MEDIA_SIZE", 16<<20)),
+		MediaDir:                getEnv("MEDIA_DIR", filepath.Join(os.TempDir(), "go-wa-media")),
+		MediaTTL:                getDurationEnv("MEDIA_TTL", 24*time.Hour),
+		MediaTimeout:            getDurationEnv("MEDIA_TIMEOUT", 120*time.Second),
+		MediaAllowedNetworks:    getListEnv("MEDIA_ALLOWED_NETWORKS"),
+		MediaUploadCacheTTL:     getDurationEnv("MEDIA_UPLOAD_CACHE_TTL", 24*time.Hour),
+		LiveMessageRetention:    getDurationEnv("LIVE_MESSAGE_RETENTION", 7*24*time.Hour),
 	}
 
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
 
-	if cfg.LaravelWebhookBase == "" {
+	if cfg.WebhookSinkEnabled && cfg.LaravelWebhookBase == "" {
 		return nil, fmt.Errorf("LARAVEL_WEBHOOK_BASE is required")
 	}
 
@@ -77,6 +127,25 @@ func getIntEnv(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func getBoolEnv(key string, defaultVal bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+func getListEnv(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getDurationEnv(key string, defaultVal time.Duration) time.Duration {
 	if val := os.Getenv(key); val != "" {
 		if d, err := time.ParseDuration(val); err == nil {
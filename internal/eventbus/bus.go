@@ -0,0 +1,212 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped. Dropped subscribers can resume from the ring buffer.
+const subscriberBuffer = 256
+
+type Event struct {
+	ID            uint64      `json:"id"`
+	Type          string      `json:"event_type"`
+	SchemaVersion int         `json:"schema_version"`
+	WaAccountID   string      `json:"wa_account_id"`
+	Timestamp     time.Time   `json:"timestamp"`
+	Data          interface{} `json:"data"`
+}
+
+// Bus fans out account events to in-process subscribers and keeps the most
+// recent events of every account in a bounded ring buffer for resuming
+type Bus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	bufferSize  int
+	rings       map[string]*ring
+	subscribers map[string]map[*Subscription]struct{}
+}
+
+func New(bufferSize int) *Bus {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	return &Bus{
+		bufferSize:  bufferSize,
+		rings:       make(map[string]*ring),
+		subscribers: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+func (b *Bus) Publish(waAccountID, eventType string, schemaVersion int, timestamp time.Time, data interface{}) {
+	if waAccountID == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	evt := Event{
+		ID:            b.lastID,
+		Type:          eventType,
+		SchemaVersion: schemaVersion,
+		WaAccountID:   waAccountID,
+		Timestamp:     timestamp,
+		Data:          data,
+	}
+
+	r, ok := b.rings[waAccountID]
+	if !ok {
+		r = newRing(b.bufferSize)
+		b.rings[waAccountID] = r
+	}
+	r.push(evt)
+
+	for sub := range b.subscribers[waAccountID] {
+		if !sub.wants(eventType) {
+			continue
+		}
+
+		select {
+		case sub.events <- evt:
+		default:
+			// Never block publishers on a slow consumer
+			b.removeLocked(sub)
+			sub.dropped = true
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for an account. If afterID is non-zero,
+// buffered events newer than afterID are returned as backlog; complete is
+// false when some of those events are no longer available.
+// Types restricts the subscription to the given event types, all if empty.
+func (b *Bus) Subscribe(waAccountID string, types []string, afterID uint64) (sub *Subscription, backlog []Event, complete bool) {
+	sub = &Subscription{
+		bus:         b,
+		waAccountID: waAccountID,
+		events:      make(chan Event, subscriberBuffer),
+	}
+	if len(types) > 0 {
+		sub.types = make(map[string]struct{}, len(types))
+		for _, t := range types {
+			sub.types[t] = struct{}{}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	if afterID > b.lastID {
+		// IDs restart with the process, an unknown ID means everything
+		// buffered is new to the subscriber and older events are lost
+		afterID = 0
+		complete = false
+	}
+
+	if r, ok := b.rings[waAccountID]; ok && (afterID > 0 || !complete) {
+		events, all := r.since(afterID)
+		complete = complete && all
+		for _, evt := range events {
+			if sub.wants(evt.Type) {
+				backlog = append(backlog, evt)
+			}
+		}
+	}
+
+	if b.subscribers[waAccountID] == nil {
+		b.subscribers[waAccountID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[waAccountID][sub] = struct{}{}
+
+	return sub, backlog, complete
+}
+
+func (b *Bus) removeLocked(sub *Subscription) {
+	subs := b.subscribers[sub.waAccountID]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscribers, sub.waAccountID)
+	}
+}
+
+type Subscription struct {
+	bus         *Bus
+	waAccountID string
+	types       map[string]struct{}
+	events      chan Event
+	dropped     bool
+	closed      bool
+}
+
+// Events is closed when the subscription is closed or dropped for falling behind
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+
+	if !s.dropped {
+		s.bus.removeLocked(s)
+		close(s.events)
+	}
+}
+
+func (s *Subscription) wants(eventType string) bool {
+	if s.types == nil {
+		return true
+	}
+	_, ok := s.types[eventType]
+	return ok
+}
+
+type ring struct {
+	events []Event
+	start  int
+	count  int
+}
+
+func newRing(size int) *ring {
+	return &ring{events: make([]Event, size)}
+}
+
+func (r *ring) push(evt Event) {
+	if r.count < len(r.events) {
+		r.events[(r.start+r.count)%len(r.events)] = evt
+		r.count++
+		return
+	}
+
+	r.events[r.start] = evt
+	r.start = (r.start + 1) % len(r.events)
+}
+
+// since returns the buffered events with an ID greater than afterID and
+// whether the buffer still held every such event
+func (r *ring) since(afterID uint64) ([]Event, bool) {
+	var events []Event
+	complete := r.count < len(r.events)
+
+	for i := 0; i < r.count; i++ {
+		evt := r.events[(r.start+i)%len(r.events)]
+		if evt.ID <= afterID {
+			complete = true
+			continue
+		}
+		events = append(events, evt)
+	}
+
+	return events, complete
+}
@@ -0,0 +1,436 @@
+// Package fetch downloads remote media for messages. Requests only reach
+// public addresses, checked on every connection after DNS resolution so
+// redirects and rebinding can't reach internal services, and bodies are
+// capped while streaming.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	maxRedirects = 5
+	dialTimeout  = 10 * time.Second
+	userAgent    = "go-whatsapp-service/1.0 (media fetch)"
+)
+
+var (
+	ErrInvalidURL         = errors.New("invalid URL")
+	ErrBlockedAddress     = errors.New("address is not allowed")
+	ErrTooManyRedirects   = errors.New("too many redirects")
+	ErrTooLarge           = errors.New("content exceeds the maximum size")
+	ErrUnsupportedType    = errors.New("unsupported content type")
+	ErrTimeout            = errors.New("request timed out")
+	ErrUnreachable        = errors.New("host is unreachable")
+	ErrUnexpectedResponse = errors.New("unexpected response")
+)
+
+// StatusError is a response other than 200 OK
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server responded with status %d", e.StatusCode)
+}
+
+// blockedNetworks are special purpose ranges not covered by the netip.Addr
+// predicates used in isAllowed
+var blockedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("fec0::/10"), // deprecated site-local
+}
+
+var (
+	sixToFourNetwork = netip.MustParsePrefix("2002::/16")
+	nat64Network     = netip.MustParsePrefix("64:ff9b::/96")
+)
+
+// Options configures a Fetcher. AllowedNetworks are exempt from the address
+// checks, for media servers on the internal network. With a Cache, content
+// of URLs fetched before is reused without a request, or revalidated when its
+// server asked for that, instead of downloaded again.
+type Options struct {
+	MaxSize         int64
+	Timeout         time.Duration
+	AllowedNetworks []netip.Prefix
+	Cache           Cache
+}
+
+// Cached is an earlier response to a URL with the validators that tell
+// whether it changed. Revalidate is set when the server asked to be checked
+// with before the content is used again.
+type Cached struct {
+	ContentType  string
+	ETag         string
+	LastModified string
+	Revalidate   bool
+	Data         []byte
+}
+
+// Cache keeps responses to URLs. Lookup returns nil for URLs it doesn't have
+// or that expired, failures of either method only cost the download.
+type Cache interface {
+	Lookup(rawURL string) *Cached
+	Store(rawURL string, response *Cached)
+}
+
+// Fetcher downloads media over HTTP(S) with bounded size, time and redirects
+type Fetcher struct {
+	httpClient *http.Client
+	transport  *http.Transport
+	maxSize    int64
+	cache      Cache
+}
+
+func New(opts Options) *Fetcher {
+	transport := NewTransport(opts.AllowedNetworks)
+
+	return &Fetcher{
+		httpClient: &http.Client{
+			Transport:     transport,
+			Timeout:       opts.Timeout,
+			CheckRedirect: checkRedirect,
+		},
+		transport: transport,
+		maxSize:   opts.MaxSize,
+		cache:     opts.Cache,
+	}
+}
+
+// NewTransport returns an HTTP transport that only connects to public
+// addresses and those in allowed. Proxies from the environment are not used,
+// the checks would only see the proxy.
+func NewTransport(allowed []netip.Prefix) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+			}
+			if !isAllowed(addrPort.Addr(), allowed) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// Transport returns the guarded transport, for other clients fetching URLs
+// given by API callers
+func (f *Fetcher) Transport() http.RoundTripper {
+	return f.transport
+}
+
+func (f *Fetcher) MaxSize() int64 {
+	return f.maxSize
+}
+
+// Get downloads the content at rawURL. If accept lists media types, like
+// "image/*", a response declaring another type is rejected; responses without
+// a type or with a generic binary one are let through. Content in the cache
+// is used without a request, unless its server asked for revalidation; then
+// it's only downloaded again if the server reports it changed.
+func (f *Fetcher) Get(ctx context.Context, rawURL string, accept ...string) ([]byte, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if err := checkURL(target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+
+	var cached *Cached
+	if f.cache != nil {
+		cached = f.cache.Lookup(rawURL)
+	}
+	if cached != nil && !cached.Revalidate {
+		return f.cachedContent(cached, accept)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, classify(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return f.notModified(rawURL, resp, cached, accept)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !acceptable(contentType, accept) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	if f.maxSize > 0 && resp.ContentLength > f.maxSize {
+		return nil, ErrTooLarge
+	}
+
+	body := io.Reader(resp.Body)
+	if f.maxSize > 0 {
+		body = io.LimitReader(resp.Body, f.maxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, classify(err)
+	}
+	if f.maxSize > 0 && int64(len(data)) > f.maxSize {
+		return nil, ErrTooLarge
+	}
+
+	if f.cache != nil && cacheable(resp) {
+		f.cache.Store(rawURL, &Cached{
+			ContentType:  contentType,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Revalidate:   mustRevalidate(resp),
+			Data:         data,
+		})
+	}
+
+	return data, nil
+}
+
+// cachedContent returns cached content, checked like a download
+func (f *Fetcher) cachedContent(cached *Cached, accept []string) ([]byte, error) {
+	if !acceptable(cached.ContentType, accept) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, cached.ContentType)
+	}
+	if f.maxSize > 0 && int64(len(cached.Data)) > f.maxSize {
+		return nil, ErrTooLarge
+	}
+	return cached.Data, nil
+}
+
+// notModified returns the cached content the server confirmed is current,
+// storing the validators it sent along
+func (f *Fetcher) notModified(rawURL string, resp *http.Response, cached *Cached, accept []string) ([]byte, error) {
+	data, err := f.cachedContent(cached, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	revalidated := *cached
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		revalidated.ETag = etag
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		revalidated.LastModified = lastModified
+	}
+	f.cache.Store(rawURL, &revalidated)
+
+	return data, nil
+}
+
+// ParseNetworks parses CIDR prefixes and plain IP addresses
+func ParseNetworks(values []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if addr, err := netip.ParseAddr(value); err == nil {
+			networks = append(networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", value, err)
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// CheckURL validates a URL that will be requested later, like a webhook
+// endpoint, and resolves its host: every address must be allowed. The
+// transport checks connections again, the host may resolve differently then.
+func CheckURL(ctx context.Context, rawURL string, allowed []netip.Prefix) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if err := checkURL(target); err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return classify(err)
+	}
+	for _, addr := range addrs {
+		if !isAllowed(addr, allowed) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// isAllowed reports whether a connection to addr may be made: addresses in
+// allowed, otherwise only public unicast addresses
+func isAllowed(addr netip.Addr, allowed []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range blockedNetworks {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	// IPv6 prefixes that embed an IPv4 address are judged by that address
+	if addr.Is6() {
+		b := addr.As16()
+		switch {
+		case sixToFourNetwork.Contains(addr):
+			return isAllowed(netip.AddrFrom4([4]byte{b[2], b[3], b[4], b[5]}), nil)
+		case nat64Network.Contains(addr):
+			return isAllowed(netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}), nil)
+		}
+	}
+
+	return true
+}
+
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q is not supported", ErrInvalidURL, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+	return nil
+}
+
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, maxRedirects)
+	}
+	return checkURL(req.URL)
+}
+
+// cacheable reports whether a response may be stored, responses that have to
+// be revalidated only when they can be
+func cacheable(resp *http.Response) bool {
+	if hasDirective(resp, "no-store") {
+		return false
+	}
+	if mustRevalidate(resp) {
+		return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+	}
+	return true
+}
+
+// mustRevalidate reports whether the server asked to be checked with before
+// a response is used again
+func mustRevalidate(resp *http.Response) bool {
+	return hasDirective(resp, "no-cache") || hasDirective(resp, "max-age=0")
+}
+
+func hasDirective(resp *http.Response, directive string) bool {
+	for _, d := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(d), directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptable reports whether a response content type matches one of the
+// accepted media types
+func acceptable(contentType string, accept []string) bool {
+	if len(accept) == 0 || contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if mediaType == "application/octet-stream" || mediaType == "binary/octet-stream" {
+		return true
+	}
+
+	for _, pattern := range accept {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// classify maps transport errors onto the errors of this package, keeping
+// ones that already are
+func classify(err error) error {
+	for _, known := range []error{ErrInvalidURL, ErrBlockedAddress, ErrTooManyRedirects} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) {
+		return fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	return fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+}
@@ -0,0 +1,337 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsAllowed(t *testing.T) {
+	internal := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("fd00::/64")}
+
+	tests := []struct {
+		addr    string
+		allowed []netip.Prefix
+		want    bool
+	}{
+		{"93.184.216.34", nil, true},
+		{"2606:2800:220:1::1", nil, true},
+		{"::ffff:93.184.216.34", nil, true},
+		{"127.0.0.1", nil, false},
+		{"::1", nil, false},
+		{"::ffff:127.0.0.1", nil, false},
+		{"10.1.2.3", nil, false},
+		{"172.16.0.1", nil, false},
+		{"192.168.1.1", nil, false},
+		{"169.254.169.254", nil, false},
+		{"fe80::1", nil, false},
+		{"fd00::1", nil, false},
+		{"0.0.0.0", nil, false},
+		{"::", nil, false},
+		{"100.64.0.1", nil, false},
+		{"198.18.0.1", nil, false},
+		{"224.0.0.1", nil, false},
+		{"255.255.255.255", nil, false},
+		{"2001:db8::1", nil, false},
+		{"2002:7f00:1::", nil, false},      // 6to4 of 127.0.0.1
+		{"2002:5db8:d822::", nil, true},    // 6to4 of 93.184.216.34
+		{"64:ff9b::a9fe:a9fe", nil, false}, // NAT64 of 169.254.169.254
+		{"64:ff9b::5db8:d822", nil, true},  // NAT64 of 93.184.216.34
+		{"64:ff9b:1::1", nil, false},
+		{"10.1.2.3", internal, true},
+		{"::ffff:10.1.2.3", internal, true},
+		{"10.2.0.1", internal, false},
+		{"fd00::1", internal, true},
+		{"2002:0a01:0203::", internal, false}, // 6to4 of an allowed address isn't
+	}
+
+	for _, tt := range tests {
+		if got := isAllowed(netip.MustParseAddr(tt.addr), tt.allowed); got != tt.want {
+			t.Errorf("isAllowed(%s, %v) = %v, want %v", tt.addr, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	got, err := ParseNetworks([]string{"10.0.0.0/8", "192.168.1.7/24", "172.16.0.5", "::ffff:10.0.0.1", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("ParseNetworks: %v", err)
+	}
+	want := []string{"10.0.0.0/8", "192.168.1.0/24", "172.16.0.5/32", "10.0.0.1/32", "fd00::/8"}
+	if len(got) != len(want) {
+		t.Fatalf("ParseNetworks = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("network %d is %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	for _, value := range []string{"", "10.0.0.0/33", "example.com", "10.0.0"} {
+		if _, err := ParseNetworks([]string{value}); err == nil {
+			t.Errorf("ParseNetworks(%q) succeeded", value)
+		}
+	}
+}
+
+func TestAcceptable(t *testing.T) {
+	tests := []struct {
+		contentType string
+		accept      []string
+		want        bool
+	}{
+		{"image/png", nil, true},
+		{"", []string{"image/*"}, true},
+		{"image/png", []string{"image/*"}, true},
+		{"IMAGE/PNG; charset=binary", []string{"image/*"}, true},
+		{"application/octet-stream", []string{"image/*"}, true},
+		{"text/html", []string{"image/*", "video/*"}, false},
+		{"application/pdf", []string{"application/pdf"}, true},
+		{"application/pdfx", []string{"application/pdf"}, false},
+		{"imagery/png", []string{"image/*"}, false},
+		{"not a type;;", []string{"image/*"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptable(tt.contentType, tt.accept); got != tt.want {
+			t.Errorf("acceptable(%q, %v) = %v, want %v", tt.contentType, tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	req := func(rawURL string) *http.Request {
+		u, _ := url.Parse(rawURL)
+		return &http.Request{URL: u}
+	}
+
+	if err := checkRedirect(req("https://example.com/b"), make([]*http.Request, maxRedirects-1)); err != nil {
+		t.Errorf("redirect %d: %v", maxRedirects, err)
+	}
+	if err := checkRedirect(req("https://example.com/b"), make([]*http.Request, maxRedirects)); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("redirect %d = %v, want ErrTooManyRedirects", maxRedirects+1, err)
+	}
+	if err := checkRedirect(req("file:///etc/passwd"), nil); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("redirect to a file = %v, want ErrInvalidURL", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>"))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 17))
+	})
+	mux.HandleFunc("/streamed", func(w http.ResponseWriter, r *http.Request) {
+		// Without a declared length the limit applies while reading
+		for range 17 {
+			w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+		}
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/image", http.StatusMovedPermanently)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := New(Options{
+		MaxSize:         16,
+		Timeout:         200 * time.Millisecond,
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		path    string
+		accept  []string
+		want    string
+		wantErr error
+	}{
+		{"/image", []string{"image/*"}, "png", nil},
+		{"/moved", []string{"image/*"}, "png", nil},
+		{"/page", nil, "<html>", nil},
+		{"/page", []string{"image/*"}, "", ErrUnsupportedType},
+		{"/large", nil, "", ErrTooLarge},
+		{"/streamed", nil, "", ErrTooLarge},
+		{"/slow", nil, "", ErrTimeout},
+		{"/loop", nil, "", ErrTooManyRedirects},
+	}
+
+	for _, tt := range tests {
+		data, err := fetcher.Get(ctx, server.URL+tt.path, tt.accept...)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get(%s) = %v, want %v", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(data) != tt.want {
+			t.Errorf("Get(%s) = %q, %v, want %q", tt.path, data, err, tt.want)
+		}
+	}
+
+	var statusErr *StatusError
+	if _, err := fetcher.Get(ctx, server.URL+"/missing"); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Get of a missing page = %v, want status 404", err)
+	}
+	if _, err := fetcher.Get(ctx, "ftp://"+strings.TrimPrefix(server.URL, "http://")); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("Get of an FTP URL = %v, want ErrInvalidURL", err)
+	}
+
+	// Without the allowed network the connection is refused before it's made
+	blocked := New(Options{MaxSize: 16, Timeout: time.Second})
+	if _, err := blocked.Get(ctx, server.URL+"/image"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Get of a loopback server = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	allowed := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}
+
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{"https://93.184.216.34/hook", nil},
+		{"http://10.1.0.5:8080/hook", nil},
+		{"https://[2606:2800:220:1::1]/hook", nil},
+		{"http://127.0.0.1/hook", ErrBlockedAddress},
+		{"http://[::1]/hook", ErrBlockedAddress},
+		{"http://169.254.169.254/latest/meta-data", ErrBlockedAddress},
+		{"http://10.2.0.5/hook", ErrBlockedAddress},
+		{"gopher://93.184.216.34/", ErrInvalidURL},
+		{"https:///hook", ErrInvalidURL},
+		{"http://%zz/", ErrInvalidURL},
+	}
+
+	for _, tt := range tests {
+		err := CheckURL(context.Background(), tt.url, allowed)
+		if (tt.wantErr == nil && err != nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+			t.Errorf("CheckURL(%s) = %v, want %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+// mapCache is a Cache in memory
+type mapCache map[string]*Cached
+
+func (c mapCache) Lookup(rawURL string) *Cached {
+	return c[rawURL]
+}
+
+func (c mapCache) Store(rawURL string, response *Cached) {
+	c[rawURL] = response
+}
+
+func TestGetCaches(t *testing.T) {
+	version := "v1"
+	downloads, requests := 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fresh", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("fresh"))
+	})
+	mux.HandleFunc("/etag", func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + version + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(version))
+	})
+	mux.HandleFunc("/modified", func(w http.ResponseWriter, r *http.Request) {
+		modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Write([]byte("modified"))
+	})
+	mux.HandleFunc("/no-store", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"x"`)
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Write([]byte("secret"))
+	})
+	mux.HandleFunc("/unvalidated", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write([]byte("plain"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cache := mapCache{}
+	fetcher := New(Options{
+		MaxSize:         16,
+		Timeout:         time.Second,
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")},
+		Cache:           cache,
+	})
+	get := func(path string, accept ...string) string {
+		t.Helper()
+		data, err := fetcher.Get(context.Background(), server.URL+path, accept...)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", path, err)
+		}
+		return string(data)
+	}
+
+	// Content is reused without asking the server, checked like a download
+	if get("/fresh", "image/*") != "fresh" || get("/fresh", "image/*") != "fresh" || requests != 1 {
+		t.Errorf("cached URL requested %d times, want once", requests)
+	}
+	if _, err := fetcher.Get(context.Background(), server.URL+"/fresh", "video/*"); !errors.Is(err, ErrUnsupportedType) || requests != 1 {
+		t.Errorf("cached image fetched as video = %v after %d requests, want ErrUnsupportedType without a request", err, requests)
+	}
+
+	// Content served with no-cache is revalidated before each use
+	if get("/etag", "image/*") != "v1" || get("/etag", "image/*") != "v1" || downloads != 1 {
+		t.Errorf("unchanged ETag URL downloaded %d times, want once", downloads)
+	}
+	version = "v2"
+	if got := get("/etag"); got != "v2" || downloads != 2 || cache[server.URL+"/etag"].ETag != `"v2"` {
+		t.Errorf("changed ETag URL = %q after %d downloads, want v2 after 2", got, downloads)
+	}
+	// The cached content type is checked like a downloaded one
+	if _, err := fetcher.Get(context.Background(), server.URL+"/etag", "video/*"); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("revalidated image fetched as video = %v, want ErrUnsupportedType", err)
+	}
+
+	downloads = 0
+	if get("/modified") != "modified" || get("/modified") != "modified" || downloads != 1 {
+		t.Errorf("unmodified URL downloaded %d times, want once", downloads)
+	}
+
+	get("/no-store")
+	get("/unvalidated")
+	if cache[server.URL+"/no-store"] != nil || cache[server.URL+"/unvalidated"] != nil {
+		t.Errorf("cached responses that can't be kept or can't be revalidated")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/appstate"
 	"go.mau.fi/whatsmeow/types"
 )
 
@@ -167,8 +168,8 @@ func (h *AccountHandler) ChangeAvatar(c *gin.Context) {
 		return
 	}
 
-	// Fixed: Added ctx parameter
-	pictureID, err := mc.Client.SetProfilePicture(ctx, avatarBytes)
+	// Without a target the picture of the account itself is set
+	pictureID, err := mc.Client.SetGroupPhoto(ctx, types.EmptyJID, avatarBytes)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to set avatar")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -221,8 +222,8 @@ func (h *AccountHandler) RemoveAvatar(c *gin.Context) {
 		return
 	}
 
-	// Fixed: Added ctx parameter and pass nil to remove avatar
-	_, err = mc.Client.SetProfilePicture(ctx, nil)
+	// No picture removes the current one
+	_, err = mc.Client.SetGroupPhoto(ctx, types.EmptyJID, nil)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to remove avatar")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -279,8 +280,8 @@ func (h *AccountHandler) ChangePushName(c *gin.Context) {
 		return
 	}
 
-	// Fixed: Added ctx parameter
-	err = mc.Client.SetPushName(ctx, req.PushName)
+	// The push name is an app state setting synced to the other devices
+	err = mc.Client.SendAppState(ctx, appstate.BuildSettingPushName(req.PushName))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to set push name")
 		c.JSON(http.StatusInternalServerError, gin.H{
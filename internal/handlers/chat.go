@@ -20,9 +20,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
+	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
 	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
 )
 
 type ChatHandler struct {
@@ -99,7 +102,7 @@ func (h *ChatHandler) ListChats(c *gin.Context) {
 	}
 
 	// Get all contacts as a proxy for chats
-	contacts, err := mc.Client.Store.Contacts.GetAllContacts()
+	contacts, err := mc.Client.Store.Contacts.GetAllContacts(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get contacts")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -262,27 +265,8 @@ func (h *ChatHandler) PinChat(c *gin.Context) {
 		return
 	}
 
-	// Fixed: SetChatPin doesn't exist - must use app state
-	// Create app state mutation for pinning/unpinning chat
-	var action appstate.ChatAction
-	if req.Pinned {
-		action = appstate.ChatActionPin
-	} else {
-		action = appstate.ChatActionUnpin
-	}
-
-	mutation := appstate.Mutation{
-		Action: appstate.MutationActionSet,
-		Index:  []string{chatJID.String()},
-		Value: appstate.ChatSettings{
-			Action: action,
-		},
-	}
-
-	err = mc.Client.SendAppState(ctx, appstate.PatchInfo{
-		Type:      appstate.WAPatchRegularHigh,
-		Mutations: []appstate.Mutation{mutation},
-	})
+	// Chat pins are app state, there is no direct call for them
+	err = mc.Client.SendAppState(ctx, appstate.BuildPin(chatJID, req.Pinned))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to pin chat")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -293,6 +277,10 @@ func (h *ChatHandler) PinChat(c *gin.Context) {
 		return
 	}
 
+	if err := h.clientManager.UpdateChatState(req.WaAccountID, chatJID, store.ChatStateUpdate{Pinned: &req.Pinned}); err != nil {
+		log.Error().Err(err).Msg("Failed to record chat pin state")
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
 		"pinned":     req.Pinned,
@@ -346,9 +334,7 @@ func (h *ChatHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	// Fixed: Added ctx parameter as first argument
-	// Signature: MarkRead(ctx context.Context, ids []types.MessageID, timestamp time.Time, chat, sender types.JID, receiptTypeExtra ...types.ReceiptType) error
-	err = mc.Client.MarkRead(ctx, []types.MessageID{}, time.Now(), chatJID, types.EmptyJID)
+	err = markRead(ctx, mc.Client, chatJID, []types.MessageID{}, types.EmptyJID)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to mark chat as read")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -365,6 +351,13 @@ func (h *ChatHandler) MarkAsRead(c *gin.Context) {
 	})
 }
 
+// markRead sends read receipts for a chat, it is shared by the HTTP and WebSocket APIs
+func markRead(ctx context.Context, client *whatsmeow.Client, chatJID types.JID, ids []types.MessageID, sender types.JID) error {
+	// Fixed: Added ctx parameter as first argument
+	// Signature: MarkRead(ctx context.Context, ids []types.MessageID, timestamp time.Time, chat, sender types.JID, receiptTypeExtra ...types.ReceiptType) error
+	return client.MarkRead(ctx, ids, time.Now(), chatJID, sender)
+}
+
 func (h *ChatHandler) ArchiveChat(c *gin.Context) {
 	chatID := c.Param("chatId")
 	requestID := c.GetString("request_id")
@@ -411,26 +404,8 @@ func (h *ChatHandler) ArchiveChat(c *gin.Context) {
 		return
 	}
 
-	// Fixed: SetChatArchive doesn't exist - must use app state
-	var action appstate.ChatAction
-	if req.Archived {
-		action = appstate.ChatActionArchive
-	} else {
-		action = appstate.ChatActionUnarchive
-	}
-
-	mutation := appstate.Mutation{
-		Action: appstate.MutationActionSet,
-		Index:  []string{chatJID.String()},
-		Value: appstate.ChatSettings{
-			Action: action,
-		},
-	}
-
-	err = mc.Client.SendAppState(ctx, appstate.PatchInfo{
-		Type:      appstate.WAPatchRegularHigh,
-		Mutations: []appstate.Mutation{mutation},
-	})
+	// Archiving is app state, there is no direct call for it
+	err = mc.Client.SendAppState(ctx, appstate.BuildArchive(chatJID, req.Archived, time.Time{}, nil))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to archive chat")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -441,6 +416,10 @@ func (h *ChatHandler) ArchiveChat(c *gin.Context) {
 		return
 	}
 
+	if err := h.clientManager.UpdateChatState(req.WaAccountID, chatJID, store.ChatStateUpdate{Archived: &req.Archived}); err != nil {
+		log.Error().Err(err).Msg("Failed to record chat archive state")
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
 		"archived":   req.Archived,
@@ -494,10 +473,9 @@ func (h *ChatHandler) MuteChat(c *gin.Context) {
 		return
 	}
 
-	// Fixed: SetChatMute doesn't exist - must use app state
+	// Muting is app state, there is no direct call for it
 	var muteEndTime time.Time
-	var action appstate.ChatAction
-
+	var muteEnd *int64
 	if req.Muted {
 		if req.Duration > 0 {
 			muteEndTime = time.Now().Add(time.Duration(req.Duration) * time.Second)
@@ -505,24 +483,10 @@ func (h *ChatHandler) MuteChat(c *gin.Context) {
 			// Permanent mute (8 years from now as WhatsApp doesn't support truly permanent)
 			muteEndTime = time.Now().Add(8 * 365 * 24 * time.Hour)
 		}
-		action = appstate.ChatActionMute
-	} else {
-		action = appstate.ChatActionUnmute
-	}
-
-	mutation := appstate.Mutation{
-		Action: appstate.MutationActionSet,
-		Index:  []string{chatJID.String()},
-		Value: appstate.ChatSettings{
-			Action:      action,
-			MuteEndTime: muteEndTime.Unix(),
-		},
+		muteEnd = proto.Int64(muteEndTime.UnixMilli())
 	}
 
-	err = mc.Client.SendAppState(ctx, appstate.PatchInfo{
-		Type:      appstate.WAPatchRegularHigh,
-		Mutations: []appstate.Mutation{mutation},
-	})
+	err = mc.Client.SendAppState(ctx, appstate.BuildMuteAbs(chatJID, req.Muted, muteEnd))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to mute chat")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -533,6 +497,14 @@ func (h *ChatHandler) MuteChat(c *gin.Context) {
 		return
 	}
 
+	muteUpdate := store.ChatStateUpdate{Muted: &req.Muted}
+	if req.Muted {
+		muteUpdate.MutedUntil = &muteEndTime
+	}
+	if err := h.clientManager.UpdateChatState(req.WaAccountID, chatJID, muteUpdate); err != nil {
+		log.Error().Err(err).Msg("Failed to record chat mute state")
+	}
+
 	response := gin.H{
 		"success":    true,
 		"muted":      req.Muted,
@@ -545,3 +517,54 @@ func (h *ChatHandler) MuteChat(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *ChatHandler) GetChatState(c *gin.Context) {
+	chatID := c.Param("chatId")
+	waAccountID := c.Query("wa_account_id")
+	requestID := c.GetString("request_id")
+
+	if waAccountID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "missing_parameter",
+			"message":    "wa_account_id is required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	chatJID, err := types.ParseJID(chatID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_chat_id",
+			"message":    "invalid chat JID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	state, err := h.clientManager.GetChatState(waAccountID, chatJID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get chat state")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "chat_state_failed",
+			"message":    "failed to get chat state",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if state == nil {
+		state = &store.ChatState{ChatJID: chatJID.String(), Labels: []string{}}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chat_id":     chatJID.String(),
+		"archived":    state.Archived,
+		"pinned":      state.Pinned,
+		"muted":       state.Muted,
+		"muted_until": state.MutedUntil,
+		"labels":      state.Labels,
+		"updated_at":  state.UpdatedAt,
+		"request_id":  requestID,
+	})
+}
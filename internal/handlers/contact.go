@@ -12,6 +12,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -62,9 +63,8 @@ func (h *ContactHandler) GetContacts(c *gin.Context) {
 		return
 	}
 
-	// This is correct - Store.Contacts.GetAllContacts() doesn't need context
-	// It's a database/store operation, not a WhatsApp API call
-	contacts, err := mc.Client.Store.Contacts.GetAllContacts()
+	// A read of the device store, not a WhatsApp API call
+	contacts, err := mc.Client.Store.Contacts.GetAllContacts(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get contacts")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -132,8 +132,8 @@ func (h *ContactHandler) SyncContacts(c *gin.Context) {
 		return
 	}
 
-	// Get count before sync - Store method, no context needed
-	contactsBefore, err := mc.Client.Store.Contacts.GetAllContacts()
+	// Get count before sync from the device store
+	contactsBefore, err := mc.Client.Store.Contacts.GetAllContacts(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get contacts before sync")
 	}
@@ -151,8 +151,8 @@ func (h *ContactHandler) SyncContacts(c *gin.Context) {
 		time.Sleep(2 * time.Second)
 	}
 
-	// Get count after sync - Store method, no context needed
-	contactsAfter, err := mc.Client.Store.Contacts.GetAllContacts()
+	// Get count after sync from the device store
+	contactsAfter, err := mc.Client.Store.Contacts.GetAllContacts(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get contacts after sync")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -180,3 +180,79 @@ func (h *ContactHandler) SyncContacts(c *gin.Context) {
 		"request_id":      requestID,
 	})
 }
+
+// GetContactDirectory lists the cached contact directory maintained from
+// contact, push name and business name events
+func (h *ContactHandler) GetContactDirectory(c *gin.Context) {
+	waAccountID := c.Query("wa_account_id")
+	search := c.Query("search")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+	requestID := c.GetString("request_id")
+
+	if waAccountID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "missing_parameter",
+			"message":    "wa_account_id is required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var changedSince time.Time
+	if raw := c.Query("changed_since"); raw != "" {
+		unix, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "invalid_parameter",
+				"message":    "changed_since must be a unix timestamp",
+				"request_id": requestID,
+			})
+			return
+		}
+		changedSince = time.Unix(unix, 0)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 500 {
+		perPage = 50
+	}
+
+	contacts, total, err := h.clientManager.ListDirectoryContacts(waAccountID, search, changedSince, perPage, (page-1)*perPage)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list contact directory")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "contacts_fetch_failed",
+			"message":    "failed to get contacts",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	contactList := []map[string]interface{}{}
+	for _, contact := range contacts {
+		contactList = append(contactList, map[string]interface{}{
+			"jid":             contact.JID,
+			"name":            contact.Name,
+			"name_source":     contact.NameSource,
+			"full_name":       contact.FullName,
+			"first_name":      contact.FirstName,
+			"push_name":       contact.PushName,
+			"business_name":   contact.BusinessName,
+			"last_changed_at": contact.LastChangedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contacts": contactList,
+		"meta": gin.H{
+			"current_page": page,
+			"per_page":     perPage,
+			"total":        total,
+			"total_pages":  (total + perPage - 1) / perPage,
+		},
+		"request_id": requestID,
+	})
+}
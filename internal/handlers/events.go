@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/eventbus"
+)
+
+type EventStreamHandler struct {
+	bus       *eventbus.Bus
+	heartbeat time.Duration
+}
+
+func NewEventStreamHandler(bus *eventbus.Bus, heartbeat time.Duration) *EventStreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+
+	return &EventStreamHandler{
+		bus:       bus,
+		heartbeat: heartbeat,
+	}
+}
+
+// Stream serves the events of an account as Server-Sent Events. The optional
+// types query parameter is a comma separated list of event types to include.
+// Reconnecting clients resume after the Last-Event-ID header (or the
+// last_event_id query parameter) from the in-memory buffer.
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	waAccountID := c.Param("waAccountId")
+	requestID := c.GetString("request_id")
+
+	var afterID uint64
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "invalid_last_event_id",
+				"message":    "Last-Event-ID must be a numeric event ID",
+				"request_id": requestID,
+			})
+			return
+		}
+		afterID = id
+	}
+
+	var eventTypes []string
+	for _, t := range strings.Split(c.Query("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			eventTypes = append(eventTypes, t)
+		}
+	}
+
+	sub, backlog, complete := h.bus.Subscribe(waAccountID, eventTypes, afterID)
+	defer sub.Close()
+
+	// The server write timeout would cut the stream off, events are flushed
+	// individually so the connection is held open until the client leaves
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn().Err(err).Msg("Failed to clear write deadline for event stream")
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	log.Info().
+		Str("wa_account_id", waAccountID).
+		Strs("types", eventTypes).
+		Uint64("last_event_id", afterID).
+		Int("backlog", len(backlog)).
+		Msg("Event stream opened")
+
+	fmt.Fprintf(c.Writer, "retry: 3000\n\n")
+	if !complete {
+		fmt.Fprintf(c.Writer, "event: events_lost\ndata: {\"last_event_id\":%d}\n\n", afterID)
+	}
+	for _, evt := range backlog {
+		if err := writeSSEEvent(c.Writer, evt); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			log.Info().Str("wa_account_id", waAccountID).Msg("Event stream closed by client")
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprintf(c.Writer, ": heartbeat %d\n\n", time.Now().Unix()); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case evt, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind, the client resumes with Last-Event-ID
+				log.Warn().Str("wa_account_id", waAccountID).Msg("Event stream subscriber fell behind, closing")
+				return
+			}
+			if err := writeSSEEvent(c.Writer, evt); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeSSEEvent(w io.Writer, evt eventbus.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		log.Error().Err(err).Uint64("event_id", evt.ID).Msg("Failed to marshal stream event")
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+	return err
+}
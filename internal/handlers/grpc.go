@@ -0,0 +1,868 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/auth"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/eventbus"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/middleware"
+	wav1 "github.com/whatsapp-api/go-whatsapp-service/internal/pb/wav1"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type grpcTokenKey struct{}
+
+// GRPCServer implements the gRPC API on top of the same client manager, rate
+// limiter and token auth as the REST and WebSocket APIs
+type GRPCServer struct {
+	clientManager *wa.ClientManager
+	bus           *eventbus.Bus
+	messages      *MessageHandler
+	rateLimiter   *middleware.RateLimiter
+	signingSecret string
+}
+
+func NewGRPCServer(cm *wa.ClientManager, bus *eventbus.Bus, messages *MessageHandler, rl *middleware.RateLimiter, signingSecret string) *GRPCServer {
+	return &GRPCServer{
+		clientManager: cm,
+		bus:           bus,
+		messages:      messages,
+		rateLimiter:   rl,
+		signingSecret: signingSecret,
+	}
+}
+
+// ServerOptions returns the interceptors authenticating every call, and the
+// message size allowing media of the largest size inline
+func (s *GRPCServer) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(int(s.messages.requestSizeLimit())),
+		grpc.ChainUnaryInterceptor(s.unaryAuth),
+		grpc.ChainStreamInterceptor(s.streamAuth),
+	}
+}
+
+// Register registers every service on a gRPC server. The event service is
+// only registered when the event bus is available.
+func (s *GRPCServer) Register(server *grpc.Server) {
+	wav1.RegisterSessionServiceServer(server, &grpcSessionService{GRPCServer: s})
+	wav1.RegisterMessageServiceServer(server, &grpcMessageService{GRPCServer: s})
+	wav1.RegisterGroupServiceServer(server, &grpcGroupService{GRPCServer: s})
+	wav1.RegisterChatServiceServer(server, &grpcChatService{GRPCServer: s})
+	wav1.RegisterContactServiceServer(server, &grpcContactService{GRPCServer: s})
+	wav1.RegisterAccountServiceServer(server, &grpcAccountService{GRPCServer: s})
+	if s.bus != nil {
+		wav1.RegisterEventServiceServer(server, &grpcEventService{GRPCServer: s})
+	}
+}
+
+func (s *GRPCServer) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token = auth.BearerToken(values[0])
+	}
+
+	claims, err := auth.ParseToken(s.signingSecret, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return context.WithValue(ctx, grpcTokenKey{}, claims), nil
+}
+
+func (s *GRPCServer) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *GRPCServer) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func grpcToken(ctx context.Context) *auth.Token {
+	claims, _ := ctx.Value(grpcTokenKey{}).(*auth.Token)
+	return claims
+}
+
+func authorizeAccount(ctx context.Context, waAccountID string) error {
+	if waAccountID == "" {
+		return status.Error(codes.InvalidArgument, "wa_account_id is required")
+	}
+
+	claims := grpcToken(ctx)
+	if claims == nil || !claims.Allows(waAccountID) {
+		return status.Error(codes.PermissionDenied, "not authorized for this account")
+	}
+
+	return nil
+}
+
+// client authorizes the account and returns its client
+func (s *GRPCServer) client(ctx context.Context, waAccountID string) (*wa.ManagedClient, error) {
+	if err := authorizeAccount(ctx, waAccountID); err != nil {
+		return nil, err
+	}
+
+	mc, err := s.clientManager.GetOrCreateClient(ctx, waAccountID)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to get client")
+		return nil, status.Error(codes.Internal, "failed to get client")
+	}
+
+	return mc, nil
+}
+
+// connectedClient is client for operations that need a live connection
+func (s *GRPCServer) connectedClient(ctx context.Context, waAccountID string) (*wa.ManagedClient, error) {
+	mc, err := s.client(ctx, waAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !mc.Client.IsConnected() {
+		return nil, status.Error(codes.FailedPrecondition, "account not connected")
+	}
+
+	return mc, nil
+}
+
+// grpcError converts the shared apiError into a gRPC status
+func grpcError(err error) error {
+	apiErr := toAPIError(err)
+
+	code := codes.Internal
+	switch apiErr.Status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		code = codes.InvalidArgument
+	case http.StatusUnprocessableEntity:
+		code = codes.FailedPrecondition
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	}
+
+	return status.Error(code, apiErr.Message)
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+type grpcEventService struct {
+	wav1.UnimplementedEventServiceServer
+	*GRPCServer
+}
+
+func (s *grpcEventService) StreamEvents(req *wav1.StreamEventsRequest, stream wav1.EventService_StreamEventsServer) error {
+	ctx := stream.Context()
+
+	accounts := req.GetWaAccountIds()
+	if len(accounts) == 0 {
+		accounts = grpcToken(ctx).Accounts
+	}
+	for _, waAccountID := range accounts {
+		if err := authorizeAccount(ctx, waAccountID); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var backlog []eventbus.Event
+	complete := true
+	merged := make(chan eventbus.Event, wsOutboundBuffer)
+
+	for _, waAccountID := range accounts {
+		sub, events, all := s.bus.Subscribe(waAccountID, req.GetEventTypes(), req.GetLastEventId())
+		defer sub.Close()
+
+		backlog = append(backlog, events...)
+		complete = complete && all
+
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case evt, ok := <-sub.Events():
+					if !ok {
+						// Dropped by the bus for falling behind, the client resumes with last_event_id
+						cancel()
+						return
+					}
+					select {
+					case merged <- evt:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	if !complete {
+		if err := stream.SetHeader(metadata.Pairs("x-events-lost", "true")); err != nil {
+			log.Warn().Err(err).Msg("Failed to set event stream header")
+		}
+	}
+
+	sort.Slice(backlog, func(i, j int) bool { return backlog[i].ID < backlog[j].ID })
+	for _, evt := range backlog {
+		if err := sendGRPCEvent(stream, evt); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if stream.Context().Err() != nil {
+				return nil
+			}
+			return status.Error(codes.Unavailable, "event stream fell behind, resume with last_event_id")
+		case evt := <-merged:
+			if err := sendGRPCEvent(stream, evt); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func sendGRPCEvent(stream wav1.EventService_StreamEventsServer, evt eventbus.Event) error {
+	// Event data is built for JSON, so JSON is the faithful way into a Struct
+	data := &structpb.Struct{}
+	if raw, err := json.Marshal(evt.Data); err == nil {
+		if err := protojson.Unmarshal(raw, data); err != nil {
+			log.Error().Err(err).Uint64("event_id", evt.ID).Msg("Failed to convert event data")
+		}
+	}
+
+	return stream.Send(&wav1.Event{
+		Id:            evt.ID,
+		EventType:     evt.Type,
+		WaAccountId:   evt.WaAccountID,
+		Timestamp:     unixOrZero(evt.Timestamp),
+		Data:          data,
+		SchemaVersion: int32(evt.SchemaVersion),
+	})
+}
+
+type grpcSessionService struct {
+	wav1.UnimplementedSessionServiceServer
+	*GRPCServer
+}
+
+func (s *grpcSessionService) GetStatus(ctx context.Context, req *wav1.AccountRequest) (*wav1.SessionStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	mc, err := s.client(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	state := "disconnected"
+	if mc.Client.IsConnected() {
+		state = "connected"
+	} else if mc.Client.IsLoggedIn() {
+		state = "logged_in"
+	}
+
+	var jid string
+	if mc.Client.Store.ID != nil {
+		jid = mc.Client.Store.ID.String()
+	}
+
+	return &wav1.SessionStatus{
+		WaAccountId: req.GetWaAccountId(),
+		Status:      state,
+		Jid:         jid,
+		Connected:   mc.Client.IsConnected(),
+		LoggedIn:    mc.Client.IsLoggedIn(),
+	}, nil
+}
+
+func (s *grpcSessionService) Reconnect(ctx context.Context, req *wav1.AccountRequest) (*wav1.Empty, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	waAccountID := req.GetWaAccountId()
+	mc, err := s.client(ctx, waAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mc.Client.IsConnected() {
+		return nil, status.Error(codes.FailedPrecondition, "account is already connected")
+	}
+
+	go func() {
+		if err := mc.Client.Connect(); err != nil {
+			log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to reconnect")
+			s.clientManager.PublishEvent("status", webhooks.StatusPayload(waAccountID, "failed", err.Error()))
+		}
+	}()
+
+	return &wav1.Empty{}, nil
+}
+
+func (s *grpcSessionService) Logout(ctx context.Context, req *wav1.AccountRequest) (*wav1.Empty, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	waAccountID := req.GetWaAccountId()
+	mc, err := s.client(ctx, waAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mc.Client.Logout(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to logout")
+		return nil, status.Error(codes.Internal, "failed to logout")
+	}
+
+	s.clientManager.RemoveClient(waAccountID)
+
+	return &wav1.Empty{}, nil
+}
+
+func (s *grpcSessionService) GetCallPolicy(ctx context.Context, req *wav1.AccountRequest) (*wav1.CallPolicy, error) {
+	waAccountID := req.GetWaAccountId()
+	if err := authorizeAccount(ctx, waAccountID); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.clientManager.GetCallPolicy(waAccountID)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to get call policy")
+		return nil, status.Error(codes.Internal, "failed to get call policy")
+	}
+
+	return &wav1.CallPolicy{
+		WaAccountId: waAccountID,
+		AutoReject:  policy.AutoReject,
+		ReplyText:   policy.ReplyText,
+	}, nil
+}
+
+func (s *grpcSessionService) SetCallPolicy(ctx context.Context, req *wav1.CallPolicy) (*wav1.CallPolicy, error) {
+	waAccountID := req.GetWaAccountId()
+	if err := authorizeAccount(ctx, waAccountID); err != nil {
+		return nil, err
+	}
+
+	err := s.clientManager.SetCallPolicy(&store.CallPolicy{
+		WaAccountID: waAccountID,
+		AutoReject:  req.GetAutoReject(),
+		ReplyText:   req.GetReplyText(),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to save call policy")
+		return nil, status.Error(codes.Internal, "failed to save call policy")
+	}
+
+	return req, nil
+}
+
+type grpcMessageService struct {
+	wav1.UnimplementedMessageServiceServer
+	*GRPCServer
+}
+
+func (s *grpcMessageService) SendMessage(ctx context.Context, req *wav1.SendMessageRequest) (*wav1.SendMessageResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.rateLimiter.Allow(req.GetWaAccountId()) {
+		return nil, status.Error(codes.ResourceExhausted, "Too many messages sent. Please wait before sending more.")
+	}
+
+	resp, err := s.messages.sendMessage(ctx, mc, sendMessageRequestFromProto(req))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &wav1.SendMessageResponse{
+		MessageId: resp.ID,
+		Timestamp: resp.Timestamp.Unix(),
+	}, nil
+}
+
+// sendMessageRequestFromProto maps the gRPC request onto the REST one so both
+// APIs share validation and message building
+func sendMessageRequestFromProto(req *wav1.SendMessageRequest) SendMessageRequest {
+	r := SendMessageRequest{
+		WaAccountID:     req.GetWaAccountId(),
+		To:              req.GetTo(),
+		Type:            req.GetType(),
+		Body:            req.GetBody(),
+		MediaID:         req.GetMediaId(),
+		Mime:            req.GetMime(),
+		FileName:        req.GetFileName(),
+		Mentions:        req.GetMentions(),
+		LinkPreview:     req.GetLinkPreview(),
+		Template:        req.GetTemplate(),
+		TemplateVersion: int(req.GetTemplateVersion()),
+		Locale:          req.GetLocale(),
+		Variables:       req.GetVariables(),
+		media:           req.GetMediaData(),
+	}
+
+	if m := req.GetImage(); m != nil {
+		r.Image = &MediaInfo{URL: m.GetUrl(), Caption: m.GetCaption()}
+	}
+	if m := req.GetVideo(); m != nil {
+		r.Video = &VideoInfo{URL: m.GetUrl(), Caption: m.GetCaption()}
+	}
+	if m := req.GetAudio(); m != nil {
+		r.Audio = &AudioInfo{URL: m.GetUrl(), PTT: m.GetPtt()}
+	}
+	if m := req.GetSticker(); m != nil {
+		r.Sticker = &StickerInfo{URL: m.GetUrl(), PackName: m.GetPackName(), PackAuthor: m.GetPackAuthor(), Emojis: m.GetEmojis()}
+	}
+	if m := req.GetDocument(); m != nil {
+		r.Document = &DocumentInfo{URL: m.GetUrl(), Filename: m.GetFilename(), Mimetype: m.GetMimetype()}
+	}
+	if m := req.GetLocation(); m != nil {
+		r.Location = &LocationInfo{Latitude: m.GetLatitude(), Longitude: m.GetLongitude(), Name: m.GetName()}
+	}
+	if m := req.GetContact(); m != nil {
+		r.Contact = &ContactInfo{Name: m.GetName(), Phones: m.GetPhones(), Org: m.GetOrg()}
+	}
+	if m := req.GetPoll(); m != nil {
+		r.Poll = &PollInfo{Question: m.GetQuestion(), Options: m.GetOptions()}
+	}
+	if m := req.GetLink(); m != nil {
+		r.Link = &LinkInfo{URL: m.GetUrl(), Caption: m.GetCaption()}
+	}
+	if m := req.GetReplyTo(); m != nil {
+		r.ReplyTo = &ReplyInfo{MessageID: m.GetMessageId(), Chat: m.GetChat(), Participant: m.GetParticipant()}
+	}
+
+	return r
+}
+
+func (s *grpcMessageService) GetMessageStatus(ctx context.Context, req *wav1.GetMessageStatusRequest) (*wav1.MessageStatus, error) {
+	if err := authorizeAccount(ctx, req.GetWaAccountId()); err != nil {
+		return nil, err
+	}
+
+	report, err := s.clientManager.GetMessageStatus(req.GetWaAccountId(), req.GetMessageId())
+	if err != nil {
+		log.Error().Err(err).Str("message_id", req.GetMessageId()).Msg("Failed to get message status")
+		return nil, status.Error(codes.Internal, "failed to get message status")
+	}
+
+	if report == nil {
+		return nil, status.Error(codes.NotFound, "message is not tracked")
+	}
+
+	recipients := []*wav1.RecipientStatus{}
+	for _, r := range report.Recipients {
+		recipients = append(recipients, &wav1.RecipientStatus{
+			Jid:       r.RecipientJID,
+			Status:    r.Status,
+			UpdatedAt: unixOrZero(r.UpdatedAt),
+		})
+	}
+
+	return &wav1.MessageStatus{
+		MessageId:  req.GetMessageId(),
+		Chat:       report.Message.ChatJID,
+		Type:       report.Message.MessageType,
+		Status:     report.Message.Status,
+		CreatedAt:  unixOrZero(report.Message.CreatedAt),
+		UpdatedAt:  unixOrZero(report.Message.UpdatedAt),
+		Recipients: recipients,
+	}, nil
+}
+
+func (s *grpcMessageService) SendChatPresence(ctx context.Context, req *wav1.ChatPresenceRequest) (*wav1.Empty, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.messages.sendChatPresence(ctx, mc.Client, SendMessageRequest{
+		ChatPresence: &ChatPresenceInfo{JID: req.GetChat(), State: req.GetState()},
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &wav1.Empty{}, nil
+}
+
+type grpcGroupService struct {
+	wav1.UnimplementedGroupServiceServer
+	*GRPCServer
+}
+
+func (s *grpcGroupService) ListGroups(ctx context.Context, req *wav1.AccountRequest) (*wav1.ListGroupsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := mc.Client.GetJoinedGroups(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get groups")
+		return nil, status.Error(codes.Internal, "failed to get groups")
+	}
+
+	resp := &wav1.ListGroupsResponse{}
+	for _, group := range groups {
+		resp.Groups = append(resp.Groups, &wav1.Group{
+			Jid:              group.JID.String(),
+			Name:             group.Name,
+			Owner:            group.OwnerJID.String(),
+			CreatedAt:        unixOrZero(group.GroupCreated),
+			ParticipantCount: int32(len(group.Participants)),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *grpcGroupService) GetGroupInfo(ctx context.Context, req *wav1.GroupRequest) (*wav1.Group, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	groupJID, err := types.ParseJID(req.GetGroupJid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid group JID")
+	}
+
+	info, err := mc.Client.GetGroupInfo(ctx, groupJID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get group info")
+		return nil, status.Error(codes.Internal, "failed to get group info")
+	}
+
+	group := &wav1.Group{
+		Jid:              info.JID.String(),
+		Name:             info.Name,
+		Owner:            info.OwnerJID.String(),
+		Topic:            info.Topic,
+		CreatedAt:        unixOrZero(info.GroupCreated),
+		ParticipantCount: int32(len(info.Participants)),
+	}
+	for _, p := range info.Participants {
+		group.Participants = append(group.Participants, &wav1.GroupParticipant{
+			Jid:          p.JID.String(),
+			IsAdmin:      p.IsAdmin,
+			IsSuperAdmin: p.IsSuperAdmin,
+		})
+	}
+
+	return group, nil
+}
+
+func (s *grpcGroupService) CreateGroup(ctx context.Context, req *wav1.CreateGroupRequest) (*wav1.Group, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if req.GetSubject() == "" {
+		return nil, status.Error(codes.InvalidArgument, "subject is required")
+	}
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	participants := []types.JID{}
+	for _, p := range req.GetParticipants() {
+		jid, err := types.ParseJID(p)
+		if err != nil {
+			log.Error().Err(err).Str("participant", p).Msg("Failed to parse participant JID")
+			continue
+		}
+		participants = append(participants, jid)
+	}
+
+	info, err := mc.Client.CreateGroup(ctx, whatsmeow.ReqCreateGroup{
+		Name:         req.GetSubject(),
+		Participants: participants,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create group")
+		return nil, status.Error(codes.Internal, "failed to create group")
+	}
+
+	return &wav1.Group{
+		Jid:              info.JID.String(),
+		Name:             info.Name,
+		Owner:            info.OwnerJID.String(),
+		CreatedAt:        unixOrZero(info.GroupCreated),
+		ParticipantCount: int32(len(info.Participants)),
+	}, nil
+}
+
+func (s *grpcGroupService) LeaveGroup(ctx context.Context, req *wav1.GroupRequest) (*wav1.Empty, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	groupJID, err := types.ParseJID(req.GetGroupJid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid group JID")
+	}
+
+	if err := mc.Client.LeaveGroup(ctx, groupJID); err != nil {
+		log.Error().Err(err).Msg("Failed to leave group")
+		return nil, status.Error(codes.Internal, "failed to leave group")
+	}
+
+	return &wav1.Empty{}, nil
+}
+
+type grpcChatService struct {
+	wav1.UnimplementedChatServiceServer
+	*GRPCServer
+}
+
+func (s *grpcChatService) MarkRead(ctx context.Context, req *wav1.MarkReadRequest) (*wav1.Empty, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	chatJID, err := types.ParseJID(req.GetChat())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid chat JID")
+	}
+
+	sender := types.EmptyJID
+	if req.GetSender() != "" {
+		if sender, err = types.ParseJID(req.GetSender()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid sender JID")
+		}
+	}
+
+	if err := markRead(ctx, mc.Client, chatJID, req.GetMessageIds(), sender); err != nil {
+		log.Error().Err(err).Msg("Failed to mark chat as read")
+		return nil, status.Error(codes.Internal, "failed to mark as read")
+	}
+
+	return &wav1.Empty{}, nil
+}
+
+func (s *grpcChatService) GetChatState(ctx context.Context, req *wav1.ChatRequest) (*wav1.ChatState, error) {
+	if err := authorizeAccount(ctx, req.GetWaAccountId()); err != nil {
+		return nil, err
+	}
+
+	chatJID, err := types.ParseJID(req.GetChat())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid chat JID")
+	}
+
+	state, err := s.clientManager.GetChatState(req.GetWaAccountId(), chatJID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get chat state")
+		return nil, status.Error(codes.Internal, "failed to get chat state")
+	}
+
+	resp := &wav1.ChatState{Chat: chatJID.String()}
+	if state != nil {
+		resp.Archived = state.Archived
+		resp.Pinned = state.Pinned
+		resp.Muted = state.Muted
+		resp.Labels = state.Labels
+		if state.MutedUntil != nil {
+			resp.MutedUntil = state.MutedUntil.Unix()
+		}
+	}
+
+	return resp, nil
+}
+
+type grpcContactService struct {
+	wav1.UnimplementedContactServiceServer
+	*GRPCServer
+}
+
+func (s *grpcContactService) ListContacts(ctx context.Context, req *wav1.ListContactsRequest) (*wav1.ListContactsResponse, error) {
+	if err := authorizeAccount(ctx, req.GetWaAccountId()); err != nil {
+		return nil, err
+	}
+
+	var changedSince time.Time
+	if req.GetChangedSince() > 0 {
+		changedSince = time.Unix(req.GetChangedSince(), 0)
+	}
+
+	page, perPage := int(req.GetPage()), int(req.GetPerPage())
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 500 {
+		perPage = 50
+	}
+
+	contacts, total, err := s.clientManager.ListDirectoryContacts(req.GetWaAccountId(), req.GetSearch(), changedSince, perPage, (page-1)*perPage)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list contact directory")
+		return nil, status.Error(codes.Internal, "failed to get contacts")
+	}
+
+	resp := &wav1.ListContactsResponse{Total: int32(total)}
+	for _, contact := range contacts {
+		resp.Contacts = append(resp.Contacts, &wav1.DirectoryContact{
+			Jid:           contact.JID,
+			Name:          contact.Name,
+			NameSource:    contact.NameSource,
+			FullName:      contact.FullName,
+			FirstName:     contact.FirstName,
+			PushName:      contact.PushName,
+			BusinessName:  contact.BusinessName,
+			LastChangedAt: unixOrZero(contact.LastChangedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+type grpcAccountService struct {
+	wav1.UnimplementedAccountServiceServer
+	*GRPCServer
+}
+
+func (s *grpcAccountService) CheckUserExists(ctx context.Context, req *wav1.CheckUserExistsRequest) (*wav1.CheckUserExistsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if len(req.GetPhones()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "phones are required")
+	}
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := mc.Client.IsOnWhatsApp(ctx, req.GetPhones())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check user existence")
+		return nil, status.Error(codes.Internal, "failed to check user existence")
+	}
+
+	resp := &wav1.CheckUserExistsResponse{}
+	for _, result := range results {
+		resp.Results = append(resp.Results, &wav1.UserExists{
+			Phone:        result.Query,
+			Exists:       result.IsIn,
+			Jid:          result.JID.String(),
+			VerifiedName: verifiedName(result.VerifiedName),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *grpcAccountService) GetUserInfo(ctx context.Context, req *wav1.GetUserInfoRequest) (*wav1.UserInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	mc, err := s.connectedClient(ctx, req.GetWaAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	jid, err := types.ParseJID(req.GetJid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid JID")
+	}
+
+	infos, err := mc.Client.GetUserInfo(ctx, []types.JID{jid})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user info")
+		return nil, status.Error(codes.Internal, "failed to get user info")
+	}
+
+	info, ok := infos[jid]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	resp := &wav1.UserInfo{
+		Jid:          jid.String(),
+		VerifiedName: verifiedName(info.VerifiedName),
+		Status:       info.Status,
+		PictureId:    info.PictureID,
+	}
+	for _, device := range info.Devices {
+		resp.Devices = append(resp.Devices, device.String())
+	}
+
+	return resp, nil
+}
+
+func verifiedName(name *types.VerifiedName) string {
+	if name == nil || name.Details == nil {
+		return ""
+	}
+	return name.Details.GetVerifiedName()
+}
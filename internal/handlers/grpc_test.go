@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/whatsapp-api/go-whatsapp-service/internal/eventbus"
+	wav1 "github.com/whatsapp-api/go-whatsapp-service/internal/pb/wav1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves the gRPC API in memory and returns a connection to it
+func newGRPCClient(t *testing.T, bus *eventbus.Bus) *grpc.ClientConn {
+	t.Helper()
+
+	s := NewGRPCServer(nil, bus, newTestMessageHandler(t, 8<<20), nil, testSigningSecret)
+	server := grpc.NewServer(s.ServerOptions()...)
+	s.Register(server)
+
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(64<<20)),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestGRPCAuth(t *testing.T) {
+	conn := newGRPCClient(t, nil)
+	sessions := wav1.NewSessionServiceClient(conn)
+	messages := wav1.NewMessageServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	authorized := withToken(ctx, signToken(t, "acc-1"))
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{
+			"no token",
+			func() error {
+				_, err := sessions.GetStatus(ctx, &wav1.AccountRequest{WaAccountId: "acc-1"})
+				return err
+			},
+			codes.Unauthenticated,
+		},
+		{
+			"bad token",
+			func() error {
+				_, err := sessions.GetStatus(withToken(ctx, "nope"), &wav1.AccountRequest{WaAccountId: "acc-1"})
+				return err
+			},
+			codes.Unauthenticated,
+		},
+		{
+			"no account",
+			func() error {
+				_, err := sessions.GetStatus(authorized, &wav1.AccountRequest{})
+				return err
+			},
+			codes.InvalidArgument,
+		},
+		{
+			"account outside the token",
+			func() error {
+				_, err := sessions.GetStatus(authorized, &wav1.AccountRequest{WaAccountId: "acc-2"})
+				return err
+			},
+			codes.PermissionDenied,
+		},
+		{
+			// Over the default limit of 4MiB, accepted as inline media may be that large
+			"large message of an account outside the token",
+			func() error {
+				_, err := messages.SendMessage(authorized, &wav1.SendMessageRequest{
+					WaAccountId: "acc-2",
+					To:          "123",
+					Type:        "image",
+					MediaData:   make([]byte, 5<<20),
+				})
+				return err
+			},
+			codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		if code := status.Code(tt.call()); code != tt.code {
+			t.Errorf("%s = %s, want %s", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestGRPCStreamEvents(t *testing.T) {
+	bus := eventbus.New(16)
+	conn := newGRPCClient(t, bus)
+	events := wav1.NewEventServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = withToken(ctx, signToken(t, "acc-1"))
+
+	stream, err := events.StreamEvents(ctx, &wav1.StreamEventsRequest{WaAccountIds: []string{"acc-2"}})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("stream of an account outside the token = %v, want PermissionDenied", err)
+	}
+
+	bus.Publish("acc-1", "message", 1, time.Now(), map[string]interface{}{"id": "first"})
+	bus.Publish("acc-1", "message", 1, time.Now(), map[string]interface{}{"id": "second"})
+
+	// Resumes after the first event from the buffer
+	stream, err = events.StreamEvents(ctx, &wav1.StreamEventsRequest{LastEventId: 1})
+	if err != nil {
+		t.Fatalf("StreamEvents: %v", err)
+	}
+	evt, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if evt.GetId() != 2 || evt.GetWaAccountId() != "acc-1" || evt.GetData().GetFields()["id"].GetStringValue() != "second" {
+		t.Errorf("event = %v, want the second acc-1 event", evt)
+	}
+}
+
+func TestGRPCError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{&apiError{http.StatusBadRequest, "invalid_request", "bad"}, codes.InvalidArgument},
+		{mediaTooLarge(16), codes.InvalidArgument},
+		{&apiError{http.StatusUnsupportedMediaType, "unsupported_media_type", "html"}, codes.InvalidArgument},
+		{&apiError{http.StatusUnprocessableEntity, "media_download_failed", "failed"}, codes.FailedPrecondition},
+		{&apiError{http.StatusNotFound, "media_not_found", "gone"}, codes.NotFound},
+		{&apiError{http.StatusTooManyRequests, "rate_limit_exceeded", "slow down"}, codes.ResourceExhausted},
+		{errors.New("boom"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		if code := status.Code(grpcError(tt.err)); code != tt.code {
+			t.Errorf("grpcError(%v) = %s, want %s", tt.err, code, tt.code)
+		}
+	}
+}
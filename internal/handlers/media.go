@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
+)
+
+type MediaHandler struct {
+	store *media.Store
+}
+
+func NewMediaHandler(store *media.Store) *MediaHandler {
+	return &MediaHandler{store: store}
+}
+
+type UploadMediaRequest struct {
+	WaAccountID string `json:"wa_account_id" binding:"required"`
+	Data        string `json:"data" binding:"required"` // plain base64 or a data URL
+	FileName    string `json:"file_name"`
+	Mime        string `json:"mime"`
+}
+
+// Upload stores media sent as the "file" part of a multipart/form-data request
+// or as base64 JSON. The returned media_id can be sent as a message of the
+// uploading account any number of times until the upload expires.
+func (h *MediaHandler) Upload(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	var (
+		m   *media.Media
+		err error
+	)
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		m, err = h.saveFile(c)
+	} else {
+		m, err = h.saveBase64(c)
+	}
+	if err != nil {
+		apiErr := toAPIError(err)
+		if apiErr.Status == http.StatusInternalServerError {
+			log.Error().Err(err).Msg("Failed to store media")
+			apiErr = &apiError{http.StatusInternalServerError, "media_error", "failed to store media"}
+		}
+		c.JSON(apiErr.Status, gin.H{
+			"error":      apiErr.Code,
+			"message":    apiErr.Message,
+			"request_id": requestID,
+		})
+		return
+	}
+
+	response := mediaResponse(m)
+	response["request_id"] = requestID
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *MediaHandler) saveFile(c *gin.Context) (*media.Media, error) {
+	header, err := c.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, &apiError{http.StatusBadRequest, "invalid_request", "file is required"}
+	}
+	if err != nil {
+		return nil, bodyError(err)
+	}
+	waAccountID := c.PostForm("wa_account_id")
+	if waAccountID == "" {
+		return nil, &apiError{http.StatusBadRequest, "invalid_request", "wa_account_id is required"}
+	}
+	if header.Size > h.store.MaxSize() {
+		return nil, mediaTooLarge(h.store.MaxSize())
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, "invalid_request", "failed to read file"}
+	}
+	defer file.Close()
+
+	fileName := c.PostForm("file_name")
+	if fileName == "" {
+		fileName = header.Filename
+	}
+	mime := c.PostForm("mime")
+	if mime == "" {
+		mime = header.Header.Get("Content-Type")
+	}
+
+	m, err := h.store.Save(file, waAccountID, fileName, mime)
+	if errors.Is(err, media.ErrTooLarge) {
+		return nil, mediaTooLarge(h.store.MaxSize())
+	}
+	return m, err
+}
+
+func (h *MediaHandler) saveBase64(c *gin.Context) (*media.Media, error) {
+	var req UploadMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, bodyError(err)
+	}
+
+	data, mime, err := media.DecodeBase64(req.Data, h.store.MaxSize())
+	if errors.Is(err, media.ErrTooLarge) {
+		return nil, mediaTooLarge(h.store.MaxSize())
+	}
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, "invalid_media", err.Error()}
+	}
+	if req.Mime != "" {
+		mime = req.Mime
+	}
+
+	m, err := h.store.Save(bytes.NewReader(data), req.WaAccountID, req.FileName, mime)
+	if errors.Is(err, media.ErrTooLarge) {
+		return nil, mediaTooLarge(h.store.MaxSize())
+	}
+	return m, err
+}
+
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	m, ok := h.loadMedia(c)
+	if !ok {
+		return
+	}
+
+	response := mediaResponse(m)
+	response["request_id"] = requestID
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *MediaHandler) DeleteMedia(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	m, ok := h.loadMedia(c)
+	if !ok {
+		return
+	}
+
+	found, err := h.store.Delete(m.ID)
+	if err != nil {
+		log.Error().Err(err).Str("media_id", m.ID).Msg("Failed to delete media")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "media_error",
+			"message":    "failed to delete media",
+			"request_id": requestID,
+		})
+		return
+	}
+	if !found {
+		mediaNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"request_id": requestID,
+	})
+}
+
+// loadMedia returns the upload of the path, it answers 404 for uploads of
+// another account than the wa_account_id query parameter
+func (h *MediaHandler) loadMedia(c *gin.Context) (*media.Media, bool) {
+	requestID := c.GetString("request_id")
+	id := c.Param("mediaId")
+
+	waAccountID := c.Query("wa_account_id")
+	if waAccountID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_request",
+			"message":    "wa_account_id query parameter is required",
+			"request_id": requestID,
+		})
+		return nil, false
+	}
+
+	m, err := h.store.Stat(id)
+	if err != nil {
+		log.Error().Err(err).Str("media_id", id).Msg("Failed to get media")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "media_error",
+			"message":    "failed to get media",
+			"request_id": requestID,
+		})
+		return nil, false
+	}
+	if m == nil || m.WaAccountID != waAccountID {
+		mediaNotFound(c)
+		return nil, false
+	}
+
+	return m, true
+}
+
+func mediaResponse(m *media.Media) gin.H {
+	return gin.H{
+		"media_id":      m.ID,
+		"wa_account_id": m.WaAccountID,
+		"file_name":     m.FileName,
+		"mime":          m.Mime,
+		"size":          m.Size,
+		"sha256":        m.SHA256,
+		"created_at":    m.CreatedAt,
+		"expires_at":    m.ExpiresAt,
+	}
+}
+
+func mediaNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error":      "media_not_found",
+		"message":    "media does not exist or expired",
+		"request_id": c.GetString("request_id"),
+	})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/middleware"
+)
+
+func newMediaRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := media.NewStore(t.TempDir(), 16, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	h := NewMediaHandler(store)
+	r := gin.New()
+	r.POST("/v1/media", middleware.LimitBody(media.RequestSizeLimit(store.MaxSize())), h.Upload)
+	r.GET("/v1/media/:mediaId", h.GetMedia)
+	r.DELETE("/v1/media/:mediaId", h.DeleteMedia)
+	return r
+}
+
+func serve(r http.Handler, method, target, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q is not JSON: %v", w.Body.String(), err)
+	}
+	return body.Error
+}
+
+func TestMediaUploadIsScopedToAccount(t *testing.T) {
+	r := newMediaRouter(t)
+
+	body, _ := json.Marshal(UploadMediaRequest{
+		WaAccountID: "acc-1",
+		Data:        "data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte("hello")),
+		FileName:    "hello.txt",
+	})
+	w := serve(r, http.MethodPost, "/v1/media", "application/json", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upload = %d %s, want 201", w.Code, w.Body)
+	}
+	var uploaded struct {
+		MediaID string `json:"media_id"`
+		Mime    string `json:"mime"`
+		Size    int64  `json:"size"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &uploaded); err != nil {
+		t.Fatalf("upload response: %v", err)
+	}
+	if uploaded.MediaID == "" || uploaded.Mime != "text/plain" || uploaded.Size != 5 {
+		t.Errorf("upload = %+v, want a media_id, mime text/plain and size 5", uploaded)
+	}
+
+	path := "/v1/media/" + uploaded.MediaID
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		status int
+		code   string
+	}{
+		{"get without account", http.MethodGet, "", http.StatusBadRequest, "invalid_request"},
+		{"get of another account", http.MethodGet, "?wa_account_id=acc-2", http.StatusNotFound, "media_not_found"},
+		{"delete of another account", http.MethodDelete, "?wa_account_id=acc-2", http.StatusNotFound, "media_not_found"},
+		{"get", http.MethodGet, "?wa_account_id=acc-1", http.StatusOK, ""},
+		{"delete", http.MethodDelete, "?wa_account_id=acc-1", http.StatusOK, ""},
+		{"get after delete", http.MethodGet, "?wa_account_id=acc-1", http.StatusNotFound, "media_not_found"},
+	}
+
+	for _, tt := range tests {
+		w := serve(r, tt.method, path+tt.query, "", nil)
+		if w.Code != tt.status {
+			t.Errorf("%s = %d %s, want %d", tt.name, w.Code, w.Body, tt.status)
+			continue
+		}
+		if tt.code != "" {
+			if code := errorCode(t, w); code != tt.code {
+				t.Errorf("%s error = %q, want %q", tt.name, code, tt.code)
+			}
+		}
+	}
+}
+
+func TestMediaUploadRejects(t *testing.T) {
+	r := newMediaRouter(t)
+
+	multipartBody := func(fields map[string]string, file string) ([]byte, string) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for name, value := range fields {
+			mw.WriteField(name, value)
+		}
+		if file != "" {
+			part, _ := mw.CreateFormFile("file", "file.bin")
+			part.Write([]byte(file))
+		}
+		mw.Close()
+		return buf.Bytes(), mw.FormDataContentType()
+	}
+
+	tests := []struct {
+		name   string
+		body   func() ([]byte, string)
+		status int
+		code   string
+	}{
+		{
+			"multipart without account",
+			func() ([]byte, string) { return multipartBody(nil, "hello") },
+			http.StatusBadRequest, "invalid_request",
+		},
+		{
+			"multipart without file",
+			func() ([]byte, string) { return multipartBody(map[string]string{"wa_account_id": "acc-1"}, "") },
+			http.StatusBadRequest, "invalid_request",
+		},
+		{
+			"multipart over the media size",
+			func() ([]byte, string) {
+				return multipartBody(map[string]string{"wa_account_id": "acc-1"}, strings.Repeat("x", 17))
+			},
+			http.StatusRequestEntityTooLarge, "media_too_large",
+		},
+		{
+			"base64 over the media size",
+			func() ([]byte, string) {
+				body, _ := json.Marshal(UploadMediaRequest{WaAccountID: "acc-1", Data: base64.StdEncoding.EncodeToString(make([]byte, 17))})
+				return body, "application/json"
+			},
+			http.StatusRequestEntityTooLarge, "media_too_large",
+		},
+		{
+			"invalid base64",
+			func() ([]byte, string) {
+				body, _ := json.Marshal(UploadMediaRequest{WaAccountID: "acc-1", Data: "not base64!"})
+				return body, "application/json"
+			},
+			http.StatusBadRequest, "invalid_media",
+		},
+		{
+			"body over the request size",
+			func() ([]byte, string) {
+				return []byte(`{"wa_account_id":"acc-1","data":"` + strings.Repeat("A", 2<<20) + `"}`), "application/json"
+			},
+			http.StatusRequestEntityTooLarge, "request_too_large",
+		},
+	}
+
+	for _, tt := range tests {
+		body, contentType := tt.body()
+		w := serve(r, http.MethodPost, "/v1/media", contentType, body)
+		if w.Code != tt.status {
+			t.Errorf("%s = %d %s, want %d", tt.name, w.Code, w.Body, tt.status)
+			continue
+		}
+		if code := errorCode(t, w); code != tt.code {
+			t.Errorf("%s error = %q, want %q", tt.name, code, tt.code)
+		}
+	}
+}
@@ -13,16 +13,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/fetch"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/linkpreview"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
 	"go.mau.fi/whatsmeow"
+	waCommon "go.mau.fi/whatsmeow/proto/waCommon"
 	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 	"google.golang.org/protobuf/proto"
@@ -31,34 +36,65 @@ import (
 type MessageHandler struct {
 	clientManager *wa.ClientManager
 	webhookSender *webhooks.Sender
+	media         *media.Store
+	fetcher       *fetch.Fetcher
+	uploads       *wa.UploadCache
+	previews      *linkpreview.Fetcher
 }
 
-func NewMessageHandler(cm *wa.ClientManager, ws *webhooks.Sender) *MessageHandler {
+func NewMessageHandler(cm *wa.ClientManager, ws *webhooks.Sender, mediaStore *media.Store, fetcher *fetch.Fetcher, uploads *wa.UploadCache) *MessageHandler {
 	return &MessageHandler{
 		clientManager: cm,
 		webhookSender: ws,
+		media:         mediaStore,
+		fetcher:       fetcher,
+		uploads:       uploads,
+		previews:      linkpreview.NewFetcher(fetcher.Transport()),
 	}
 }
 
 type SendMessageRequest struct {
-	WaAccountID  string            `json:"wa_account_id" binding:"required"`
-	To           string            `json:"to" binding:"required"`
-	Type         string            `json:"type" binding:"required"`
-	Body         string            `json:"body"`
-	MediaURL     string            `json:"media_url"`
-	FileName     string            `json:"file_name"`
-	Mime         string            `json:"mime"`
-	Caption      string            `json:"caption"`
+	WaAccountID  string            `json:"wa_account_id" form:"wa_account_id" binding:"required"`
+	To           string            `json:"to" form:"to" binding:"required"`
+	Type         string            `json:"type" form:"type" binding:"required_without=Template"`
+	Body         string            `json:"body" form:"body"`
+	MediaURL     string            `json:"media_url" form:"media_url"`
+	MediaBase64  string            `json:"media_base64"` // plain base64 or a data URL
+	MediaID      string            `json:"media_id" form:"media_id"`
+	FileName     string            `json:"file_name" form:"file_name"`
+	Mime         string            `json:"mime" form:"mime"`
+	Caption      string            `json:"caption" form:"caption"`
 	Image        *MediaInfo        `json:"image"`
 	Video        *VideoInfo        `json:"video"`
 	Audio        *AudioInfo        `json:"audio"`
 	Document     *DocumentInfo     `json:"document"`
+	Sticker      *StickerInfo      `json:"sticker"`
 	Location     *LocationInfo     `json:"location"`
 	Contact      *ContactInfo      `json:"contact"`
 	Poll         *PollInfo         `json:"poll"`
 	Link         *LinkInfo         `json:"link"`
 	Presence     *PresenceInfo     `json:"presence"`
 	ChatPresence *ChatPresenceInfo `json:"chat_presence"`
+	ReplyTo      *ReplyInfo        `json:"reply_to"`
+	Mentions     []string          `json:"mentions" form:"mentions"`         // JIDs or phone numbers, @<phone> tokens in the text are added
+	LinkPreview  bool              `json:"link_preview" form:"link_preview"` // preview the first URL of text messages
+
+	// A template fills in the body, caption or poll in place of the request
+	Template        string            `json:"template" form:"template"`
+	TemplateVersion int               `json:"template_version" form:"template_version"` // defaults to the latest
+	Locale          string            `json:"locale" form:"locale"`                     // defaults to the template's default locale
+	Variables       map[string]string `json:"variables"`
+
+	// media is the content of an uploaded file, inline base64 media or a
+	// stored upload, it takes precedence over media URLs
+	media []byte
+}
+
+// ReplyInfo identifies the message a message replies to
+type ReplyInfo struct {
+	MessageID   string `json:"message_id"`
+	Chat        string `json:"chat"`        // chat of the quoted message, defaults to the recipient
+	Participant string `json:"participant"` // sender of the quoted message, looked up if empty
 }
 
 type MediaInfo struct {
@@ -76,6 +112,14 @@ type AudioInfo struct {
 	PTT bool   `json:"ptt"`
 }
 
+// StickerInfo is the sticker source and the pack it's shown as part of
+type StickerInfo struct {
+	URL        string   `json:"url"`
+	PackName   string   `json:"pack_name"`
+	PackAuthor string   `json:"pack_author"`
+	Emojis     []string `json:"emojis"`
+}
+
 type DocumentInfo struct {
 	URL      string `json:"url"`
 	Filename string `json:"filename"`
@@ -135,14 +179,34 @@ type UpdateMessageRequest struct {
 	NewText     string `json:"new_text" binding:"required"`
 }
 
+// apiError is an error with the HTTP status and error code it is reported as
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &apiError{http.StatusInternalServerError, "internal_error", err.Error()}
+}
+
 func (h *MessageHandler) SendMessage(c *gin.Context) {
 	var req SendMessageRequest
 	requestID := c.GetString("request_id")
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "invalid_request",
-			"message":    err.Error(),
+	if err := h.bindSendMessage(c, &req); err != nil {
+		apiErr := toAPIError(err)
+		c.JSON(apiErr.Status, gin.H{
+			"error":      apiErr.Code,
+			"message":    apiErr.Message,
 			"request_id": requestID,
 		})
 		return
@@ -207,23 +271,53 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	toJID, err := types.ParseJID(req.To)
+	resp, err := h.sendMessage(ctx, mc, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "invalid_recipient",
-			"message":    "invalid recipient JID",
+		apiErr := toAPIError(err)
+		c.JSON(apiErr.Status, gin.H{
+			"error":      apiErr.Code,
+			"message":    apiErr.Message,
 			"request_id": requestID,
 		})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message_id": resp.ID,
+		"timestamp":  resp.Timestamp,
+		"request_id": requestID,
+	})
+}
+
+// sendMessage builds, tracks and sends a message. It is shared by the HTTP
+// and WebSocket APIs, errors are returned as *apiError.
+func (h *MessageHandler) sendMessage(ctx context.Context, mc *wa.ManagedClient, req SendMessageRequest) (whatsmeow.SendResponse, error) {
+	toJID, err := types.ParseJID(req.To)
+	if err != nil {
+		return whatsmeow.SendResponse{}, &apiError{http.StatusBadRequest, "invalid_recipient", "invalid recipient JID"}
+	}
+
+	if req.Template != "" {
+		if err := h.applyTemplate(&req); err != nil {
+			return whatsmeow.SendResponse{}, err
+		}
+	}
+
+	contextInfo, err := h.buildContextInfo(mc, toJID, req)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	if err := h.resolveMedia(&req); err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
 	var message *waE2E.Message
 
 	switch req.Type {
 	case "text":
-		message = &waE2E.Message{
-			Conversation: proto.String(req.Body),
-		}
+		message = h.buildTextMessage(ctx, mc.Client, req)
 	case "image":
 		message, err = h.buildImageMessage(ctx, mc.Client, req)
 	case "video":
@@ -241,41 +335,105 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 	case "poll":
 		message, err = h.buildPollMessage(req)
 	case "link":
-		message, err = h.buildLinkMessage(req)
+		message, err = h.buildLinkMessage(ctx, mc.Client, req)
 	default:
+		return whatsmeow.SendResponse{}, &apiError{http.StatusBadRequest, "invalid_message_type", "unsupported message type"}
+	}
+
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			return whatsmeow.SendResponse{}, apiErr
+		}
+		log.Error().Err(err).Msg("Failed to build message")
+		return whatsmeow.SendResponse{}, &apiError{http.StatusInternalServerError, "message_build_failed", err.Error()}
+	}
+	attachContextInfo(message, contextInfo)
+
+	// Generate the ID up front so the message can be tracked before it hits the network
+	messageID := mc.Client.GenerateMessageID()
+	if err := h.clientManager.TrackOutboundMessage(req.WaAccountID, messageID, toJID, req.Type); err != nil {
+		log.Error().Err(err).Str("message_id", messageID).Msg("Failed to track outbound message")
+	}
+
+	resp, err := mc.Client.SendMessage(ctx, toJID, message, whatsmeow.SendRequestExtra{ID: messageID})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send message")
+		if err := h.clientManager.SetOutboundMessageStatus(req.WaAccountID, messageID, toJID, wa.MessageStatusFailed); err != nil {
+			log.Error().Err(err).Str("message_id", messageID).Msg("Failed to record message failure")
+		}
+		return whatsmeow.SendResponse{}, &apiError{http.StatusInternalServerError, "send_failed", "failed to send message"}
+	}
+
+	if err := h.clientManager.SetOutboundMessageStatus(req.WaAccountID, messageID, toJID, wa.MessageStatusServerAck); err != nil {
+		log.Error().Err(err).Str("message_id", messageID).Msg("Failed to record server ack")
+	}
+	mc.SaveSentMessage(toJID, resp, message)
+
+	return resp, nil
+}
+
+func (h *MessageHandler) GetMessageStatus(c *gin.Context) {
+	messageID := c.Param("messageId")
+	waAccountID := c.Query("wa_account_id")
+	requestID := c.GetString("request_id")
+
+	if waAccountID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "invalid_message_type",
-			"message":    "unsupported message type",
+			"error":      "missing_parameter",
+			"message":    "wa_account_id is required",
 			"request_id": requestID,
 		})
 		return
 	}
 
+	report, err := h.clientManager.GetMessageStatus(waAccountID, messageID)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build message")
+		log.Error().Err(err).Str("message_id", messageID).Msg("Failed to get message status")
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      "message_build_failed",
-			"message":    err.Error(),
+			"error":      "status_fetch_failed",
+			"message":    "failed to get message status",
 			"request_id": requestID,
 		})
 		return
 	}
 
-	resp, err := mc.Client.SendMessage(ctx, toJID, message)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to send message")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      "send_failed",
-			"message":    "failed to send message",
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":      "message_not_found",
+			"message":    "message is not tracked",
 			"request_id": requestID,
 		})
 		return
 	}
 
+	recipients := []map[string]interface{}{}
+	for _, r := range report.Recipients {
+		recipients = append(recipients, map[string]interface{}{
+			"jid":        r.RecipientJID,
+			"status":     r.Status,
+			"updated_at": r.UpdatedAt,
+		})
+	}
+
+	history := []map[string]interface{}{}
+	for _, t := range report.History {
+		history = append(history, map[string]interface{}{
+			"recipient":   t.RecipientJID,
+			"status":      t.Status,
+			"occurred_at": t.OccurredAt,
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"success":    true,
-		"message_id": resp.ID,
-		"timestamp":  resp.Timestamp,
+		"message_id": messageID,
+		"chat":       report.Message.ChatJID,
+		"type":       report.Message.MessageType,
+		"status":     report.Message.Status,
+		"created_at": report.Message.CreatedAt,
+		"updated_at": report.Message.UpdatedAt,
+		"recipients": recipients,
+		"history":    history,
 		"request_id": requestID,
 	})
 }
@@ -320,7 +478,7 @@ func (h *MessageHandler) DeleteMessage(c *gin.Context) {
 	_, err = mc.Client.SendMessage(ctx, chatJID, &waE2E.Message{
 		ProtocolMessage: &waE2E.ProtocolMessage{
 			Type: waE2E.ProtocolMessage_REVOKE.Enum(),
-			Key: &waE2E.MessageKey{
+			Key: &waCommon.MessageKey{
 				FromMe:    proto.Bool(true),
 				ID:        proto.String(messageID),
 				RemoteJID: proto.String(chatJID.String()),
@@ -385,7 +543,7 @@ func (h *MessageHandler) RevokeMessage(c *gin.Context) {
 	_, err = mc.Client.SendMessage(ctx, chatJID, &waE2E.Message{
 		ProtocolMessage: &waE2E.ProtocolMessage{
 			Type: waE2E.ProtocolMessage_REVOKE.Enum(),
-			Key: &waE2E.MessageKey{
+			Key: &waCommon.MessageKey{
 				FromMe:    proto.Bool(true),
 				ID:        proto.String(messageID),
 				RemoteJID: proto.String(chatJID.String()),
@@ -449,7 +607,7 @@ func (h *MessageHandler) ReactToMessage(c *gin.Context) {
 
 	_, err = mc.Client.SendMessage(ctx, chatJID, &waE2E.Message{
 		ReactionMessage: &waE2E.ReactionMessage{
-			Key: &waE2E.MessageKey{
+			Key: &waCommon.MessageKey{
 				FromMe:    proto.Bool(true),
 				ID:        proto.String(messageID),
 				RemoteJID: proto.String(chatJID.String()),
@@ -516,7 +674,7 @@ func (h *MessageHandler) UpdateMessage(c *gin.Context) {
 	_, err = mc.Client.SendMessage(ctx, chatJID, &waE2E.Message{
 		ProtocolMessage: &waE2E.ProtocolMessage{
 			Type: waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
-			Key: &waE2E.MessageKey{
+			Key: &waCommon.MessageKey{
 				FromMe:    proto.Bool(true),
 				ID:        proto.String(messageID),
 				RemoteJID: proto.String(chatJID.String()),
@@ -545,16 +703,14 @@ func (h *MessageHandler) UpdateMessage(c *gin.Context) {
 }
 
 func (h *MessageHandler) buildImageMessage(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) (*waE2E.Message, error) {
-	if req.MediaURL == "" {
-		return nil, fmt.Errorf("media_url is required for image messages")
-	}
-
-	data, err := h.downloadMedia(ctx, req.MediaURL)
+	data, err := h.mediaData(ctx, req, "image")
 	if err != nil {
-		return nil, fmt.Errorf("failed to download image: %w", err)
+		return nil, err
 	}
 
-	uploaded, err := client.Upload(ctx, data, whatsmeow.MediaImage)
+	info := media.Inspect(data, req.Mime)
+
+	uploaded, err := h.uploads.Upload(ctx, client, data, whatsmeow.MediaImage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload image: %w", err)
 	}
@@ -567,7 +723,10 @@ func (h *MessageHandler) buildImageMessage(ctx context.Context, client *whatsmeo
 			FileEncSHA256: uploaded.FileEncSHA256,
 			FileSHA256:    uploaded.FileSHA256,
 			FileLength:    proto.Uint64(uploaded.FileLength),
-			Mimetype:      proto.String(req.Mime),
+			Mimetype:      proto.String(info.Mime),
+			Width:         optionalUint32(info.Width),
+			Height:        optionalUint32(info.Height),
+			JPEGThumbnail: info.Thumbnail,
 		},
 	}
 
@@ -579,16 +738,14 @@ func (h *MessageHandler) buildImageMessage(ctx context.Context, client *whatsmeo
 }
 
 func (h *MessageHandler) buildVideoMessage(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) (*waE2E.Message, error) {
-	if req.MediaURL == "" {
-		return nil, fmt.Errorf("media_url is required for video messages")
-	}
-
-	data, err := h.downloadMedia(ctx, req.MediaURL)
+	data, err := h.mediaData(ctx, req, "video")
 	if err != nil {
-		return nil, fmt.Errorf("failed to download video: %w", err)
+		return nil, err
 	}
 
-	uploaded, err := client.Upload(ctx, data, whatsmeow.MediaVideo)
+	info := media.Inspect(data, req.Mime)
+
+	uploaded, err := h.uploads.Upload(ctx, client, data, whatsmeow.MediaVideo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload video: %w", err)
 	}
@@ -601,7 +758,10 @@ func (h *MessageHandler) buildVideoMessage(ctx context.Context, client *whatsmeo
 			FileEncSHA256: uploaded.FileEncSHA256,
 			FileSHA256:    uploaded.FileSHA256,
 			FileLength:    proto.Uint64(uploaded.FileLength),
-			Mimetype:      proto.String(req.Mime),
+			Mimetype:      proto.String(info.Mime),
+			Seconds:       optionalUint32(info.Seconds),
+			Width:         optionalUint32(info.Width),
+			Height:        optionalUint32(info.Height),
 		},
 	}
 
@@ -613,21 +773,19 @@ func (h *MessageHandler) buildVideoMessage(ctx context.Context, client *whatsmeo
 }
 
 func (h *MessageHandler) buildDocumentMessage(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) (*waE2E.Message, error) {
-	if req.MediaURL == "" {
-		return nil, fmt.Errorf("media_url is required for document messages")
-	}
-
-	data, err := h.downloadMedia(ctx, req.MediaURL)
+	data, err := h.mediaData(ctx, req, "document")
 	if err != nil {
-		return nil, fmt.Errorf("failed to download document: %w", err)
+		return nil, err
 	}
 
-	uploaded, err := client.Upload(ctx, data, whatsmeow.MediaDocument)
+	info := media.Inspect(data, req.Mime)
+
+	uploaded, err := h.uploads.Upload(ctx, client, data, whatsmeow.MediaDocument)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload document: %w", err)
 	}
 
-	return &waE2E.Message{
+	msg := &waE2E.Message{
 		DocumentMessage: &waE2E.DocumentMessage{
 			URL:           proto.String(uploaded.URL),
 			DirectPath:    proto.String(uploaded.DirectPath),
@@ -636,22 +794,36 @@ func (h *MessageHandler) buildDocumentMessage(ctx context.Context, client *whats
 			FileSHA256:    uploaded.FileSHA256,
 			FileLength:    proto.Uint64(uploaded.FileLength),
 			FileName:      proto.String(req.FileName),
-			Mimetype:      proto.String(req.Mime),
+			Mimetype:      proto.String(info.Mime),
+			PageCount:     optionalUint32(info.PageCount),
+			JPEGThumbnail: info.Thumbnail,
 		},
-	}, nil
+	}
+
+	if req.Body != "" {
+		msg.DocumentMessage.Caption = proto.String(req.Body)
+	}
+
+	return msg, nil
 }
 
 func (h *MessageHandler) buildAudioMessage(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) (*waE2E.Message, error) {
-	if req.Audio == nil || req.Audio.URL == "" {
-		return nil, fmt.Errorf("audio data is required")
+	data, err := h.mediaData(ctx, req, "audio")
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := h.downloadMedia(ctx, req.Audio.URL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download audio: %w", err)
+	// Voice notes only play as Opus, other audio keeps its own type
+	ptt := req.Audio != nil && req.Audio.PTT
+	info := media.Inspect(data, req.Mime)
+	if ptt && info.Mime != media.OpusMime {
+		return nil, &apiError{http.StatusBadRequest, "unsupported_audio", fmt.Sprintf("voice notes must be OGG/Opus audio, got %s", info.Mime)}
+	}
+	if !strings.HasPrefix(info.Mime, "audio/") {
+		return nil, &apiError{http.StatusBadRequest, "unsupported_audio", fmt.Sprintf("unsupported audio type %s", info.Mime)}
 	}
 
-	uploaded, err := client.Upload(ctx, data, whatsmeow.MediaAudio)
+	uploaded, err := h.uploads.Upload(ctx, client, data, whatsmeow.MediaAudio)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload audio: %w", err)
 	}
@@ -664,23 +836,31 @@ func (h *MessageHandler) buildAudioMessage(ctx context.Context, client *whatsmeo
 			FileEncSHA256: uploaded.FileEncSHA256,
 			FileSHA256:    uploaded.FileSHA256,
 			FileLength:    proto.Uint64(uploaded.FileLength),
-			Mimetype:      proto.String("audio/ogg; codecs=opus"),
-			PTT:           proto.Bool(req.Audio.PTT),
+			Mimetype:      proto.String(info.Mime),
+			Seconds:       optionalUint32(info.Seconds),
+			PTT:           proto.Bool(ptt),
+			Waveform:      info.Waveform,
 		},
 	}, nil
 }
 
 func (h *MessageHandler) buildStickerMessage(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) (*waE2E.Message, error) {
-	if req.MediaURL == "" {
-		return nil, fmt.Errorf("media_url is required for sticker messages")
+	data, err := h.mediaData(ctx, req, "sticker")
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := h.downloadMedia(ctx, req.MediaURL)
+	var pack media.StickerPack
+	if req.Sticker != nil {
+		pack = media.StickerPack{Name: req.Sticker.PackName, Publisher: req.Sticker.PackAuthor, Emojis: req.Sticker.Emojis}
+	}
+	sticker, err := media.NormalizeSticker(data, pack)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download sticker: %w", err)
+		return nil, &apiError{http.StatusBadRequest, "unsupported_sticker", err.Error()}
 	}
 
-	uploaded, err := client.Upload(ctx, data, whatsmeow.MediaImage)
+	// Stickers are encrypted with the image media keys
+	uploaded, err := h.uploads.Upload(ctx, client, sticker.Data, whatsmeow.MediaImage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload sticker: %w", err)
 	}
@@ -694,6 +874,9 @@ func (h *MessageHandler) buildStickerMessage(ctx context.Context, client *whatsm
 			FileSHA256:    uploaded.FileSHA256,
 			FileLength:    proto.Uint64(uploaded.FileLength),
 			Mimetype:      proto.String("image/webp"),
+			Width:         optionalUint32(sticker.Width),
+			Height:        optionalUint32(sticker.Height),
+			IsAnimated:    proto.Bool(sticker.Animated),
 		},
 	}, nil
 }
@@ -755,25 +938,6 @@ func (h *MessageHandler) buildPollMessage(req SendMessageRequest) (*waE2E.Messag
 	}, nil
 }
 
-func (h *MessageHandler) buildLinkMessage(req SendMessageRequest) (*waE2E.Message, error) {
-	if req.Link == nil || req.Link.URL == "" {
-		return nil, fmt.Errorf("link data is required")
-	}
-
-	text := req.Link.URL
-	if req.Link.Caption != "" {
-		text = req.Link.Caption + "\n" + req.Link.URL
-	}
-
-	return &waE2E.Message{
-		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
-			Text:         proto.String(text),
-			CanonicalURL: proto.String(req.Link.URL),
-			MatchedText:  proto.String(req.Link.URL),
-		},
-	}, nil
-}
-
 // Fixed: Added ctx parameter to SendPresence
 func (h *MessageHandler) sendPresence(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) error {
 	if req.Presence == nil {
@@ -814,22 +978,3 @@ func (h *MessageHandler) sendChatPresence(ctx context.Context, client *whatsmeow
 	// Fixed: Added ctx as first parameter
 	return client.SendChatPresence(ctx, jid, state, types.ChatPresenceMediaText)
 }
-
-func (h *MessageHandler) downloadMedia(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download media: status %d", resp.StatusCode)
-	}
-
-	return io.ReadAll(resp.Body)
-}
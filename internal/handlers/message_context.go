@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// mentionPattern matches @<phone> tokens in message text
+var mentionPattern = regexp.MustCompile(`@(\d{6,15})\b`)
+
+// buildContextInfo builds the ContextInfo carrying the quoted message and
+// mentions of a request, it returns nil if the request has neither
+func (h *MessageHandler) buildContextInfo(mc *wa.ManagedClient, to types.JID, req SendMessageRequest) (*waE2E.ContextInfo, error) {
+	mentions, err := mentionedJIDs(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.ReplyTo == nil && len(mentions) == 0 {
+		return nil, nil
+	}
+
+	info := &waE2E.ContextInfo{}
+	if len(mentions) > 0 {
+		info.MentionedJID = mentions
+	}
+
+	if req.ReplyTo != nil {
+		if err := h.addQuotedMessage(info, mc, to, req.ReplyTo); err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+// addQuotedMessage points the ContextInfo at the replied-to message. The
+// quoted content and sender come from the message store, which holds history
+// sync imports and the messages sent or received within the live message
+// retention; otherwise the quote is sent without content.
+func (h *MessageHandler) addQuotedMessage(info *waE2E.ContextInfo, mc *wa.ManagedClient, to types.JID, reply *ReplyInfo) error {
+	if reply.MessageID == "" {
+		return &apiError{http.StatusBadRequest, "invalid_reply", "reply_to.message_id is required"}
+	}
+
+	chat := to
+	if reply.Chat != "" {
+		parsed, err := types.ParseJID(reply.Chat)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, "invalid_reply", "invalid reply_to.chat JID"}
+		}
+		chat = parsed
+	}
+
+	var participant types.JID
+	if reply.Participant != "" {
+		parsed, err := parseUserJID(reply.Participant)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, "invalid_reply", "invalid reply_to.participant JID"}
+		}
+		participant = parsed
+	}
+
+	quoted := &waE2E.Message{Conversation: proto.String("")}
+	stored, err := h.clientManager.GetStoredMessage(mc.WaAccountID, chat, reply.MessageID)
+	if err != nil {
+		log.Warn().Err(err).Str("message_id", reply.MessageID).Msg("Failed to look up quoted message")
+	}
+	if stored != nil {
+		quoted.Conversation = proto.String(stored.Text)
+		if participant.IsEmpty() {
+			if stored.FromMe && mc.Client.Store.ID != nil {
+				participant = mc.Client.Store.ID.ToNonAD()
+			} else if sender, err := types.ParseJID(stored.SenderJID); err == nil {
+				participant = sender.ToNonAD()
+			}
+		}
+	}
+
+	if participant.IsEmpty() {
+		if chat.Server == types.GroupServer {
+			return &apiError{http.StatusBadRequest, "invalid_reply", "reply_to.participant is required to quote an unknown group message"}
+		}
+		// In a private chat an unknown message is most likely from the other side
+		participant = chat.ToNonAD()
+	}
+
+	info.StanzaID = proto.String(reply.MessageID)
+	info.Participant = proto.String(participant.String())
+	info.QuotedMessage = quoted
+	if chat != to {
+		info.RemoteJID = proto.String(chat.String())
+	}
+
+	return nil
+}
+
+// mentionedJIDs returns the explicit mentions of a request followed by the
+// @<phone> tokens found in its text, without duplicates
+func mentionedJIDs(req SendMessageRequest) ([]string, error) {
+	seen := map[string]bool{}
+	mentions := []string{}
+	add := func(jid types.JID) {
+		if s := jid.String(); !seen[s] {
+			seen[s] = true
+			mentions = append(mentions, s)
+		}
+	}
+
+	for _, mention := range req.Mentions {
+		jid, err := parseUserJID(mention)
+		if err != nil {
+			return nil, &apiError{http.StatusBadRequest, "invalid_mention", "invalid mention " + mention}
+		}
+		add(jid)
+	}
+
+	for _, text := range messageTexts(req) {
+		for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+			add(types.NewJID(match[1], types.DefaultUserServer))
+		}
+	}
+
+	return mentions, nil
+}
+
+// messageTexts returns the text and captions of a request that can hold mentions
+func messageTexts(req SendMessageRequest) []string {
+	texts := []string{req.Body, req.Caption}
+	if req.Image != nil {
+		texts = append(texts, req.Image.Caption)
+	}
+	if req.Video != nil {
+		texts = append(texts, req.Video.Caption)
+	}
+	if req.Link != nil {
+		texts = append(texts, req.Link.Caption)
+	}
+	return texts
+}
+
+// parseUserJID parses a user JID, a bare phone number is taken as a WhatsApp user
+func parseUserJID(value string) (types.JID, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "@") {
+		value = strings.TrimPrefix(value, "+") + "@" + types.DefaultUserServer
+	}
+	jid, err := types.ParseJID(value)
+	if err != nil {
+		return types.JID{}, err
+	}
+	if jid.User == "" {
+		return types.JID{}, &apiError{http.StatusBadRequest, "invalid_jid", "JID has no user"}
+	}
+	return jid.ToNonAD(), nil
+}
+
+// attachContextInfo sets the ContextInfo on whichever content the message carries
+func attachContextInfo(message *waE2E.Message, info *waE2E.ContextInfo) {
+	if message == nil || info == nil {
+		return
+	}
+
+	switch {
+	case message.ExtendedTextMessage != nil:
+		message.ExtendedTextMessage.ContextInfo = info
+	case message.ImageMessage != nil:
+		message.ImageMessage.ContextInfo = info
+	case message.VideoMessage != nil:
+		message.VideoMessage.ContextInfo = info
+	case message.AudioMessage != nil:
+		message.AudioMessage.ContextInfo = info
+	case message.DocumentMessage != nil:
+		message.DocumentMessage.ContextInfo = info
+	case message.StickerMessage != nil:
+		message.StickerMessage.ContextInfo = info
+	case message.LocationMessage != nil:
+		message.LocationMessage.ContextInfo = info
+	case message.ContactMessage != nil:
+		message.ContactMessage.ContextInfo = info
+	case message.PollCreationMessage != nil:
+		message.PollCreationMessage.ContextInfo = info
+	}
+}
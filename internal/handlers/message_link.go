@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/linkpreview"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
+	"go.mau.fi/whatsmeow"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+// linkThumbnailSize is the largest side of the uploaded preview image, the
+// inline thumbnail is the usual small one
+const linkThumbnailSize = 720
+
+// buildTextMessage builds a text message, with a preview of its first URL if
+// the request asks for one
+func (h *MessageHandler) buildTextMessage(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) *waE2E.Message {
+	text := &waE2E.ExtendedTextMessage{
+		Text: proto.String(req.Body),
+	}
+
+	if req.LinkPreview {
+		if link := linkpreview.FindURL(req.Body); link != "" {
+			h.addLinkPreview(ctx, client, text, link)
+		}
+	}
+
+	return &waE2E.Message{ExtendedTextMessage: text}
+}
+
+// buildLinkMessage builds a message with the URL below the caption and a
+// preview of the page
+func (h *MessageHandler) buildLinkMessage(ctx context.Context, client *whatsmeow.Client, req SendMessageRequest) (*waE2E.Message, error) {
+	if req.Link == nil || req.Link.URL == "" {
+		return nil, fmt.Errorf("link data is required")
+	}
+
+	body := req.Link.URL
+	if req.Link.Caption != "" {
+		body = req.Link.Caption + "\n" + req.Link.URL
+	}
+
+	text := &waE2E.ExtendedTextMessage{
+		Text: proto.String(body),
+	}
+	h.addLinkPreview(ctx, client, text, req.Link.URL)
+
+	return &waE2E.Message{ExtendedTextMessage: text}, nil
+}
+
+// addLinkPreview adds the title, description and thumbnail of the page at
+// link to a text message. A page that can't be previewed leaves the message
+// with a plain link, the message is sent either way.
+func (h *MessageHandler) addLinkPreview(ctx context.Context, client *whatsmeow.Client, text *waE2E.ExtendedTextMessage, link string) {
+	text.MatchedText = proto.String(link)
+
+	preview, err := h.previews.Fetch(ctx, link)
+	if err != nil {
+		log.Warn().Err(err).Str("url", link).Msg("Failed to fetch link preview")
+		return
+	}
+
+	text.Title = proto.String(preview.Title)
+	if preview.Description != "" {
+		text.Description = proto.String(preview.Description)
+	}
+	text.PreviewType = waE2E.ExtendedTextMessage_NONE.Enum()
+
+	if len(preview.Image) == 0 {
+		return
+	}
+	// The remote image is decoded once, within the pixel limit of ScaleJPEG,
+	// the inline thumbnail is made from the small copy
+	image, width, height, err := media.ScaleJPEG(preview.Image, linkThumbnailSize)
+	if err != nil {
+		log.Warn().Err(err).Str("url", preview.ImageURL).Msg("Failed to scale link preview image")
+		return
+	}
+	thumbnail, err := media.Thumbnail(image)
+	if err != nil {
+		log.Warn().Err(err).Str("url", preview.ImageURL).Msg("Failed to create link preview thumbnail")
+		return
+	}
+	text.JPEGThumbnail = thumbnail
+
+	// The larger image recipients show is uploaded, like media
+	uploaded, err := h.uploads.Upload(ctx, client, image, whatsmeow.MediaLinkThumbnail)
+	if err != nil {
+		log.Warn().Err(err).Str("url", preview.ImageURL).Msg("Failed to upload link preview image")
+		return
+	}
+	text.ThumbnailDirectPath = proto.String(uploaded.DirectPath)
+	text.ThumbnailSHA256 = uploaded.FileSHA256
+	text.ThumbnailEncSHA256 = uploaded.FileEncSHA256
+	text.MediaKey = uploaded.MediaKey
+	text.MediaKeyTimestamp = proto.Int64(time.Now().Unix())
+	text.ThumbnailWidth = proto.Uint32(width)
+	text.ThumbnailHeight = proto.Uint32(height)
+}
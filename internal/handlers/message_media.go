@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/fetch"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
+	"google.golang.org/protobuf/proto"
+)
+
+// bindSendMessage binds a JSON request, or a multipart/form-data request with
+// the media as its "file" part. Form requests carry the plain fields only,
+// plus "ptt" for voice notes, "pack_name", "pack_author" and "emojis" for
+// stickers and "variables[<name>]" for templates.
+func (h *MessageHandler) bindSendMessage(c *gin.Context, req *SendMessageRequest) error {
+	if c.ContentType() != gin.MIMEMultipartPOSTForm {
+		if err := c.ShouldBindJSON(req); err != nil {
+			return bodyError(err)
+		}
+		return nil
+	}
+
+	if err := c.ShouldBind(req); err != nil {
+		return bodyError(err)
+	}
+
+	if value := c.PostForm("ptt"); value != "" {
+		ptt, err := strconv.ParseBool(value)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, "invalid_request", "ptt must be a boolean"}
+		}
+		req.Audio = &AudioInfo{PTT: ptt}
+	}
+
+	if req.Type == "sticker" {
+		req.Sticker = &StickerInfo{
+			PackName:   c.PostForm("pack_name"),
+			PackAuthor: c.PostForm("pack_author"),
+			Emojis:     c.PostFormArray("emojis"),
+		}
+	}
+
+	if req.Template != "" {
+		req.Variables = c.PostFormMap("variables")
+	}
+
+	header, err := c.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil
+	}
+	if err != nil {
+		return bodyError(err)
+	}
+	if header.Size > h.media.MaxSize() {
+		return mediaTooLarge(h.media.MaxSize())
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return &apiError{http.StatusBadRequest, "invalid_request", "failed to read file"}
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.media.MaxSize()+1))
+	if err != nil {
+		return &apiError{http.StatusBadRequest, "invalid_request", "failed to read file"}
+	}
+	if int64(len(data)) > h.media.MaxSize() {
+		return mediaTooLarge(h.media.MaxSize())
+	}
+
+	req.media = data
+	if req.FileName == "" {
+		req.FileName = header.Filename
+	}
+	if req.Mime == "" {
+		req.Mime = header.Header.Get("Content-Type")
+	}
+
+	return nil
+}
+
+// requestSizeLimit is the largest send request accepted, with media of the
+// largest size inline
+func (h *MessageHandler) requestSizeLimit() int64 {
+	return media.RequestSizeLimit(h.media.MaxSize())
+}
+
+// resolveMedia loads inline base64 media and uploads referenced by media_id
+// into the request
+func (h *MessageHandler) resolveMedia(req *SendMessageRequest) error {
+	switch {
+	case req.media != nil:
+		if int64(len(req.media)) > h.media.MaxSize() {
+			return mediaTooLarge(h.media.MaxSize())
+		}
+
+	case req.MediaBase64 != "":
+		data, mime, err := media.DecodeBase64(req.MediaBase64, h.media.MaxSize())
+		if errors.Is(err, media.ErrTooLarge) {
+			return mediaTooLarge(h.media.MaxSize())
+		}
+		if err != nil {
+			return &apiError{http.StatusBadRequest, "invalid_media", err.Error()}
+		}
+		req.media = data
+		if req.Mime == "" {
+			req.Mime = mime
+		}
+
+	case req.MediaID != "":
+		m, data, err := h.media.Get(req.MediaID)
+		if err != nil {
+			return &apiError{http.StatusInternalServerError, "media_error", "failed to load media"}
+		}
+		// Uploads of other accounts are as good as missing
+		if m == nil || m.WaAccountID != req.WaAccountID {
+			return &apiError{http.StatusNotFound, "media_not_found", "media does not exist or expired"}
+		}
+		req.media = data
+		if req.FileName == "" {
+			req.FileName = m.FileName
+		}
+		if req.Mime == "" {
+			req.Mime = m.Mime
+		}
+	}
+
+	return nil
+}
+
+// mediaData returns the media of a message: the uploaded or inline media if
+// the request has any, otherwise the media downloaded from its URL
+func (h *MessageHandler) mediaData(ctx context.Context, req SendMessageRequest, kind string) ([]byte, error) {
+	if req.media != nil {
+		return req.media, nil
+	}
+
+	url := mediaURL(req)
+	if url == "" {
+		return nil, &apiError{http.StatusBadRequest, "missing_media", fmt.Sprintf("a file, media_base64, media_id or media_url is required for %s messages", kind)}
+	}
+
+	data, err := h.fetcher.Get(ctx, url, mediaContentTypes[kind]...)
+	if err != nil {
+		return nil, h.fetchError(kind, err)
+	}
+	return data, nil
+}
+
+// mediaContentTypes are the content types accepted when downloading media of
+// a message type, any type is fine for documents
+var mediaContentTypes = map[string][]string{
+	"image":   {"image/*"},
+	"video":   {"video/*"},
+	"audio":   {"audio/*", "application/ogg", "video/ogg"},
+	"sticker": {"image/*"},
+}
+
+// fetchError maps a failed media download onto a client error, the URL is
+// the caller's to fix
+func (h *MessageHandler) fetchError(kind string, err error) *apiError {
+	switch {
+	case errors.Is(err, fetch.ErrInvalidURL):
+		return &apiError{http.StatusBadRequest, "invalid_media_url", err.Error()}
+	case errors.Is(err, fetch.ErrBlockedAddress):
+		return &apiError{http.StatusBadRequest, "media_url_blocked", "media URL resolves to an address that is not allowed"}
+	case errors.Is(err, fetch.ErrTooLarge):
+		return mediaTooLarge(h.fetcher.MaxSize())
+	case errors.Is(err, fetch.ErrUnsupportedType):
+		return &apiError{http.StatusUnsupportedMediaType, "unsupported_media_type", fmt.Sprintf("%s URL serves %v", kind, err)}
+	default:
+		return &apiError{http.StatusUnprocessableEntity, "media_download_failed", fmt.Sprintf("failed to download %s: %v", kind, err)}
+	}
+}
+
+// mediaURL returns the URL of the type specific object, falling back to media_url
+func mediaURL(req SendMessageRequest) string {
+	url := ""
+	switch req.Type {
+	case "image":
+		if req.Image != nil {
+			url = req.Image.URL
+		}
+	case "video":
+		if req.Video != nil {
+			url = req.Video.URL
+		}
+	case "audio":
+		if req.Audio != nil {
+			url = req.Audio.URL
+		}
+	case "document":
+		if req.Document != nil {
+			url = req.Document.URL
+		}
+	case "sticker":
+		if req.Sticker != nil {
+			url = req.Sticker.URL
+		}
+	}
+
+	if url == "" {
+		url = req.MediaURL
+	}
+	return url
+}
+
+// bodyError maps a failure to read a request body onto a client error, the
+// body may have hit the size cap of middleware.LimitBody
+func bodyError(err error) *apiError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &apiError{http.StatusRequestEntityTooLarge, "request_too_large", "request body is too large"}
+	}
+	return &apiError{http.StatusBadRequest, "invalid_request", err.Error()}
+}
+
+func mediaTooLarge(maxSize int64) *apiError {
+	return &apiError{http.StatusRequestEntityTooLarge, "media_too_large", fmt.Sprintf("media exceeds the maximum size of %d bytes", maxSize)}
+}
+
+// optionalUint32 leaves unknown (zero) media attributes unset
+func optionalUint32(v uint32) *uint32 {
+	if v == 0 {
+		return nil
+	}
+	return proto.Uint32(v)
+}
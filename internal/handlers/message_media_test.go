@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/fetch"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
+)
+
+// newTestMessageHandler has a media store of maxSize bytes per upload, and a
+// fetcher of 16 bytes allowed to download from httptest servers
+func newTestMessageHandler(t *testing.T, maxSize int64) *MessageHandler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := media.NewStore(t.TempDir(), maxSize, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	fetcher := fetch.New(fetch.Options{
+		MaxSize:         16,
+		Timeout:         5 * time.Second,
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")},
+	})
+	return NewMessageHandler(nil, nil, store, fetcher, nil)
+}
+
+// bindRequest binds and resolves a send request like SendMessage does
+func bindRequest(h *MessageHandler, contentType string, body []byte) (SendMessageRequest, error) {
+	var req SendMessageRequest
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	c.Request.Body = http.MaxBytesReader(w, c.Request.Body, h.requestSizeLimit())
+
+	if err := h.bindSendMessage(c, &req); err != nil {
+		return req, err
+	}
+	return req, h.resolveMedia(&req)
+}
+
+func TestBindSendMessageMedia(t *testing.T) {
+	h := newTestMessageHandler(t, 16)
+
+	uploaded, err := h.media.Save(strings.NewReader("uploaded"), "acc-1", "upload.txt", "text/plain")
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	form := func(fields map[string]string, file string) ([]byte, string) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for name, value := range fields {
+			mw.WriteField(name, value)
+		}
+		if file != "" {
+			part, _ := mw.CreateFormFile("file", "voice.ogg")
+			part.Write([]byte(file))
+		}
+		mw.Close()
+		return buf.Bytes(), mw.FormDataContentType()
+	}
+
+	voiceBody, voiceType := form(map[string]string{"wa_account_id": "acc-1", "to": "123", "type": "audio", "ptt": "true"}, "voice")
+	largeBody, largeType := form(map[string]string{"wa_account_id": "acc-1", "to": "123", "type": "audio"}, strings.Repeat("x", 17))
+	badPTTBody, badPTTType := form(map[string]string{"wa_account_id": "acc-1", "to": "123", "type": "audio", "ptt": "maybe"}, "voice")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		media       string
+		fileName    string
+		mime        string
+		status      int
+		code        string
+	}{
+		{
+			name:        "data URL",
+			contentType: "application/json",
+			body:        []byte(`{"wa_account_id":"acc-1","to":"123","type":"image","media_base64":"data:image/png;base64,` + base64.StdEncoding.EncodeToString([]byte("png")) + `"}`),
+			media:       "png",
+			mime:        "image/png",
+		},
+		{
+			name:        "upload",
+			contentType: "application/json",
+			body:        []byte(`{"wa_account_id":"acc-1","to":"123","type":"document","media_id":"` + uploaded.ID + `"}`),
+			media:       "uploaded",
+			fileName:    "upload.txt",
+			mime:        "text/plain",
+		},
+		{
+			name:        "upload of another account",
+			contentType: "application/json",
+			body:        []byte(`{"wa_account_id":"acc-2","to":"123","type":"document","media_id":"` + uploaded.ID + `"}`),
+			status:      http.StatusNotFound,
+			code:        "media_not_found",
+		},
+		{
+			name:        "base64 over the media size",
+			contentType: "application/json",
+			body:        []byte(`{"wa_account_id":"acc-1","to":"123","type":"image","media_base64":"` + base64.StdEncoding.EncodeToString(make([]byte, 17)) + `"}`),
+			status:      http.StatusRequestEntityTooLarge,
+			code:        "media_too_large",
+		},
+		{
+			name:        "body over the request size",
+			contentType: "application/json",
+			body:        []byte(`{"wa_account_id":"acc-1","to":"123","type":"image","media_base64":"` + strings.Repeat("A", 2<<20) + `"}`),
+			status:      http.StatusRequestEntityTooLarge,
+			code:        "request_too_large",
+		},
+		{
+			name:        "voice note file",
+			contentType: voiceType,
+			body:        voiceBody,
+			media:       "voice",
+			fileName:    "voice.ogg",
+			mime:        "application/octet-stream",
+		},
+		{
+			name:        "file over the media size",
+			contentType: largeType,
+			body:        largeBody,
+			status:      http.StatusRequestEntityTooLarge,
+			code:        "media_too_large",
+		},
+		{
+			name:        "invalid ptt",
+			contentType: badPTTType,
+			body:        badPTTBody,
+			status:      http.StatusBadRequest,
+			code:        "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		req, err := bindRequest(h, tt.contentType, tt.body)
+		if tt.status != 0 {
+			var apiErr *apiError
+			if !errors.As(err, &apiErr) || apiErr.Status != tt.status || apiErr.Code != tt.code {
+				t.Errorf("%s: error = %v, want %d %s", tt.name, err, tt.status, tt.code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if string(req.media) != tt.media || req.FileName != tt.fileName || req.Mime != tt.mime {
+			t.Errorf("%s: media %q, file %q, mime %q, want %q, %q, %q", tt.name, req.media, req.FileName, req.Mime, tt.media, tt.fileName, tt.mime)
+		}
+	}
+
+	req, err := bindRequest(h, voiceType, voiceBody)
+	if err != nil || req.Audio == nil || !req.Audio.PTT {
+		t.Errorf("voice note: audio = %+v, %v, want ptt", req.Audio, err)
+	}
+}
+
+func TestMediaData(t *testing.T) {
+	h := newTestMessageHandler(t, 16)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>"))
+		case "/large.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(make([]byte, 17))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		req    SendMessageRequest
+		data   string
+		status int
+		code   string
+	}{
+		{"inline", SendMessageRequest{Type: "image", media: []byte("inline")}, "inline", 0, ""},
+		{"no media", SendMessageRequest{Type: "image"}, "", http.StatusBadRequest, "missing_media"},
+		{"image URL", SendMessageRequest{Type: "image", Image: &MediaInfo{URL: srv.URL + "/image.png"}}, "png", 0, ""},
+		{"media_url", SendMessageRequest{Type: "sticker", MediaURL: srv.URL + "/image.png"}, "png", 0, ""},
+		{"wrong content type", SendMessageRequest{Type: "image", MediaURL: srv.URL + "/page.html"}, "", http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"any type for documents", SendMessageRequest{Type: "document", MediaURL: srv.URL + "/page.html"}, "<html>", 0, ""},
+		{"too large", SendMessageRequest{Type: "image", MediaURL: srv.URL + "/large.png"}, "", http.StatusRequestEntityTooLarge, "media_too_large"},
+		{"not found", SendMessageRequest{Type: "image", MediaURL: srv.URL + "/missing.png"}, "", http.StatusUnprocessableEntity, "media_download_failed"},
+		{"blocked address", SendMessageRequest{Type: "image", MediaURL: "http://[::1]/image.png"}, "", http.StatusBadRequest, "media_url_blocked"},
+		{"invalid URL", SendMessageRequest{Type: "image", MediaURL: "ftp://example.com/image.png"}, "", http.StatusBadRequest, "invalid_media_url"},
+	}
+
+	for _, tt := range tests {
+		data, err := h.mediaData(context.Background(), tt.req, tt.req.Type)
+		if tt.status != 0 {
+			var apiErr *apiError
+			if !errors.As(err, &apiErr) || apiErr.Status != tt.status || apiErr.Code != tt.code {
+				t.Errorf("%s: error = %v, want %d %s", tt.name, err, tt.status, tt.code)
+			}
+			continue
+		}
+		if err != nil || string(data) != tt.data {
+			t.Errorf("%s: data = %q, %v, want %q", tt.name, data, err, tt.data)
+		}
+	}
+}
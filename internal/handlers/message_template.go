@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/templates"
+)
+
+// applyTemplate renders the template of a message request into its body,
+// link caption or poll. The type comes from the template, a request that
+// gives one must give the same.
+func (h *MessageHandler) applyTemplate(req *SendMessageRequest) error {
+	if req.Body != "" || req.Poll != nil || (req.Link != nil && req.Link.Caption != "") {
+		return &apiError{http.StatusBadRequest, "invalid_request", "body, poll and link caption can't be combined with a template"}
+	}
+
+	rendered, err := h.clientManager.RenderTemplate(req.Template, req.TemplateVersion, req.Locale, req.Variables)
+	var missing *templates.MissingVariablesError
+	if errors.As(err, &missing) {
+		return &apiError{http.StatusBadRequest, "missing_variables", fmt.Sprintf("missing template variables: %s", strings.Join(missing.Names, ", "))}
+	}
+	if err != nil {
+		log.Error().Err(err).Str("template", req.Template).Msg("Failed to render message template")
+		return &apiError{http.StatusInternalServerError, "template_failed", "failed to render message template"}
+	}
+	if rendered == nil {
+		return templateNotFound()
+	}
+
+	if req.Type != "" && req.Type != rendered.Type {
+		return &apiError{http.StatusBadRequest, "invalid_request", fmt.Sprintf("template %s is a %s template", rendered.Name, rendered.Type)}
+	}
+	req.Type = rendered.Type
+
+	switch rendered.Type {
+	case "poll":
+		req.Poll = &PollInfo{Question: rendered.Body, Options: rendered.Options}
+	case "link":
+		if req.Link == nil {
+			return &apiError{http.StatusBadRequest, "invalid_request", "link data is required"}
+		}
+		req.Link.Caption = rendered.Body
+	default:
+		req.Body = rendered.Body
+	}
+
+	return nil
+}
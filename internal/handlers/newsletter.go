@@ -79,16 +79,9 @@ func (h *NewsletterHandler) ListNewsletters(c *gin.Context) {
 			"id": newsletter.ID.String(),
 		}
 
-		// Safely access ThreadMeta fields
-		if newsletter.ThreadMeta != nil {
-			if newsletter.ThreadMeta.Name != nil {
-				item["name"] = newsletter.ThreadMeta.Name.Text
-			}
-			if newsletter.ThreadMeta.Description != nil {
-				item["description"] = newsletter.ThreadMeta.Description.Text
-			}
-			item["subscribers"] = newsletter.ThreadMeta.SubscriberCount
-		}
+		item["name"] = newsletter.ThreadMeta.Name.Text
+		item["description"] = newsletter.ThreadMeta.Description.Text
+		item["subscribers"] = newsletter.ThreadMeta.SubscriberCount
 
 		newsletterList = append(newsletterList, item)
 	}
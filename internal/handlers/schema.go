@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+)
+
+// ListSchemas lists the webhook event types and schema versions with a JSON Schema document
+func ListSchemas(c *gin.Context) {
+	versions := make([]int, 0, webhooks.SchemaVersion)
+	for v := 1; v <= webhooks.SchemaVersion; v++ {
+		versions = append(versions, v)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"current_version": webhooks.SchemaVersion,
+		"versions":        versions,
+		"event_types":     webhooks.EventTypes(),
+		"request_id":      c.GetString("request_id"),
+	})
+}
+
+// GetSchema serves the JSON Schema of an event type's webhook payload as of a schema version
+func GetSchema(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	version := webhooks.SchemaVersion
+	if raw := c.Param("version"); raw != "latest" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "invalid_version",
+				"message":    "version must be a schema version number or latest",
+				"request_id": requestID,
+			})
+			return
+		}
+		version = v
+	}
+
+	schema, err := webhooks.JSONSchema(c.Param("eventType"), version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":      "schema_not_found",
+			"message":    err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/schema+json")
+	c.JSON(http.StatusOK, schema)
+}
@@ -7,22 +7,25 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
-	"image/png"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types/events"
+	"rsc.io/qr"
 )
 
+// qrImageSize is the approximate side in pixels of the QR code images
+const qrImageSize = 256
+
 type SessionHandler struct {
 	clientManager *wa.ClientManager
 	webhookSender *webhooks.Sender
@@ -85,7 +88,7 @@ func (h *SessionHandler) GetQR(c *gin.Context) {
 	go func() {
 		if err := mc.Client.Connect(); err != nil {
 			log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to connect")
-			h.webhookSender.SendStatus(waAccountID, "failed", err.Error())
+			h.clientManager.PublishEvent("status", webhooks.StatusPayload(waAccountID, "failed", err.Error()))
 		}
 	}()
 
@@ -94,10 +97,10 @@ func (h *SessionHandler) GetQR(c *gin.Context) {
 		switch v := evt.(type) {
 		case *events.Connected:
 			log.Info().Str("wa_account_id", waAccountID).Msg("Successfully connected via QR")
-			h.webhookSender.SendStatus(waAccountID, "connected", "")
+			h.clientManager.PublishEvent("status", webhooks.StatusPayload(waAccountID, "connected", ""))
 		case *events.LoggedOut:
 			log.Info().Str("wa_account_id", waAccountID).Msg("Logged out")
-			h.webhookSender.SendStatus(waAccountID, "logged_out", fmt.Sprintf("reason_%v", v.Reason))
+			h.clientManager.PublishEvent("status", webhooks.StatusPayload(waAccountID, "logged_out", fmt.Sprintf("reason_%v", v.Reason)))
 		}
 	})
 	defer mc.Client.RemoveEventHandler(eventHandler)
@@ -107,8 +110,8 @@ func (h *SessionHandler) GetQR(c *gin.Context) {
 	case evt := <-qrChan:
 		switch evt.Event {
 		case "code":
-			// Generate PNG image from QR code
-			qrImage, err := whatsmeow.GenerateQRImage(evt.Code, 256)
+			// Render the QR code as a PNG of about qrImageSize pixels
+			code, err := qr.Encode(evt.Code, qr.M)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to generate QR image")
 				c.JSON(http.StatusInternalServerError, gin.H{
@@ -118,20 +121,9 @@ func (h *SessionHandler) GetQR(c *gin.Context) {
 				})
 				return
 			}
+			code.Scale = max(1, qrImageSize/(code.Size+8))
 
-			// Convert to base64
-			var buf bytes.Buffer
-			if err := png.Encode(&buf, qrImage); err != nil {
-				log.Error().Err(err).Msg("Failed to encode QR image")
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":      "qr_encoding_failed",
-					"message":    "Failed to encode QR code",
-					"request_id": requestID,
-				})
-				return
-			}
-
-			qrBase64 := base64.StdEncoding.EncodeToString(buf.Bytes())
+			qrBase64 := base64.StdEncoding.EncodeToString(code.PNG())
 
 			c.JSON(http.StatusOK, QRResponse{
 				QRCode:       qrBase64,
@@ -236,7 +228,7 @@ func (h *SessionHandler) PairWithCode(c *gin.Context) {
 	go func() {
 		if err := mc.Client.Connect(); err != nil {
 			log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to connect")
-			h.webhookSender.SendStatus(waAccountID, "failed", err.Error())
+			h.clientManager.PublishEvent("status", webhooks.StatusPayload(waAccountID, "failed", err.Error()))
 		}
 	}()
 
@@ -279,7 +271,7 @@ func (h *SessionHandler) Reconnect(c *gin.Context) {
 	go func() {
 		if err := mc.Client.Connect(); err != nil {
 			log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to reconnect")
-			h.webhookSender.SendStatus(waAccountID, "failed", err.Error())
+			h.clientManager.PublishEvent("status", webhooks.StatusPayload(waAccountID, "failed", err.Error()))
 		}
 	}()
 
@@ -366,3 +358,102 @@ func (h *SessionHandler) GetStatus(c *gin.Context) {
 		"request_id":    requestID,
 	})
 }
+
+type SetCallPolicyRequest struct {
+	AutoReject bool   `json:"auto_reject"`
+	ReplyText  string `json:"reply_text" binding:"max=4096"`
+}
+
+func (h *SessionHandler) GetCallPolicy(c *gin.Context) {
+	waAccountID := c.Param("waAccountId")
+	requestID := c.GetString("request_id")
+
+	policy, err := h.clientManager.GetCallPolicy(waAccountID)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to get call policy")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "call_policy_failed",
+			"message":    "failed to get call policy",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wa_account_id": waAccountID,
+		"auto_reject":   policy.AutoReject,
+		"reply_text":    policy.ReplyText,
+		"request_id":    requestID,
+	})
+}
+
+func (h *SessionHandler) SetCallPolicy(c *gin.Context) {
+	waAccountID := c.Param("waAccountId")
+	requestID := c.GetString("request_id")
+	var req SetCallPolicyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_request",
+			"message":    err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	err := h.clientManager.SetCallPolicy(&store.CallPolicy{
+		WaAccountID: waAccountID,
+		AutoReject:  req.AutoReject,
+		ReplyText:   req.ReplyText,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to save call policy")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "call_policy_failed",
+			"message":    "failed to save call policy",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"auto_reject": req.AutoReject,
+		"reply_text":  req.ReplyText,
+		"request_id":  requestID,
+	})
+}
+
+func (h *SessionHandler) GetHistorySyncProgress(c *gin.Context) {
+	waAccountID := c.Param("waAccountId")
+	requestID := c.GetString("request_id")
+
+	progress, err := h.clientManager.GetHistorySyncProgress(waAccountID)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to get history sync progress")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "history_sync_failed",
+			"message":    "failed to get history sync progress",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	syncs := []map[string]interface{}{}
+	for _, p := range progress {
+		syncs = append(syncs, map[string]interface{}{
+			"sync_type":     p.SyncType,
+			"progress":      p.Progress,
+			"chunks":        p.Chunks,
+			"conversations": p.Conversations,
+			"messages":      p.Messages,
+			"updated_at":    p.UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wa_account_id": waAccountID,
+		"syncs":         syncs,
+		"request_id":    requestID,
+	})
+}
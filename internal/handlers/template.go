@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/templates"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
+)
+
+// maxPollOptions is the most options WhatsApp shows in a poll
+const maxPollOptions = 12
+
+type TemplateHandler struct {
+	clientManager *wa.ClientManager
+}
+
+func NewTemplateHandler(cm *wa.ClientManager) *TemplateHandler {
+	return &TemplateHandler{clientManager: cm}
+}
+
+// TemplateRequest creates a template or a new version of it. Locales maps a
+// locale like "en" or "pt-BR" to the content in that language.
+type TemplateRequest struct {
+	Name          string                           `json:"name"` // on create only, the path names the template on update
+	Type          string                           `json:"type" binding:"required"`
+	DefaultLocale string                           `json:"default_locale"` // required with more than one locale
+	Locales       map[string]store.TemplateVariant `json:"locales" binding:"required"`
+}
+
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	list, err := h.clientManager.ListTemplates()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list message templates")
+		templateError(c, &apiError{http.StatusInternalServerError, "templates_failed", "failed to list message templates"})
+		return
+	}
+
+	result := []gin.H{}
+	for _, t := range list {
+		result = append(result, templateResponse(t))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates":  result,
+		"count":      len(result),
+		"request_id": requestID,
+	})
+}
+
+// GetTemplate returns the latest version of a template, or the one in the
+// version query parameter
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	version := 0
+	if v := c.Query("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			templateError(c, &apiError{http.StatusBadRequest, "invalid_request", "version must be a positive integer"})
+			return
+		}
+		version = n
+	}
+
+	t, ok := h.loadTemplate(c, version)
+	if !ok {
+		return
+	}
+
+	response := templateResponse(t)
+	response["request_id"] = requestID
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TemplateHandler) ListVersions(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	name := c.Param("templateName")
+
+	versions, err := h.clientManager.ListTemplateVersions(name)
+	if err != nil {
+		log.Error().Err(err).Str("template", name).Msg("Failed to list message template versions")
+		templateError(c, &apiError{http.StatusInternalServerError, "templates_failed", "failed to list message template versions"})
+		return
+	}
+	if len(versions) == 0 {
+		templateError(c, templateNotFound())
+		return
+	}
+
+	result := []gin.H{}
+	for _, t := range versions {
+		result = append(result, templateResponse(t))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"versions":   result,
+		"count":      len(result),
+		"request_id": requestID,
+	})
+}
+
+// CreateTemplate creates version 1 of a template
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	var req TemplateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		templateError(c, &apiError{http.StatusBadRequest, "invalid_request", err.Error()})
+		return
+	}
+	if !templates.ValidName(req.Name) {
+		templateError(c, &apiError{http.StatusBadRequest, "invalid_template", "name must be 1 to 128 lowercase letters, digits, dots, dashes or underscores"})
+		return
+	}
+
+	t, apiErr := req.toTemplate(req.Name, 1)
+	if apiErr != nil {
+		templateError(c, apiErr)
+		return
+	}
+
+	created, err := h.clientManager.CreateTemplate(t)
+	if err != nil {
+		log.Error().Err(err).Str("template", t.Name).Msg("Failed to create message template")
+		templateError(c, &apiError{http.StatusInternalServerError, "template_failed", "failed to create message template"})
+		return
+	}
+	if !created {
+		templateError(c, &apiError{http.StatusConflict, "template_exists", "template already exists, update it to add a version"})
+		return
+	}
+
+	response := templateResponse(t)
+	response["request_id"] = requestID
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateTemplate adds a version to a template, messages sent without a
+// template_version use it from then on
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	var req TemplateRequest
+
+	latest, ok := h.loadTemplate(c, 0)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		templateError(c, &apiError{http.StatusBadRequest, "invalid_request", err.Error()})
+		return
+	}
+
+	t, apiErr := req.toTemplate(latest.Name, latest.Version+1)
+	if apiErr != nil {
+		templateError(c, apiErr)
+		return
+	}
+
+	created, err := h.clientManager.CreateTemplate(t)
+	if err != nil {
+		log.Error().Err(err).Str("template", t.Name).Msg("Failed to update message template")
+		templateError(c, &apiError{http.StatusInternalServerError, "template_failed", "failed to update message template"})
+		return
+	}
+	if !created {
+		templateError(c, &apiError{http.StatusConflict, "template_conflict", "template was updated concurrently, retry"})
+		return
+	}
+
+	response := templateResponse(t)
+	response["request_id"] = requestID
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteTemplate deletes a template with all its versions
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	name := c.Param("templateName")
+
+	found, err := h.clientManager.DeleteTemplate(name)
+	if err != nil {
+		log.Error().Err(err).Str("template", name).Msg("Failed to delete message template")
+		templateError(c, &apiError{http.StatusInternalServerError, "template_failed", "failed to delete message template"})
+		return
+	}
+	if !found {
+		templateError(c, templateNotFound())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"request_id": requestID,
+	})
+}
+
+func (h *TemplateHandler) loadTemplate(c *gin.Context, version int) (*store.MessageTemplate, bool) {
+	name := c.Param("templateName")
+
+	t, err := h.clientManager.GetTemplate(name, version)
+	if err != nil {
+		log.Error().Err(err).Str("template", name).Msg("Failed to get message template")
+		templateError(c, &apiError{http.StatusInternalServerError, "template_failed", "failed to get message template"})
+		return nil, false
+	}
+	if t == nil {
+		templateError(c, templateNotFound())
+		return nil, false
+	}
+
+	return t, true
+}
+
+// toTemplate validates the request and builds a version of the template
+func (req *TemplateRequest) toTemplate(name string, version int) (*store.MessageTemplate, *apiError) {
+	invalid := func(format string, args ...interface{}) *apiError {
+		return &apiError{http.StatusBadRequest, "invalid_template", fmt.Sprintf(format, args...)}
+	}
+
+	if !slices.Contains(wa.TemplateTypes, req.Type) {
+		return nil, invalid("type must be one of %v", wa.TemplateTypes)
+	}
+	if len(req.Locales) == 0 {
+		return nil, invalid("at least one locale is required")
+	}
+
+	variants := make(map[string]store.TemplateVariant, len(req.Locales))
+	for locale, variant := range req.Locales {
+		normalized, ok := templates.NormalizeLocale(locale)
+		if !ok {
+			return nil, invalid("invalid locale %q", locale)
+		}
+		if _, ok := variants[normalized]; ok {
+			return nil, invalid("locale %q is given twice", locale)
+		}
+
+		if variant.Body == "" {
+			return nil, invalid("locale %q has no body", locale)
+		}
+		if req.Type == "poll" {
+			if len(variant.Options) < 2 || len(variant.Options) > maxPollOptions {
+				return nil, invalid("poll of locale %q must have 2 to %d options", locale, maxPollOptions)
+			}
+			if slices.Contains(variant.Options, "") {
+				return nil, invalid("poll of locale %q has an empty option", locale)
+			}
+		} else if len(variant.Options) > 0 {
+			return nil, invalid("only poll templates have options")
+		}
+
+		variants[normalized] = variant
+	}
+
+	defaultLocale := req.DefaultLocale
+	if defaultLocale == "" && len(variants) == 1 {
+		for locale := range variants {
+			defaultLocale = locale
+		}
+	}
+	defaultLocale, _ = templates.NormalizeLocale(defaultLocale)
+	if _, ok := variants[defaultLocale]; !ok {
+		return nil, invalid("default_locale must be one of the locales")
+	}
+
+	return &store.MessageTemplate{
+		Name:          name,
+		Version:       version,
+		Type:          req.Type,
+		DefaultLocale: defaultLocale,
+		Variants:      variants,
+	}, nil
+}
+
+// templateResponse includes the variables a message must give to use the
+// template, per locale
+func templateResponse(t *store.MessageTemplate) gin.H {
+	locales := gin.H{}
+	for locale, variant := range t.Variants {
+		variables := templates.Placeholders(slices.Concat([]string{variant.Body}, variant.Options)...)
+		locales[locale] = gin.H{
+			"body":      variant.Body,
+			"options":   nonNilStrings(variant.Options),
+			"variables": nonNilStrings(variables),
+		}
+	}
+
+	return gin.H{
+		"name":           t.Name,
+		"version":        t.Version,
+		"type":           t.Type,
+		"default_locale": t.DefaultLocale,
+		"locales":        locales,
+		"created_at":     t.CreatedAt,
+	}
+}
+
+func templateNotFound() *apiError {
+	return &apiError{http.StatusNotFound, "template_not_found", "message template not found"}
+}
+
+func templateError(c *gin.Context, err *apiError) {
+	c.JSON(err.Status, gin.H{
+		"error":      err.Code,
+		"message":    err.Message,
+		"request_id": c.GetString("request_id"),
+	})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+)
+
+func TestTemplateRequestToTemplate(t *testing.T) {
+	text := func(body string) store.TemplateVariant { return store.TemplateVariant{Body: body} }
+	poll := func(options ...string) store.TemplateVariant {
+		return store.TemplateVariant{Body: "Pick one", Options: options}
+	}
+
+	tests := []struct {
+		name          string
+		req           TemplateRequest
+		defaultLocale string
+		invalid       bool
+	}{
+		{"single locale is the default", TemplateRequest{Type: "text", Locales: map[string]store.TemplateVariant{"pt_BR": text("Olá {{name}}")}}, "pt-br", false},
+		{"default of several locales", TemplateRequest{Type: "text", DefaultLocale: "EN", Locales: map[string]store.TemplateVariant{"en": text("Hi"), "de": text("Hallo")}}, "en", false},
+		{"several locales without default", TemplateRequest{Type: "text", Locales: map[string]store.TemplateVariant{"en": text("Hi"), "de": text("Hallo")}}, "", true},
+		{"default not a locale", TemplateRequest{Type: "text", DefaultLocale: "fr", Locales: map[string]store.TemplateVariant{"en": text("Hi")}}, "", true},
+		{"unknown type", TemplateRequest{Type: "sticker", Locales: map[string]store.TemplateVariant{"en": text("Hi")}}, "", true},
+		{"no locales", TemplateRequest{Type: "text"}, "", true},
+		{"invalid locale", TemplateRequest{Type: "text", Locales: map[string]store.TemplateVariant{"not a locale": text("Hi")}}, "", true},
+		{"locale given twice", TemplateRequest{Type: "text", DefaultLocale: "pt-br", Locales: map[string]store.TemplateVariant{"pt_BR": text("Olá"), "pt-br": text("Oi")}}, "", true},
+		{"empty body", TemplateRequest{Type: "text", Locales: map[string]store.TemplateVariant{"en": text("")}}, "", true},
+		{"poll", TemplateRequest{Type: "poll", Locales: map[string]store.TemplateVariant{"en": poll("Yes", "No")}}, "en", false},
+		{"poll with one option", TemplateRequest{Type: "poll", Locales: map[string]store.TemplateVariant{"en": poll("Yes")}}, "", true},
+		{"poll with an empty option", TemplateRequest{Type: "poll", Locales: map[string]store.TemplateVariant{"en": poll("Yes", "")}}, "", true},
+		{"options of a text", TemplateRequest{Type: "text", Locales: map[string]store.TemplateVariant{"en": poll("Yes", "No")}}, "", true},
+	}
+
+	for _, tt := range tests {
+		tmpl, apiErr := tt.req.toTemplate("greeting", 2)
+		if tt.invalid {
+			if apiErr == nil || apiErr.Status != http.StatusBadRequest || apiErr.Code != "invalid_template" {
+				t.Errorf("%s: error = %+v, want invalid_template", tt.name, apiErr)
+			}
+			continue
+		}
+		if apiErr != nil {
+			t.Errorf("%s: %s", tt.name, apiErr.Message)
+			continue
+		}
+		if tmpl.Name != "greeting" || tmpl.Version != 2 || tmpl.Type != tt.req.Type || tmpl.DefaultLocale != tt.defaultLocale {
+			t.Errorf("%s: template = %+v, want greeting v2 of type %s with default locale %s", tt.name, tmpl, tt.req.Type, tt.defaultLocale)
+		}
+		if _, ok := tmpl.Variants[tt.defaultLocale]; !ok {
+			t.Errorf("%s: variants %v lack the normalized default locale %s", tt.name, tmpl.Variants, tt.defaultLocale)
+		}
+	}
+}
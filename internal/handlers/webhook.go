@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/fetch"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+)
+
+// urlCheckTimeout bounds resolving the host of a subscription URL
+const urlCheckTimeout = 5 * time.Second
+
+type WebhookHandler struct {
+	subscriptions *wa.SubscriptionSink
+	// allowed are the internal networks subscription URLs may point at
+	allowed []netip.Prefix
+}
+
+func NewWebhookHandler(subscriptions *wa.SubscriptionSink, allowed []netip.Prefix) *WebhookHandler {
+	return &WebhookHandler{subscriptions: subscriptions, allowed: allowed}
+}
+
+// WebhookSubscriptionRequest creates or replaces a subscription. Scope and
+// filter fields left empty match every account, tenant, event type or JID.
+type WebhookSubscriptionRequest struct {
+	WaAccountID string   `json:"wa_account_id"`
+	TenantID    string   `json:"tenant_id"` // matches the accounts assigned to the tenant
+	URL         string   `json:"url" binding:"required"`
+	Secret      string   `json:"secret"` // generated on create if empty, kept on update if empty
+	EventTypes  []string `json:"event_types"`
+	ChatJIDs    []string `json:"chat_jids"`
+	JIDs        []string `json:"jids"`
+	Format      string   `json:"format"` // native, cloudevents or cloudevents-binary, native if empty
+	Active      *bool    `json:"active"`
+}
+
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	subs, err := h.subscriptions.List(c.Query("wa_account_id"), c.Query("tenant_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhook subscriptions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "subscriptions_failed",
+			"message":    "failed to list webhook subscriptions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	result := []gin.H{}
+	for _, sub := range subs {
+		result = append(result, subscriptionResponse(sub))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": result,
+		"count":         len(result),
+		"request_id":    requestID,
+	})
+}
+
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+
+	response := subscriptionResponse(sub)
+	response["request_id"] = requestID
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	var req WebhookSubscriptionRequest
+
+	if !h.bindSubscriptionRequest(c, &req) {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			log.Error().Err(err).Msg("Failed to generate webhook secret")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":      "subscription_failed",
+				"message":    "failed to generate secret",
+				"request_id": requestID,
+			})
+			return
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	sub := req.toSubscription(secret)
+	if err := h.subscriptions.Create(sub); err != nil {
+		log.Error().Err(err).Msg("Failed to create webhook subscription")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "subscription_failed",
+			"message":    "failed to create webhook subscription",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// The secret is only ever returned on creation
+	response := subscriptionResponse(sub)
+	response["secret"] = sub.Secret
+	response["request_id"] = requestID
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	var req WebhookSubscriptionRequest
+
+	existing, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+
+	if !h.bindSubscriptionRequest(c, &req) {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = existing.Secret
+	}
+
+	sub := req.toSubscription(secret)
+	sub.ID = existing.ID
+
+	found, err := h.subscriptions.Update(sub)
+	if err != nil {
+		log.Error().Err(err).Str("subscription_id", sub.ID).Msg("Failed to update webhook subscription")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "subscription_failed",
+			"message":    "failed to update webhook subscription",
+			"request_id": requestID,
+		})
+		return
+	}
+	if !found {
+		subscriptionNotFound(c)
+		return
+	}
+
+	response := subscriptionResponse(sub)
+	response["request_id"] = requestID
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	id := c.Param("subscriptionId")
+
+	if _, err := uuid.Parse(id); err != nil {
+		subscriptionNotFound(c)
+		return
+	}
+
+	found, err := h.subscriptions.Delete(id)
+	if err != nil {
+		log.Error().Err(err).Str("subscription_id", id).Msg("Failed to delete webhook subscription")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "subscription_failed",
+			"message":    "failed to delete webhook subscription",
+			"request_id": requestID,
+		})
+		return
+	}
+	if !found {
+		subscriptionNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"request_id": requestID,
+	})
+}
+
+func (h *WebhookHandler) loadSubscription(c *gin.Context) (*store.WebhookSubscription, bool) {
+	requestID := c.GetString("request_id")
+	id := c.Param("subscriptionId")
+
+	if _, err := uuid.Parse(id); err != nil {
+		subscriptionNotFound(c)
+		return nil, false
+	}
+
+	sub, err := h.subscriptions.Get(id)
+	if err != nil {
+		log.Error().Err(err).Str("subscription_id", id).Msg("Failed to get webhook subscription")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "subscription_failed",
+			"message":    "failed to get webhook subscription",
+			"request_id": requestID,
+		})
+		return nil, false
+	}
+	if sub == nil {
+		subscriptionNotFound(c)
+		return nil, false
+	}
+
+	return sub, true
+}
+
+func (h *WebhookHandler) bindSubscriptionRequest(c *gin.Context, req *WebhookSubscriptionRequest) bool {
+	requestID := c.GetString("request_id")
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_request",
+			"message":    err.Error(),
+			"request_id": requestID,
+		})
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), urlCheckTimeout)
+	defer cancel()
+	if err := fetch.CheckURL(ctx, req.URL, h.allowed); err != nil {
+		message := "url must be an absolute http or https URL"
+		switch {
+		case errors.Is(err, fetch.ErrBlockedAddress):
+			message = "url must not point at a private, loopback or link-local address"
+		case errors.Is(err, fetch.ErrTimeout), errors.Is(err, fetch.ErrUnreachable):
+			message = "url host could not be resolved"
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_url",
+			"message":    message,
+			"request_id": requestID,
+		})
+		return false
+	}
+
+	format, err := webhooks.ParseFormat(req.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_format",
+			"message":    err.Error(),
+			"request_id": requestID,
+		})
+		return false
+	}
+	req.Format = string(format)
+
+	return true
+}
+
+func (req *WebhookSubscriptionRequest) toSubscription(secret string) *store.WebhookSubscription {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	return &store.WebhookSubscription{
+		WaAccountID: req.WaAccountID,
+		TenantID:    req.TenantID,
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  nonNilStrings(req.EventTypes),
+		ChatJIDs:    nonNilStrings(req.ChatJIDs),
+		JIDs:        nonNilStrings(req.JIDs),
+		Format:      req.Format,
+		Active:      active,
+	}
+}
+
+func subscriptionResponse(sub *store.WebhookSubscription) gin.H {
+	return gin.H{
+		"id":            sub.ID,
+		"wa_account_id": sub.WaAccountID,
+		"tenant_id":     sub.TenantID,
+		"url":           sub.URL,
+		"event_types":   nonNilStrings(sub.EventTypes),
+		"chat_jids":     nonNilStrings(sub.ChatJIDs),
+		"jids":          nonNilStrings(sub.JIDs),
+		"format":        sub.Format,
+		"active":        sub.Active,
+		"created_at":    sub.CreatedAt,
+		"updated_at":    sub.UpdatedAt,
+	}
+}
+
+// AccountTenantRequest assigns an account to a tenant, an empty tenant_id
+// removes the assignment
+type AccountTenantRequest struct {
+	TenantID string `json:"tenant_id" binding:"max=255"`
+}
+
+func (h *WebhookHandler) GetAccountTenant(c *gin.Context) {
+	waAccountID := c.Param("waAccountId")
+	requestID := c.GetString("request_id")
+
+	tenantID, err := h.subscriptions.AccountTenant(waAccountID)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to get account tenant")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "tenant_failed",
+			"message":    "failed to get account tenant",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wa_account_id": waAccountID,
+		"tenant_id":     tenantID,
+		"request_id":    requestID,
+	})
+}
+
+func (h *WebhookHandler) SetAccountTenant(c *gin.Context) {
+	waAccountID := c.Param("waAccountId")
+	requestID := c.GetString("request_id")
+
+	var req AccountTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_request",
+			"message":    err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if err := h.subscriptions.SetAccountTenant(waAccountID, req.TenantID); err != nil {
+		log.Error().Err(err).Str("wa_account_id", waAccountID).Msg("Failed to set account tenant")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "tenant_failed",
+			"message":    "failed to set account tenant",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wa_account_id": waAccountID,
+		"tenant_id":     req.TenantID,
+		"request_id":    requestID,
+	})
+}
+
+func subscriptionNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error":      "subscription_not_found",
+		"message":    "webhook subscription not found",
+		"request_id": c.GetString("request_id"),
+	})
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
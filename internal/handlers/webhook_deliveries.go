@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
+)
+
+type WebhookDeliveryHandler struct {
+	deliveries *wa.DeliveryLog
+}
+
+func NewWebhookDeliveryHandler(deliveries *wa.DeliveryLog) *WebhookDeliveryHandler {
+	return &WebhookDeliveryHandler{deliveries: deliveries}
+}
+
+// ListDeliveries lists recorded webhook delivery attempts, most recent first
+func (h *WebhookDeliveryHandler) ListDeliveries(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+
+	filter := store.WebhookDeliveryFilter{
+		WaAccountID: c.Query("wa_account_id"),
+		EventType:   c.Query("event_type"),
+		RequestID:   c.Query("request_id"),
+	}
+
+	switch c.Query("status") {
+	case "":
+	case "success":
+		succeeded := true
+		filter.Succeeded = &succeeded
+	case "failed":
+		succeeded := false
+		filter.Succeeded = &succeeded
+	default:
+		invalidDeliveryParameter(c, "status must be success or failed")
+		return
+	}
+
+	if raw := c.Query("status_code"); raw != "" {
+		code, err := strconv.Atoi(raw)
+		if err != nil {
+			invalidDeliveryParameter(c, "status_code must be a number")
+			return
+		}
+		filter.StatusCode = code
+	}
+
+	var ok bool
+	if filter.Since, ok = timeParam(c, "since"); !ok {
+		invalidDeliveryParameter(c, "since must be a unix or RFC 3339 timestamp")
+		return
+	}
+	if filter.Until, ok = timeParam(c, "until"); !ok {
+		invalidDeliveryParameter(c, "until must be a unix or RFC 3339 timestamp")
+		return
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 500 {
+		perPage = 50
+	}
+
+	deliveries, total, err := h.deliveries.List(filter, perPage, (page-1)*perPage)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhook deliveries")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "deliveries_failed",
+			"message":    "failed to list webhook deliveries",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	result := []gin.H{}
+	for i := range deliveries {
+		result = append(result, deliveryResponse(&deliveries[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": result,
+		"meta": gin.H{
+			"current_page": page,
+			"per_page":     perPage,
+			"total":        total,
+			"total_pages":  (total + perPage - 1) / perPage,
+		},
+		"request_id": requestID,
+	})
+}
+
+// GetDelivery shows a delivery attempt with the exact payload that was
+// signed and the signature sent with it
+func (h *WebhookDeliveryHandler) GetDelivery(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	id, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		invalidDeliveryParameter(c, "delivery ID must be a number")
+		return
+	}
+
+	delivery, err := h.deliveries.Get(id)
+	if err != nil {
+		log.Error().Err(err).Int64("delivery_id", id).Msg("Failed to get webhook delivery")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "deliveries_failed",
+			"message":    "failed to get webhook delivery",
+			"request_id": requestID,
+		})
+		return
+	}
+	if delivery == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":      "delivery_not_found",
+			"message":    "webhook delivery not found",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	response := deliveryResponse(delivery)
+	response["signature"] = delivery.Signature
+	response["headers"] = delivery.Headers
+	if json.Valid([]byte(delivery.Payload)) {
+		response["payload"] = json.RawMessage(delivery.Payload)
+	} else {
+		response["payload"] = delivery.Payload
+	}
+	response["request_id"] = requestID
+	c.JSON(http.StatusOK, response)
+}
+
+func deliveryResponse(d *store.WebhookDelivery) gin.H {
+	return gin.H{
+		"id":                  d.ID,
+		"url":                 d.URL,
+		"delivery_request_id": d.RequestID,
+		"format":              d.Format,
+		"wa_account_ids":      d.WaAccountIDs,
+		"event_types":         d.EventTypes,
+		"batch_size":          d.BatchSize,
+		"status_code":         d.StatusCode,
+		"succeeded":           d.Succeeded,
+		"latency_ms":          d.LatencyMS,
+		"response":            d.Response,
+		"error_message":       d.Error,
+		"attempted_at":        d.AttemptedAt,
+	}
+}
+
+// timeParam parses an optional unix or RFC 3339 timestamp query parameter
+func timeParam(c *gin.Context, name string) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(unix, 0), true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	return t, err == nil
+}
+
+func invalidDeliveryParameter(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      "invalid_parameter",
+		"message":    message,
+		"request_id": c.GetString("request_id"),
+	})
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/auth"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/eventbus"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/middleware"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	wsWriteTimeout   = 10 * time.Second
+	wsCommandTimeout = 30 * time.Second
+	wsOutboundBuffer = 256
+	wsPingInterval   = 30 * time.Second
+	// wsMaxCommands is how many commands of a connection run at once, reading
+	// further commands waits for one of them to finish
+	wsMaxCommands = 8
+)
+
+type WebSocketHandler struct {
+	clientManager  *wa.ClientManager
+	bus            *eventbus.Bus
+	messages       *MessageHandler
+	rateLimiter    *middleware.RateLimiter
+	signingSecret  string
+	originPatterns []string
+}
+
+func NewWebSocketHandler(cm *wa.ClientManager, bus *eventbus.Bus, messages *MessageHandler, rl *middleware.RateLimiter, signingSecret string, originPatterns []string) *WebSocketHandler {
+	return &WebSocketHandler{
+		clientManager:  cm,
+		bus:            bus,
+		messages:       messages,
+		rateLimiter:    rl,
+		signingSecret:  signingSecret,
+		originPatterns: originPatterns,
+	}
+}
+
+type wsCommand struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	WaAccountID string   `json:"wa_account_id"`
+	Chat        string   `json:"chat"`
+	State       string   `json:"state"`
+	MessageIDs  []string `json:"message_ids"`
+	Sender      string   `json:"sender"`
+	// Message carries the same fields as POST /v1/messages
+	Message *SendMessageRequest `json:"message"`
+}
+
+type wsAck struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Success   bool   `json:"success"`
+	MessageID string `json:"message_id,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+type wsEvent struct {
+	Type  string         `json:"type"`
+	Event eventbus.Event `json:"event"`
+}
+
+// Serve upgrades the request to a WebSocket that pushes the events of every
+// account in the caller's token (optionally narrowed with the accounts query
+// parameter) and accepts send_message, chat_presence and mark_read commands.
+func (h *WebSocketHandler) Serve(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	token := c.Query("token")
+	if token == "" {
+		token = auth.BearerToken(c.GetHeader("Authorization"))
+	}
+
+	claims, err := auth.ParseToken(h.signingSecret, token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":      "unauthorized",
+			"message":    err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	accounts := claims.Accounts
+	if requested := c.Query("accounts"); requested != "" {
+		accounts = nil
+		for _, id := range strings.Split(requested, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			if !claims.Allows(id) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "forbidden",
+					"message":    fmt.Sprintf("not authorized for account %s", id),
+					"request_id": requestID,
+				})
+				return
+			}
+			accounts = append(accounts, id)
+		}
+	}
+
+	// The hijacked connection keeps the server's read/write deadlines
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		log.Warn().Err(err).Msg("Failed to clear read deadline for WebSocket")
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn().Err(err).Msg("Failed to clear write deadline for WebSocket")
+	}
+
+	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
+		OriginPatterns: h.originPatterns,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to accept WebSocket connection")
+		return
+	}
+	defer conn.CloseNow()
+
+	// Commands may carry media inline, like the REST request bodies
+	conn.SetReadLimit(h.messages.requestSizeLimit())
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	log.Info().
+		Strs("accounts", accounts).
+		Str("request_id", requestID).
+		Msg("WebSocket connection opened")
+
+	outbound := make(chan interface{}, wsOutboundBuffer)
+
+	for _, waAccountID := range accounts {
+		sub, _, _ := h.bus.Subscribe(waAccountID, nil, 0)
+		defer sub.Close()
+
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case evt, ok := <-sub.Events():
+					if !ok {
+						// Dropped by the bus for falling behind, the client has to reconnect
+						cancel()
+						return
+					}
+					select {
+					case outbound <- wsEvent{Type: "event", Event: evt}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	commands := make(chan struct{}, wsMaxCommands)
+
+	go func() {
+		defer cancel()
+		for {
+			var cmd wsCommand
+			if err := wsjson.Read(ctx, conn, &cmd); err != nil {
+				if websocket.CloseStatus(err) == -1 && ctx.Err() == nil {
+					log.Warn().Err(err).Msg("Failed to read WebSocket command")
+				}
+				return
+			}
+
+			select {
+			case commands <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			// Commands may take a while (media uploads), don't hold up reading
+			go func() {
+				defer func() { <-commands }()
+				ack := h.handleCommand(ctx, claims, cmd)
+				select {
+				case outbound <- ack:
+				case <-ctx.Done():
+				}
+			}()
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ping.C:
+			pingCtx, pingCancel := context.WithTimeout(ctx, wsWriteTimeout)
+			err := conn.Ping(pingCtx)
+			pingCancel()
+			if err != nil {
+				cancel()
+			}
+		case <-ctx.Done():
+			conn.Close(websocket.StatusNormalClosure, "")
+			log.Info().Str("request_id", requestID).Msg("WebSocket connection closed")
+			return
+		case msg := <-outbound:
+			writeCtx, writeCancel := context.WithTimeout(ctx, wsWriteTimeout)
+			err := wsjson.Write(writeCtx, conn, msg)
+			writeCancel()
+			if err != nil {
+				cancel()
+			}
+		}
+	}
+}
+
+func (h *WebSocketHandler) handleCommand(ctx context.Context, claims *auth.Token, cmd wsCommand) wsAck {
+	ack := wsAck{Type: "ack", ID: cmd.ID}
+
+	if cmd.Type == "send_message" && cmd.Message != nil {
+		cmd.WaAccountID = cmd.Message.WaAccountID
+	}
+
+	if !claims.Allows(cmd.WaAccountID) {
+		ack.Error, ack.Message = "forbidden", "not authorized for this account"
+		return ack
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wsCommandTimeout)
+	defer cancel()
+
+	mc, err := h.clientManager.GetOrCreateClient(ctx, cmd.WaAccountID)
+	if err != nil {
+		ack.Error, ack.Message = "client_error", "failed to get client"
+		return ack
+	}
+
+	if !mc.Client.IsConnected() {
+		ack.Error, ack.Message = "not_connected", "account not connected"
+		return ack
+	}
+
+	switch cmd.Type {
+	case "send_message":
+		if cmd.Message == nil {
+			ack.Error, ack.Message = "invalid_request", "message is required"
+			return ack
+		}
+		if !h.rateLimiter.Allow(cmd.WaAccountID) {
+			ack.Error, ack.Message = "rate_limit_exceeded", "Too many messages sent. Please wait before sending more."
+			return ack
+		}
+
+		resp, err := h.messages.sendMessage(ctx, mc, *cmd.Message)
+		if err != nil {
+			apiErr := toAPIError(err)
+			ack.Error, ack.Message = apiErr.Code, apiErr.Message
+			return ack
+		}
+		ack.MessageID = resp.ID
+		ack.Timestamp = resp.Timestamp.Unix()
+
+	case "chat_presence":
+		err := h.messages.sendChatPresence(ctx, mc.Client, SendMessageRequest{
+			ChatPresence: &ChatPresenceInfo{JID: cmd.Chat, State: cmd.State},
+		})
+		if err != nil {
+			ack.Error, ack.Message = "chat_presence_failed", err.Error()
+			return ack
+		}
+
+	case "mark_read":
+		chatJID, err := types.ParseJID(cmd.Chat)
+		if err != nil {
+			ack.Error, ack.Message = "invalid_chat_id", "invalid chat JID"
+			return ack
+		}
+		sender := types.EmptyJID
+		if cmd.Sender != "" {
+			if sender, err = types.ParseJID(cmd.Sender); err != nil {
+				ack.Error, ack.Message = "invalid_sender", "invalid sender JID"
+				return ack
+			}
+		}
+		if err := markRead(ctx, mc.Client, chatJID, cmd.MessageIDs, sender); err != nil {
+			log.Error().Err(err).Msg("Failed to mark chat as read")
+			ack.Error, ack.Message = "mark_read_failed", "failed to mark as read"
+			return ack
+		}
+
+	default:
+		ack.Error, ack.Message = "invalid_command", fmt.Sprintf("unsupported command type %q", cmd.Type)
+		return ack
+	}
+
+	ack.Success = true
+	return ack
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/gin-gonic/gin"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/eventbus"
+)
+
+const testSigningSecret = "test-secret"
+
+// signToken issues a token for accounts the way auth.ParseToken verifies it
+func signToken(t *testing.T, accounts ...string) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"accounts": accounts,
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("marshal token: %v", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte(payload))
+	return payload + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebSocketServer(t *testing.T, bus *eventbus.Bus) *httptest.Server {
+	t.Helper()
+
+	messages := newTestMessageHandler(t, 16)
+	h := NewWebSocketHandler(nil, bus, messages, nil, testSigningSecret, nil)
+
+	r := gin.New()
+	r.GET("/v1/ws", h.Serve)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebSocketAuth(t *testing.T) {
+	srv := newWebSocketServer(t, eventbus.New(16))
+	token := signToken(t, "acc-1")
+
+	tests := []struct {
+		name   string
+		query  string
+		header string
+		status int
+	}{
+		{"no token", "", "", http.StatusUnauthorized},
+		{"bad signature", "?token=" + token + "0", "", http.StatusUnauthorized},
+		{"account outside the token", "?token=" + token + "&accounts=acc-1,acc-2", "", http.StatusForbidden},
+		{"bearer with account outside the token", "?accounts=acc-2", "Bearer " + token, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/ws"+tt.query, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s = %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+	}
+}
+
+func TestWebSocketCommandsAndEvents(t *testing.T) {
+	bus := eventbus.New(16)
+	srv := newWebSocketServer(t, bus)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/ws?token=" + signToken(t, "acc-1")
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(1 << 20)
+
+	// Inline media makes commands larger than the default read limit of 32KiB,
+	// another account's command is refused without closing the connection
+	err = wsjson.Write(ctx, conn, wsCommand{
+		ID:   "cmd-1",
+		Type: "send_message",
+		Message: &SendMessageRequest{
+			WaAccountID: "acc-2",
+			To:          "123",
+			Type:        "image",
+			MediaBase64: strings.Repeat("A", 64<<10),
+		},
+	})
+	if err != nil {
+		t.Fatalf("write command: %v", err)
+	}
+
+	var ack wsAck
+	if err := wsjson.Read(ctx, conn, &ack); err != nil {
+		t.Fatalf("read ack: %v", err)
+	}
+	if ack.Type != "ack" || ack.ID != "cmd-1" || ack.Success || ack.Error != "forbidden" {
+		t.Errorf("ack = %+v, want a forbidden ack of cmd-1", ack)
+	}
+
+	// Subscriptions are made before commands are read, the ack means the
+	// connection is subscribed
+	bus.Publish("acc-2", "message", 1, time.Now(), map[string]string{"id": "other"})
+	bus.Publish("acc-1", "message", 1, time.Now(), map[string]string{"id": "own"})
+
+	var evt struct {
+		Type  string `json:"type"`
+		Event struct {
+			Type        string            `json:"event_type"`
+			WaAccountID string            `json:"wa_account_id"`
+			Data        map[string]string `json:"data"`
+		} `json:"event"`
+	}
+	if err := wsjson.Read(ctx, conn, &evt); err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if evt.Type != "event" || evt.Event.WaAccountID != "acc-1" || evt.Event.Data["id"] != "own" {
+		t.Errorf("event = %+v, want the acc-1 event only", evt)
+	}
+}
@@ -0,0 +1,256 @@
+// Package linkpreview fetches the title, description and image of web pages
+// from their OpenGraph and Twitter card tags, for link previews in messages.
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	fetchTimeout   = 10 * time.Second
+	maxRedirects   = 5
+	maxPageSize    = 1 << 20
+	maxImageSize   = 5 << 20
+	maxTitle       = 256
+	maxDescription = 1024
+	userAgent      = "go-whatsapp-service/1.0 (link preview)"
+)
+
+// urlPattern matches http and https URLs in text
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+// Preview is what a page tells about itself
+type Preview struct {
+	URL         string // canonical URL of the page
+	Title       string
+	Description string
+	ImageURL    string
+	Image       []byte // empty if the page has no image or it couldn't be fetched
+}
+
+// Fetcher fetches link previews with bounded time, redirects and sizes
+type Fetcher struct {
+	httpClient *http.Client
+}
+
+// NewFetcher returns a Fetcher making requests over transport, which should
+// keep them away from internal addresses
+func NewFetcher(transport http.RoundTripper) *Fetcher {
+	return &Fetcher{
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   fetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return checkScheme(req.URL)
+			},
+		},
+	}
+}
+
+// FindURL returns the first URL in text as it appears there, or an empty
+// string. Trailing punctuation is not considered part of the URL.
+func FindURL(text string) string {
+	match := urlPattern.FindString(text)
+	for match != "" {
+		trimmed := strings.TrimRight(match, ".,:;!?'*")
+		// Keep closing parentheses that close one in the URL, like Wikipedia links
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = trimmed[:len(trimmed)-1]
+		}
+		if trimmed == match {
+			break
+		}
+		match = trimmed
+	}
+	if _, err := url.Parse(match); err != nil {
+		return ""
+	}
+	return match
+}
+
+// Fetch loads a page and its preview image. Pages without any title yield an
+// error, a missing or broken image does not.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	pageURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if err := checkScheme(pageURL); err != nil {
+		return nil, err
+	}
+
+	resp, err := f.get(ctx, pageURL.String(), "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxPageSize), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset: %w", err)
+	}
+	tags := parseHead(body)
+
+	preview := &Preview{
+		URL:         first(tags["og:url"], resp.Request.URL.String()),
+		Title:       truncate(first(tags["og:title"], tags["twitter:title"], tags["title"]), maxTitle),
+		Description: truncate(first(tags["og:description"], tags["twitter:description"], tags["description"]), maxDescription),
+	}
+	if preview.Title == "" {
+		return nil, errors.New("page has no title")
+	}
+
+	image := first(tags["og:image:secure_url"], tags["og:image"], tags["og:image:url"], tags["twitter:image"], tags["twitter:image:src"])
+	if imageURL, err := resp.Request.URL.Parse(image); image != "" && err == nil && checkScheme(imageURL) == nil {
+		preview.ImageURL = imageURL.String()
+		preview.Image, _ = f.fetchImage(ctx, preview.ImageURL)
+	}
+
+	return preview, nil
+}
+
+func (f *Fetcher) fetchImage(ctx context.Context, imageURL string) ([]byte, error) {
+	resp, err := f.get(ctx, imageURL, "image/*")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > maxImageSize {
+		return nil, fmt.Errorf("image is too large")
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image is too large")
+	}
+	return data, nil
+}
+
+func (f *Fetcher) get(ctx context.Context, target, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// parseHead collects the meta tags of a page by property or name, and its
+// title as "title". Parsing stops at the body.
+func parseHead(r io.Reader) map[string]string {
+	tags := make(map[string]string)
+	set := func(key, value string) {
+		value = strings.TrimSpace(value)
+		if _, ok := tags[key]; !ok && key != "" && value != "" {
+			tags[key] = value
+		}
+	}
+
+	tokenizer := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return tags
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "body":
+				return tags
+			case "title":
+				inTitle = true
+			case "meta":
+				var key, content string
+				for _, attr := range token.Attr {
+					switch attr.Key {
+					case "property", "name":
+						if key == "" {
+							key = strings.ToLower(strings.TrimSpace(attr.Val))
+						}
+					case "content":
+						content = attr.Val
+					}
+				}
+				// The title tag wins over a meta tag called title
+				if key != "title" {
+					set(key, content)
+				}
+			}
+
+		case html.TextToken:
+			if inTitle {
+				set("title", strings.Join(strings.Fields(string(tokenizer.Text())), " "))
+			}
+
+		case html.EndTagToken:
+			switch tokenizer.Token().Data {
+			case "title":
+				inTitle = false
+			case "head":
+				return tags
+			}
+		}
+	}
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("URL has no host")
+	}
+	return nil
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
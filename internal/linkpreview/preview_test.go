@@ -0,0 +1,113 @@
+package linkpreview
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/whatsapp-api/go-whatsapp-service/internal/media"
+)
+
+// hugePNG is a PNG of a few hundred bytes declaring a 100000x100000 image
+func hugePNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("encoding PNG: %v", err)
+	}
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestFindURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"see https://example.com/page.", "https://example.com/page"},
+		{"(https://en.wikipedia.org/wiki/Go_(game))", "https://en.wikipedia.org/wiki/Go_(game)"},
+		{"first http://a.example, then https://b.example", "http://a.example"},
+		{"HTTPS://EXAMPLE.COM!?", "HTTPS://EXAMPLE.COM"},
+		{"no link here", ""},
+		{"ftp://example.com", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FindURL(tt.text); got != tt.want {
+			t.Errorf("FindURL(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	huge := hugePNG(t)
+	mux := http.NewServeMux()
+	page := func(head string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><head>" + head + "</head><body><title>Not this</title></body></html>"))
+		}
+	}
+	mux.HandleFunc("/article", page(`<title> The  title </title>
+		<meta property="og:description" content="About it">
+		<meta property="og:image" content="/huge.png">`))
+	mux.HandleFunc("/large-image", page(`<meta property="og:title" content="Large"><meta name="twitter:image" content="/large.png">`))
+	mux.HandleFunc("/untitled", page(`<meta property="og:description" content="No title">`))
+	mux.HandleFunc("/huge.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(huge)
+	})
+	mux.HandleFunc("/large.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(make([]byte, maxImageSize+1))
+	})
+	mux.HandleFunc("/file.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := NewFetcher(http.DefaultTransport)
+	ctx := context.Background()
+
+	preview, err := fetcher.Fetch(ctx, server.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if preview.Title != "The title" || preview.Description != "About it" || preview.ImageURL != server.URL+"/huge.png" {
+		t.Errorf("preview = %q %q %q", preview.Title, preview.Description, preview.ImageURL)
+	}
+	// The image is fetched as it is, turning it into thumbnails must not
+	// decode the size it declares
+	if !bytes.Equal(preview.Image, huge) {
+		t.Errorf("preview image has %d bytes, want %d", len(preview.Image), len(huge))
+	}
+	if _, err := media.Thumbnail(preview.Image); !errors.Is(err, media.ErrImageTooLarge) {
+		t.Errorf("Thumbnail of the preview image = %v, want ErrImageTooLarge", err)
+	}
+
+	preview, err = fetcher.Fetch(ctx, server.URL+"/large-image")
+	if err != nil || preview.Title != "Large" || preview.Image != nil {
+		t.Errorf("page with a large image = %+v, %v, want a preview without the image", preview, err)
+	}
+
+	for _, path := range []string{"/untitled", "/file.pdf", "/missing"} {
+		if _, err := fetcher.Fetch(ctx, server.URL+path); err == nil {
+			t.Errorf("Fetch(%s) succeeded", path)
+		}
+	}
+	if _, err := fetcher.Fetch(ctx, "file:///etc/passwd"); err == nil || !strings.Contains(err.Error(), "scheme") {
+		t.Errorf("Fetch of a file URL = %v, want a scheme error", err)
+	}
+}
@@ -0,0 +1,58 @@
+package media
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// requestOverhead is the room left for the other fields of a request that
+// carries media inline
+const requestOverhead = 1 << 20
+
+// RequestSizeLimit is the largest request body accepted with media of up to
+// maxSize inline: its base64 encoding, line breaks included, and the other
+// fields of the request
+func RequestSizeLimit(maxSize int64) int64 {
+	encoded := (maxSize + 2) / 3 * 4
+	return encoded + encoded/38 + requestOverhead
+}
+
+// DecodeBase64 decodes inline media, either plain base64 or a data URL such as
+// "data:image/png;base64,iVBOR...". The MIME type is only known for data URLs.
+func DecodeBase64(value string, maxSize int64) ([]byte, string, error) {
+	mime := ""
+	if rest, ok := strings.CutPrefix(value, "data:"); ok {
+		header, payload, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(header, ";base64") {
+			return nil, "", fmt.Errorf("data URL must be base64 encoded")
+		}
+		mime = strings.TrimSuffix(header, ";base64")
+		if params := strings.Index(mime, ";"); params >= 0 {
+			mime = mime[:params]
+		}
+		value = payload
+	}
+
+	value = strings.Join(strings.Fields(value), "")
+	if value == "" {
+		return nil, "", fmt.Errorf("media is empty")
+	}
+	if int64(base64.StdEncoding.DecodedLen(len(value))) > maxSize+2 {
+		return nil, "", ErrTooLarge
+	}
+
+	encoding := base64.StdEncoding
+	if !strings.HasSuffix(value, "=") && len(value)%4 != 0 {
+		encoding = base64.RawStdEncoding
+	}
+	data, err := encoding.DecodeString(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid base64 media: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", ErrTooLarge
+	}
+
+	return data, mime, nil
+}
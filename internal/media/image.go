@@ -0,0 +1,81 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.Decode
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	thumbnailSize    = 72
+	thumbnailQuality = 75
+
+	// maxImagePixels bounds the images decoded, a small file can declare a
+	// huge image that takes gigabytes to decode
+	maxImagePixels = 64 << 20
+)
+
+// ErrImageTooLarge is returned for images declaring more than maxImagePixels
+var ErrImageTooLarge = errors.New("image is too large to decode")
+
+func imageSize(data []byte) (uint32, uint32, bool) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return 0, 0, false
+	}
+	return uint32(config.Width), uint32(config.Height), true
+}
+
+// Thumbnail scales an image down to fit a thumbnailSize square and encodes it
+// as JPEG, transparent areas become white
+func Thumbnail(data []byte) ([]byte, error) {
+	thumbnail, _, _, err := ScaleJPEG(data, thumbnailSize)
+	return thumbnail, err
+}
+
+// ScaleJPEG scales an image down to fit a square of maxSide pixels and
+// encodes it as JPEG with its new size, transparent areas become white
+func ScaleJPEG(data []byte, maxSide int) ([]byte, uint32, uint32, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, 0, 0, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return nil, 0, 0, fmt.Errorf("image is empty")
+	}
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			width, height = maxSide, max(1, height*maxSide/width)
+		} else {
+			width, height = max(1, width*maxSide/height), maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), uint32(width), uint32(height), nil
+}
@@ -0,0 +1,101 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t testing.TB, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encoding PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withPNGSize rewrites the size in the IHDR chunk of a PNG, the image data
+// stays that of the original size
+func withPNGSize(data []byte, width, height uint32) []byte {
+	data = bytes.Clone(data)
+	// Signature (8), chunk length (4), "IHDR" (4), then width and height
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestScaleJPEG(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		maxSide               int
+		wantWidth, wantHeight uint32
+	}{
+		{"landscape", 200, 100, 72, 72, 36},
+		{"portrait", 100, 200, 72, 36, 72},
+		{"smaller than the box", 40, 30, 72, 40, 30},
+		{"thin", 1000, 1, 72, 72, 1},
+	}
+
+	for _, tt := range tests {
+		data, width, height, err := ScaleJPEG(testPNG(t, tt.width, tt.height), tt.maxSide)
+		if err != nil {
+			t.Errorf("%s: ScaleJPEG: %v", tt.name, err)
+			continue
+		}
+		if width != tt.wantWidth || height != tt.wantHeight {
+			t.Errorf("%s: size %dx%d, want %dx%d", tt.name, width, height, tt.wantWidth, tt.wantHeight)
+		}
+		config, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || format != "jpeg" || uint32(config.Width) != width || uint32(config.Height) != height {
+			t.Errorf("%s: result is %s %dx%d (%v), want a %dx%d JPEG", tt.name, format, config.Width, config.Height, err, width, height)
+		}
+	}
+}
+
+func TestScaleJPEGRejectsHugeImages(t *testing.T) {
+	// A few hundred bytes declaring a 100000x100000 image
+	data := withPNGSize(testPNG(t, 2, 2), 100000, 100000)
+
+	if _, _, _, err := ScaleJPEG(data, thumbnailSize); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("ScaleJPEG = %v, want ErrImageTooLarge", err)
+	}
+	if _, err := Thumbnail(data); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Thumbnail = %v, want ErrImageTooLarge", err)
+	}
+
+	info := Inspect(data, "image/png")
+	if info.Width != 100000 || info.Height != 100000 || info.Thumbnail != nil {
+		t.Errorf("Inspect = %dx%d with %d byte thumbnail, want the declared size without a thumbnail",
+			info.Width, info.Height, len(info.Thumbnail))
+	}
+}
+
+func TestScaleJPEGRejectsInvalidImages(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("not an image"), testPNG(t, 4, 4)[:40]} {
+		if _, _, _, err := ScaleJPEG(data, thumbnailSize); err == nil {
+			t.Errorf("ScaleJPEG(%q) succeeded", data)
+		}
+	}
+}
+
+func FuzzScaleJPEG(f *testing.F) {
+	f.Add(testPNG(f, 3, 2))
+	f.Add(withPNGSize(testPNG(f, 2, 2), 1<<20, 1<<20))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ScaleJPEG(data, thumbnailSize)
+	})
+}
@@ -0,0 +1,77 @@
+package media
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Info is what inspecting media revealed, zero values are unknown
+type Info struct {
+	Mime      string
+	Width     uint32
+	Height    uint32
+	Seconds   uint32
+	PageCount uint32
+	Thumbnail []byte // JPEG
+	Waveform  []byte // WaveformSamples levels from 0 to 100, Opus only
+}
+
+// Inspect sniffs the content type of media and extracts what recipients need
+// to render a preview: image dimensions and a JPEG thumbnail, MP4 duration
+// and dimensions, PDF page count, Opus duration and waveform. The declared
+// MIME type is kept unless it's missing or generic, Ogg files are always
+// identified by their content. Inspection is best effort, unreadable media
+// simply yields less information.
+func Inspect(data []byte, declaredMime string) Info {
+	info := Info{Mime: detectMime(data, declaredMime)}
+
+	switch {
+	case isOgg(data):
+		info.Mime = "audio/ogg"
+		if stream, err := parseOggOpus(data); err == nil {
+			info.Mime = OpusMime
+			info.Seconds = seconds(stream.duration)
+			info.Waveform = stream.waveform
+		}
+	case strings.HasPrefix(info.Mime, "audio/ogg"):
+		// Declared as Ogg but it isn't
+		info.Mime = detectMime(data, "")
+	case strings.HasPrefix(info.Mime, "image/"):
+		if width, height, ok := imageSize(data); ok {
+			info.Width, info.Height = width, height
+			info.Thumbnail, _ = Thumbnail(data)
+		}
+	case info.Mime == "video/mp4" || info.Mime == "video/quicktime" || info.Mime == "audio/mp4":
+		if movie, err := parseMP4(data); err == nil {
+			info.Width, info.Height = movie.width, movie.height
+			info.Seconds = seconds(movie.duration)
+		}
+	case info.Mime == "application/pdf":
+		info.PageCount = pdfPageCount(data)
+	}
+
+	return info
+}
+
+// detectMime returns the declared MIME type, or the sniffed one if nothing
+// specific was declared
+func detectMime(data []byte, declared string) string {
+	declared = strings.TrimSpace(declared)
+	if declared != "" && declared != "application/octet-stream" {
+		return declared
+	}
+
+	sniffed := http.DetectContentType(data)
+	switch {
+	case isMP4(data):
+		// DetectContentType only knows a few MP4 brands
+		switch string(data[8:12]) {
+		case "M4A ", "M4B ", "F4A ":
+			return "audio/mp4"
+		}
+		return "video/mp4"
+	case strings.HasPrefix(sniffed, "text/plain"):
+		return "text/plain"
+	}
+	return sniffed
+}
@@ -0,0 +1,56 @@
+package media
+
+import "testing"
+
+func TestDetectMime(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		declared string
+		want     string
+	}{
+		{"declared kept", []byte("\x89PNG\r\n\x1a\n"), "image/x-custom", "image/x-custom"},
+		{"generic declared is sniffed", []byte("\x89PNG\r\n\x1a\n"), "application/octet-stream", "image/png"},
+		{"sniffed PNG", []byte("\x89PNG\r\n\x1a\n"), "", "image/png"},
+		{"MP4 video", box("ftyp", []byte("isom\x00\x00\x02\x00")), "", "video/mp4"},
+		{"MP4 audio", box("ftyp", []byte("M4A \x00\x00\x02\x00")), "", "audio/mp4"},
+		{"plain text", []byte("hello"), " ", "text/plain"},
+		{"PDF", []byte("%PDF-1.7\n"), "", "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		if got := detectMime(tt.data, tt.declared); got != tt.want {
+			t.Errorf("%s: detectMime = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		declared      string
+		wantMime      string
+		wantWidth     uint32
+		wantHeight    uint32
+		wantSeconds   uint32
+		wantPages     uint32
+		wantThumbnail bool
+	}{
+		{name: "image", data: testPNG(t, 120, 60), wantMime: "image/png", wantWidth: 120, wantHeight: 60, wantThumbnail: true},
+		{name: "video", data: testMP4(mvhdV0(1000, 4200), box("trak", tkhd(320, 240))), wantMime: "video/mp4",
+			wantWidth: 320, wantHeight: 240, wantSeconds: 4},
+		{name: "document", data: testPDF("<< /Type /Page >>", "<< /Type /Page >>"), wantMime: "application/pdf", wantPages: 2},
+		{name: "unreadable image", data: []byte("\x89PNG\r\n\x1a\nbroken"), wantMime: "image/png"},
+		{name: "fake Ogg", data: []byte("hello"), declared: "audio/ogg", wantMime: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		info := Inspect(tt.data, tt.declared)
+		if info.Mime != tt.wantMime || info.Width != tt.wantWidth || info.Height != tt.wantHeight ||
+			info.Seconds != tt.wantSeconds || info.PageCount != tt.wantPages || (info.Thumbnail != nil) != tt.wantThumbnail {
+			t.Errorf("%s: Inspect = %s %dx%d %ds %d pages, thumbnail %v", tt.name, info.Mime, info.Width, info.Height,
+				info.Seconds, info.PageCount, info.Thumbnail != nil)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package media
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// mp4Movie is what the movie and track headers of an MP4 file tell
+type mp4Movie struct {
+	duration time.Duration
+	width    uint32
+	height   uint32
+}
+
+// isMP4 reports whether data starts with an ISO base media file type box
+func isMP4(data []byte) bool {
+	return len(data) >= 12 && string(data[4:8]) == "ftyp"
+}
+
+// parseMP4 reads the duration from the movie header and the dimensions from
+// the first track header with a size, which is the video track
+func parseMP4(data []byte) (mp4Movie, error) {
+	var movie mp4Movie
+
+	moov, ok := findBox(data, "moov")
+	if !ok {
+		return movie, fmt.Errorf("no moov box")
+	}
+
+	mvhd, ok := findBox(moov, "mvhd")
+	if !ok {
+		return movie, fmt.Errorf("no mvhd box")
+	}
+	duration, err := mvhdDuration(mvhd)
+	if err != nil {
+		return movie, err
+	}
+	movie.duration = duration
+
+	walkBoxes(moov, func(kind string, trak []byte) bool {
+		if kind != "trak" {
+			return true
+		}
+		if tkhd, ok := findBox(trak, "tkhd"); ok {
+			if width, height, ok := tkhdSize(tkhd); ok {
+				movie.width, movie.height = width, height
+				return false
+			}
+		}
+		return true
+	})
+
+	return movie, nil
+}
+
+func mvhdDuration(mvhd []byte) (time.Duration, error) {
+	if len(mvhd) < 4 {
+		return 0, fmt.Errorf("mvhd box too short")
+	}
+
+	var timescale uint32
+	var duration uint64
+	switch mvhd[0] {
+	case 0:
+		if len(mvhd) < 20 {
+			return 0, fmt.Errorf("mvhd box too short")
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[12:])
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:]))
+	case 1:
+		if len(mvhd) < 32 {
+			return 0, fmt.Errorf("mvhd box too short")
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[20:])
+		duration = binary.BigEndian.Uint64(mvhd[24:])
+	default:
+		return 0, fmt.Errorf("unknown mvhd version %d", mvhd[0])
+	}
+	if timescale == 0 {
+		return 0, fmt.Errorf("mvhd timescale is zero")
+	}
+
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// tkhdSize returns the presentation size of a track, audio tracks have none
+func tkhdSize(tkhd []byte) (uint32, uint32, bool) {
+	if len(tkhd) < 4 {
+		return 0, 0, false
+	}
+
+	// width and height are 16.16 fixed point, after the matrix
+	offset := 76
+	if tkhd[0] == 1 {
+		offset = 88
+	}
+	if len(tkhd) < offset+8 {
+		return 0, 0, false
+	}
+
+	width := binary.BigEndian.Uint32(tkhd[offset:]) >> 16
+	height := binary.BigEndian.Uint32(tkhd[offset+4:]) >> 16
+	return width, height, width > 0 && height > 0
+}
+
+// findBox returns the payload of the first box of a kind directly in data
+func findBox(data []byte, kind string) ([]byte, bool) {
+	var found []byte
+	walkBoxes(data, func(k string, payload []byte) bool {
+		if k == kind {
+			found = payload
+			return false
+		}
+		return true
+	})
+	return found, found != nil
+}
+
+// walkBoxes calls fn with the type and payload of every box directly in data
+// until fn returns false
+func walkBoxes(data []byte, fn func(kind string, payload []byte) bool) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		kind := string(data[4:8])
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			// the box extends to the end of the file
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return
+		}
+
+		if !fn(kind, data[header:size]) {
+			return
+		}
+		data = data[size:]
+	}
+}
+
+// seconds rounds a duration to whole seconds, anything playable lasts at least one
+func seconds(d time.Duration) uint32 {
+	if d <= 0 {
+		return 0
+	}
+	return uint32(max(1, d.Round(time.Second)/time.Second))
+}
@@ -0,0 +1,144 @@
+package media
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// box builds an MP4 box of a kind holding the concatenated payloads
+func box(kind string, payloads ...[]byte) []byte {
+	size := 8
+	for _, p := range payloads {
+		size += len(p)
+	}
+	data := binary.BigEndian.AppendUint32(nil, uint32(size))
+	data = append(data, kind...)
+	for _, p := range payloads {
+		data = append(data, p...)
+	}
+	return data
+}
+
+func mvhdV0(timescale, duration uint32) []byte {
+	payload := make([]byte, 20)
+	binary.BigEndian.PutUint32(payload[12:], timescale)
+	binary.BigEndian.PutUint32(payload[16:], duration)
+	return box("mvhd", payload)
+}
+
+func mvhdV1(timescale uint32, duration uint64) []byte {
+	payload := make([]byte, 32)
+	payload[0] = 1
+	binary.BigEndian.PutUint32(payload[20:], timescale)
+	binary.BigEndian.PutUint64(payload[24:], duration)
+	return box("mvhd", payload)
+}
+
+func tkhd(width, height uint32) []byte {
+	payload := make([]byte, 84)
+	binary.BigEndian.PutUint32(payload[76:], width<<16)
+	binary.BigEndian.PutUint32(payload[80:], height<<16)
+	return box("tkhd", payload)
+}
+
+func testMP4(moov ...[]byte) []byte {
+	ftyp := box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	return append(append(ftyp, box("moov", moov...)...), box("mdat", []byte("data"))...)
+}
+
+func TestParseMP4(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErr      bool
+		wantDuration time.Duration
+		wantWidth    uint32
+		wantHeight   uint32
+	}{
+		{
+			name:         "audio and video track",
+			data:         testMP4(mvhdV0(1000, 12500), box("trak", tkhd(0, 0)), box("trak", tkhd(1280, 720))),
+			wantDuration: 12500 * time.Millisecond,
+			wantWidth:    1280,
+			wantHeight:   720,
+		},
+		{
+			name:         "version 1 movie header",
+			data:         testMP4(mvhdV1(600, 600*90), box("trak", tkhd(640, 480))),
+			wantDuration: 90 * time.Second,
+			wantWidth:    640,
+			wantHeight:   480,
+		},
+		{name: "audio only", data: testMP4(mvhdV0(44100, 44100*3)), wantDuration: 3 * time.Second},
+		{name: "no moov", data: box("ftyp", []byte("isom")), wantErr: true},
+		{name: "no mvhd", data: testMP4(box("trak", tkhd(10, 10))), wantErr: true},
+		{name: "zero timescale", data: testMP4(mvhdV0(0, 100)), wantErr: true},
+		{name: "short mvhd", data: testMP4(box("mvhd", make([]byte, 8))), wantErr: true},
+		{name: "box larger than the file", data: testMP4(mvhdV0(1000, 1000))[:40], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		movie, err := parseMP4(tt.data)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: parseMP4 succeeded", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: parseMP4: %v", tt.name, err)
+			continue
+		}
+		if movie.duration != tt.wantDuration || movie.width != tt.wantWidth || movie.height != tt.wantHeight {
+			t.Errorf("%s: got %v %dx%d, want %v %dx%d", tt.name, movie.duration, movie.width, movie.height,
+				tt.wantDuration, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
+
+func TestWalkBoxesLargeSize(t *testing.T) {
+	// A 64-bit size field, followed by a box claiming to extend to the end
+	large := binary.BigEndian.AppendUint32(nil, 1)
+	large = append(large, "free"...)
+	large = binary.BigEndian.AppendUint64(large, 20)
+	large = append(large, "abcd"...)
+	data := append(large, 0, 0, 0, 0, 'm', 'd', 'a', 't', 'x')
+
+	var kinds []string
+	walkBoxes(data, func(kind string, payload []byte) bool {
+		kinds = append(kinds, kind+":"+string(payload))
+		return true
+	})
+	if len(kinds) != 2 || kinds[0] != "free:abcd" || kinds[1] != "mdat:x" {
+		t.Errorf("walkBoxes visited %q", kinds)
+	}
+}
+
+func TestSeconds(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want uint32
+	}{
+		{0, 0},
+		{-time.Second, 0},
+		{100 * time.Millisecond, 1},
+		{1499 * time.Millisecond, 1},
+		{1500 * time.Millisecond, 2},
+		{time.Hour, 3600},
+	}
+
+	for _, tt := range tests {
+		if got := seconds(tt.d); got != tt.want {
+			t.Errorf("seconds(%v) = %d, want %d", tt.d, got, tt.want)
+		}
+	}
+}
+
+func FuzzParseMP4(f *testing.F) {
+	f.Add(testMP4(mvhdV0(1000, 12500), box("trak", tkhd(1280, 720))))
+	f.Add(testMP4(mvhdV1(600, 1<<40)))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		parseMP4(data)
+	})
+}
@@ -0,0 +1,226 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const (
+	// OpusMime is the MIME type WhatsApp requires for voice notes
+	OpusMime = "audio/ogg; codecs=opus"
+
+	// WaveformSamples is the number of bars of a voice note waveform
+	WaveformSamples = 64
+
+	opusSampleRate = 48000
+	oggHeaderSize  = 27
+)
+
+var oggCapture = []byte("OggS")
+
+// opusStream is what the Ogg container of an Opus stream tells
+type opusStream struct {
+	duration time.Duration
+	waveform []byte
+}
+
+// opusPacket is the size and length of an audio packet
+type opusPacket struct {
+	size    int
+	samples int
+}
+
+// isOgg reports whether data starts with an Ogg page
+func isOgg(data []byte) bool {
+	return bytes.HasPrefix(data, oggCapture)
+}
+
+// parseOggOpus validates the first logical stream of an Ogg file as Opus and
+// computes its duration and waveform, without decoding the audio
+func parseOggOpus(data []byte) (opusStream, error) {
+	var (
+		stream   opusStream
+		serial   uint32
+		found    bool
+		preSkip  int64
+		granule  int64 = -1
+		packet   []byte
+		packets  []opusPacket
+		received int
+	)
+
+	for len(data) > 0 {
+		if len(data) < oggHeaderSize || !bytes.HasPrefix(data, oggCapture) {
+			return stream, fmt.Errorf("invalid ogg page")
+		}
+		if data[4] != 0 {
+			return stream, fmt.Errorf("unsupported ogg version %d", data[4])
+		}
+
+		segments := int(data[26])
+		if len(data) < oggHeaderSize+segments {
+			return stream, fmt.Errorf("truncated ogg page")
+		}
+		lacing := data[oggHeaderSize : oggHeaderSize+segments]
+		bodySize := 0
+		for _, l := range lacing {
+			bodySize += int(l)
+		}
+		pageSize := oggHeaderSize + segments + bodySize
+		if len(data) < pageSize {
+			return stream, fmt.Errorf("truncated ogg page")
+		}
+
+		pageSerial := binary.LittleEndian.Uint32(data[14:])
+		if !found {
+			serial, found = pageSerial, true
+		}
+		if pageSerial != serial {
+			// Other logical streams are ignored
+			data = data[pageSize:]
+			continue
+		}
+		if pageGranule := int64(binary.LittleEndian.Uint64(data[6:])); pageGranule >= 0 {
+			granule = pageGranule
+		}
+
+		body := data[oggHeaderSize+segments : pageSize]
+		for _, l := range lacing {
+			packet = append(packet, body[:l]...)
+			body = body[l:]
+			if l == 255 {
+				// The packet continues in the next segment
+				continue
+			}
+
+			switch received {
+			case 0:
+				skip, err := parseOpusHead(packet)
+				if err != nil {
+					return stream, err
+				}
+				preSkip = skip
+			case 1:
+				if !bytes.HasPrefix(packet, []byte("OpusTags")) {
+					return stream, fmt.Errorf("missing OpusTags header")
+				}
+			default:
+				if len(packet) > 0 {
+					packets = append(packets, opusPacket{size: len(packet), samples: opusPacketSamples(packet)})
+				}
+			}
+			received++
+			packet = packet[:0]
+		}
+
+		data = data[pageSize:]
+	}
+
+	if received < 2 {
+		return stream, fmt.Errorf("missing Opus headers")
+	}
+	if len(packets) == 0 {
+		return stream, fmt.Errorf("no Opus audio")
+	}
+
+	total := 0
+	for _, p := range packets {
+		total += p.samples
+	}
+	samples := int64(total) - preSkip
+	if granule > preSkip {
+		samples = granule - preSkip
+	}
+	stream.duration = time.Duration(samples) * time.Second / opusSampleRate
+	stream.waveform = opusWaveform(packets, total)
+
+	return stream, nil
+}
+
+// parseOpusHead validates the identification header and returns the samples
+// to skip at the start of the stream
+func parseOpusHead(packet []byte) (int64, error) {
+	if len(packet) < 19 || !bytes.HasPrefix(packet, []byte("OpusHead")) {
+		return 0, fmt.Errorf("not an Opus stream")
+	}
+	if version := packet[8]; version>>4 != 0 {
+		return 0, fmt.Errorf("unsupported Opus version %d", version)
+	}
+	if packet[9] == 0 {
+		return 0, fmt.Errorf("Opus stream has no channels")
+	}
+	return int64(binary.LittleEndian.Uint16(packet[10:])), nil
+}
+
+// opusPacketSamples returns the length of a packet at 48 kHz from its TOC byte
+func opusPacketSamples(packet []byte) int {
+	toc := packet[0]
+	config := int(toc >> 3)
+
+	var frame int // in units of 2.5ms, 120 samples
+	switch {
+	case config < 12: // SILK: 10, 20, 40, 60ms
+		frame = []int{4, 8, 16, 24}[config%4]
+	case config < 16: // Hybrid: 10, 20ms
+		frame = []int{4, 8}[config%2]
+	default: // CELT: 2.5, 5, 10, 20ms
+		frame = []int{1, 2, 4, 8}[config%4]
+	}
+
+	frames := 1
+	switch toc & 0x3 {
+	case 1, 2:
+		frames = 2
+	case 3:
+		if len(packet) > 1 {
+			frames = int(packet[1] & 0x3f)
+		}
+	}
+
+	return frame * 120 * frames
+}
+
+// opusWaveform approximates the loudness over time from the bitrate of the
+// packets: Opus spends few bits on silence and many on loud, busy audio.
+// Decoding the audio for real amplitudes would need libopus.
+func opusWaveform(packets []opusPacket, total int) []byte {
+	waveform := make([]byte, WaveformSamples)
+	if total <= 0 {
+		return waveform
+	}
+
+	var sums [WaveformSamples]float64
+	var counts [WaveformSamples]int
+	position := 0
+	for _, p := range packets {
+		if p.samples <= 0 {
+			continue
+		}
+		rate := float64(p.size) / float64(p.samples)
+		first := position * WaveformSamples / total
+		last := (position + p.samples - 1) * WaveformSamples / total
+		for i := first; i <= last && i < WaveformSamples; i++ {
+			sums[i] += rate
+			counts[i]++
+		}
+		position += p.samples
+	}
+
+	peak := 0.0
+	var levels [WaveformSamples]float64
+	for i := range levels {
+		if counts[i] > 0 {
+			levels[i] = sums[i] / float64(counts[i])
+			peak = max(peak, levels[i])
+		}
+	}
+	if peak == 0 {
+		return waveform
+	}
+	for i, level := range levels {
+		waveform[i] = byte(level / peak * 100)
+	}
+	return waveform
+}
@@ -0,0 +1,208 @@
+package media
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// oggPage builds an Ogg page of a logical stream holding whole packets
+func oggPage(serial uint32, granule int64, packets ...[]byte) []byte {
+	var lacing, body []byte
+	for _, packet := range packets {
+		n := len(packet)
+		for n >= 255 {
+			lacing = append(lacing, 255)
+			n -= 255
+		}
+		lacing = append(lacing, byte(n))
+		body = append(body, packet...)
+	}
+
+	page := append([]byte("OggS"), 0, 0)
+	page = binary.LittleEndian.AppendUint64(page, uint64(granule))
+	page = binary.LittleEndian.AppendUint32(page, serial)
+	page = binary.LittleEndian.AppendUint32(page, 0) // sequence number
+	page = binary.LittleEndian.AppendUint32(page, 0) // checksum, not verified
+	page = append(page, byte(len(lacing)))
+	page = append(page, lacing...)
+	return append(page, body...)
+}
+
+func opusHead(preSkip uint16) []byte {
+	head := append([]byte("OpusHead"), 1, 1)
+	head = binary.LittleEndian.AppendUint16(head, preSkip)
+	head = binary.LittleEndian.AppendUint32(head, opusSampleRate)
+	return append(head, 0, 0, 0)
+}
+
+// opusFrame is a 20ms CELT packet of size bytes
+func opusFrame(size int) []byte {
+	packet := make([]byte, size)
+	packet[0] = 31 << 3
+	return packet
+}
+
+// testOpus builds a voice note of one 20ms packet per size. The audio page
+// has the granule position of all its samples, or none if granule is false.
+func testOpus(preSkip uint16, granule bool, sizes ...int) []byte {
+	data := oggPage(1, 0, opusHead(preSkip))
+	data = append(data, oggPage(1, 0, []byte("OpusTags"))...)
+
+	var packets [][]byte
+	for _, size := range sizes {
+		packets = append(packets, opusFrame(size))
+	}
+	position := int64(len(sizes)) * 960
+	if !granule {
+		position = -1
+	}
+	return append(data, oggPage(1, position, packets...)...)
+}
+
+func repeatSize(size, count int) []int {
+	sizes := make([]int, count)
+	for i := range sizes {
+		sizes[i] = size
+	}
+	return sizes
+}
+
+func TestParseOggOpus(t *testing.T) {
+	otherStream := append(oggPage(1, 0, opusHead(0)), oggPage(2, 0, []byte("\x01vorbis"))...)
+	otherStream = append(otherStream, oggPage(1, 0, []byte("OpusTags"))...)
+	otherStream = append(otherStream, oggPage(1, 48000, [][]byte{opusFrame(20), opusFrame(20)}...)...)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErr      bool
+		wantDuration time.Duration
+	}{
+		{"one second", testOpus(0, true, repeatSize(10, 50)...), false, time.Second},
+		{"pre-skip", testOpus(480, true, repeatSize(10, 100)...), false, 1990 * time.Millisecond},
+		{"no granule position", testOpus(480, false, repeatSize(10, 100)...), false, 1990 * time.Millisecond},
+		{"packets over 255 bytes", testOpus(0, true, 600, 255, 10), false, 60 * time.Millisecond},
+		{"other logical stream", otherStream, false, time.Second},
+		{"not Ogg", []byte("RIFF\x00\x00\x00\x00WAVE"), true, 0},
+		{"unsupported version", append([]byte("OggS\x01"), make([]byte, 30)...), true, 0},
+		{"no audio", testOpus(0, true), true, 0},
+		{"Vorbis", append(oggPage(1, 0, []byte("\x01vorbis0123456789abc")), oggPage(1, 0, []byte("\x03vorbis"))...), true, 0},
+		{"missing tags", append(oggPage(1, 0, opusHead(0)), oggPage(1, 0, opusFrame(10))...), true, 0},
+		{"truncated page", testOpus(0, true, 10, 10)[:60], true, 0},
+	}
+
+	for _, tt := range tests {
+		stream, err := parseOggOpus(tt.data)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: parseOggOpus succeeded", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: parseOggOpus: %v", tt.name, err)
+			continue
+		}
+		if stream.duration != tt.wantDuration {
+			t.Errorf("%s: duration %v, want %v", tt.name, stream.duration, tt.wantDuration)
+		}
+		if len(stream.waveform) != WaveformSamples {
+			t.Errorf("%s: waveform has %d samples, want %d", tt.name, len(stream.waveform), WaveformSamples)
+		}
+	}
+}
+
+func TestParseOpusHead(t *testing.T) {
+	tests := []struct {
+		name    string
+		packet  []byte
+		want    int64
+		wantErr bool
+	}{
+		{"valid", opusHead(312), 312, false},
+		{"short", opusHead(0)[:18], 0, true},
+		{"wrong magic", append([]byte("OpusTags"), opusHead(0)[8:]...), 0, true},
+		{"unsupported version", append(append([]byte("OpusHead"), 0x10), opusHead(0)[9:]...), 0, true},
+		{"no channels", append(append([]byte("OpusHead"), 1, 0), opusHead(0)[10:]...), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseOpusHead(tt.packet)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: parseOpusHead = %d, %v, want %d, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestOpusPacketSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet []byte
+		want   int
+	}{
+		{"SILK 10ms", []byte{0 << 3}, 480},
+		{"SILK 60ms", []byte{3 << 3}, 2880},
+		{"Hybrid 20ms", []byte{13 << 3}, 960},
+		{"CELT 2.5ms", []byte{16 << 3}, 120},
+		{"CELT 20ms", []byte{31 << 3}, 960},
+		{"two equal frames", []byte{31<<3 | 1}, 1920},
+		{"two frames", []byte{31<<3 | 2}, 1920},
+		{"counted frames", []byte{16<<3 | 3, 5}, 600},
+		{"count missing", []byte{16<<3 | 3}, 120},
+	}
+
+	for _, tt := range tests {
+		if got := opusPacketSamples(tt.packet); got != tt.want {
+			t.Errorf("%s: opusPacketSamples = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOpusWaveform(t *testing.T) {
+	// Quiet first half, loud second half
+	var packets []opusPacket
+	for i := range 100 {
+		size := 10
+		if i >= 50 {
+			size = 40
+		}
+		packets = append(packets, opusPacket{size: size, samples: 960})
+	}
+
+	waveform := opusWaveform(packets, 100*960)
+	if len(waveform) != WaveformSamples {
+		t.Fatalf("waveform has %d samples, want %d", len(waveform), WaveformSamples)
+	}
+	if waveform[0] != 25 || waveform[WaveformSamples-1] != 100 {
+		t.Errorf("waveform goes from %d to %d, want 25 to 100", waveform[0], waveform[WaveformSamples-1])
+	}
+
+	for _, level := range opusWaveform(nil, 0) {
+		if level != 0 {
+			t.Fatalf("waveform of nothing has level %d", level)
+		}
+	}
+}
+
+func TestInspectOpus(t *testing.T) {
+	info := Inspect(testOpus(0, true, repeatSize(10, 150)...), "audio/mpeg")
+	if info.Mime != OpusMime || info.Seconds != 3 || len(info.Waveform) != WaveformSamples {
+		t.Errorf("Inspect = %s %ds with %d waveform samples, want %s 3s with %d", info.Mime, info.Seconds,
+			len(info.Waveform), OpusMime, WaveformSamples)
+	}
+
+	// Ogg that isn't Opus keeps the generic type and has no waveform
+	info = Inspect(append(oggPage(1, 0, []byte("\x01vorbis")), oggPage(1, 0, []byte("\x03vorbis"))...), "")
+	if info.Mime != "audio/ogg" || info.Waveform != nil {
+		t.Errorf("Inspect of Vorbis = %s with %d waveform samples, want audio/ogg without", info.Mime, len(info.Waveform))
+	}
+}
+
+func FuzzParseOggOpus(f *testing.F) {
+	f.Add(testOpus(312, true, 10, 20, 30))
+	f.Add(testOpus(0, false, 600))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		parseOggOpus(data)
+	})
+}
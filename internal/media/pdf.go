@@ -0,0 +1,93 @@
+package media
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+)
+
+// maxObjectStreamSize bounds what is inflated per compressed object stream
+const maxObjectStreamSize = 8 << 20
+
+var (
+	// pdfPagePattern matches page objects, \b keeps /Pages from matching
+	pdfPagePattern = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfObjStmTag   = []byte("/ObjStm")
+)
+
+// pdfPageCount counts the page objects of a PDF, including those in
+// compressed object streams. It returns 0 if it can't find any.
+func pdfPageCount(data []byte) uint32 {
+	count := 0
+
+	// Object streams are searched once inflated; their raw bytes are skipped
+	// so objects kept in stored deflate blocks aren't counted twice
+	var streams [][2]int
+	for offset := 0; ; {
+		i := bytes.Index(data[offset:], pdfObjStmTag)
+		if i < 0 {
+			break
+		}
+		offset += i + len(pdfObjStmTag)
+
+		start, end, ok := pdfStream(data, offset)
+		if !ok {
+			continue
+		}
+		streams = append(streams, [2]int{start, end})
+		offset = end
+
+		reader, err := zlib.NewReader(bytes.NewReader(data[start:end]))
+		if err != nil {
+			continue
+		}
+		// A truncated stream still yields the objects before the damage
+		objects, _ := io.ReadAll(io.LimitReader(reader, maxObjectStreamSize))
+		reader.Close()
+		count += len(pdfPagePattern.FindAllIndex(objects, -1))
+	}
+
+	for _, match := range pdfPagePattern.FindAllIndex(data, -1) {
+		inStream := false
+		for _, stream := range streams {
+			if match[0] >= stream[0] && match[0] < stream[1] {
+				inStream = true
+				break
+			}
+		}
+		if !inStream {
+			count++
+		}
+	}
+
+	return uint32(count)
+}
+
+// pdfStream returns the bounds of the stream data following the object
+// dictionary at offset
+func pdfStream(data []byte, offset int) (int, int, bool) {
+	rest := data[offset:]
+	i := bytes.Index(rest, []byte("stream"))
+	if i < 0 {
+		return 0, 0, false
+	}
+	// Don't wander into the stream of a later object
+	if end := bytes.Index(rest, []byte("endobj")); end >= 0 && end < i {
+		return 0, 0, false
+	}
+
+	start := offset + i + len("stream")
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		return 0, 0, false
+	}
+	return start, start + end, true
+}
@@ -0,0 +1,83 @@
+package media
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	for i, object := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+// objectStream compresses objects into an object stream, dropping the last
+// cut bytes of the compressed data
+func objectStream(objects string, cut int) string {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	w.Write([]byte(objects))
+	w.Close()
+	data := compressed.Bytes()[:compressed.Len()-cut]
+	return fmt.Sprintf("<< /Type /ObjStm /Length %d >>\nstream\n%s\nendstream", len(data), data)
+}
+
+func TestPDFPageCount(t *testing.T) {
+	page := "<< /Type /Page /Parent 2 0 R >>"
+	pages := "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"
+
+	tests := []struct {
+		name string
+		data []byte
+		want uint32
+	}{
+		{"plain pages", testPDF("<< /Type /Catalog >>", pages, page, page, page), 3},
+		{"no space after Type", testPDF(pages, "<</Type/Page>>"), 1},
+		{"pages in an object stream", testPDF(pages, objectStream(page+page, 0), page), 3},
+		{"object stream without checksum", testPDF(pages, objectStream(strings.Repeat(page, 4), 4)), 4},
+		{"no pages", testPDF(pages), 0},
+		{"not a PDF", []byte("hello"), 0},
+	}
+
+	for _, tt := range tests {
+		if got := pdfPageCount(tt.data); got != tt.want {
+			t.Errorf("%s: pdfPageCount = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPDFStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantData string
+		wantOK   bool
+	}{
+		{"LF", "<< >>\nstream\nabc\nendstream", "abc\n", true},
+		{"CRLF", "<< >>\r\nstream\r\nabc\r\nendstream", "abc\r\n", true},
+		{"stream of a later object", "<< >>\nendobj\n2 0 obj\nstream\nabc\nendstream", "", false},
+		{"no endstream", "<< >>\nstream\nabc", "", false},
+	}
+
+	for _, tt := range tests {
+		start, end, ok := pdfStream([]byte(tt.data), 0)
+		if ok != tt.wantOK || (ok && tt.data[start:end] != tt.wantData) {
+			t.Errorf("%s: pdfStream = %q, %v, want %q, %v", tt.name, tt.data[start:end], ok, tt.wantData, tt.wantOK)
+		}
+	}
+}
+
+func FuzzPDFPageCount(f *testing.F) {
+	f.Add(testPDF("<< /Type /Page >>"))
+	f.Add(testPDF(objectStream("<< /Type /Page >>", 0)))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pdfPageCount(data)
+	})
+}
@@ -0,0 +1,171 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vp8l"
+)
+
+const (
+	// StickerSize is the side of the square canvas WhatsApp renders stickers on
+	StickerSize = 512
+
+	// exifStickerTag is the EXIF tag WhatsApp reads sticker pack metadata from
+	exifStickerTag = 0x5741
+)
+
+// stickerPackNamespace derives pack IDs from pack names and publishers
+var stickerPackNamespace = uuid.MustParse("3c2ba7a9-1b8e-4c05-9f4a-6f3f0a5d2e71")
+
+// StickerPack is the metadata WhatsApp shows for a sticker's pack
+type StickerPack struct {
+	ID        string // derived from the name and publisher if empty
+	Name      string
+	Publisher string
+	Emojis    []string
+}
+
+func (p StickerPack) empty() bool {
+	return p.ID == "" && p.Name == "" && p.Publisher == "" && len(p.Emojis) == 0
+}
+
+// Sticker is a WebP file ready to be sent as a sticker
+type Sticker struct {
+	Data     []byte
+	Width    uint32
+	Height   uint32
+	Animated bool
+}
+
+// NormalizeSticker turns an image into a WebP sticker with the pack metadata
+// embedded as EXIF. Static images are scaled to fit a StickerSize square and
+// centered on a transparent background, WebP files that already are the
+// right size keep their bitstream. Animated WebP files can't be re-encoded
+// and are sent as they are, with the metadata added.
+func NormalizeSticker(data []byte, pack StickerPack) (*Sticker, error) {
+	var lossless []byte
+	if isWebP(data) {
+		chunks, err := parseWebP(data)
+		if err != nil {
+			return nil, err
+		}
+		flags, width, height, ok := webpCanvas(chunks, data)
+		if !ok {
+			return nil, fmt.Errorf("invalid WebP image")
+		}
+		animated := flags&webpFlagAnimation != 0
+		if animated || (width == StickerSize && height == StickerSize) {
+			return &Sticker{
+				Data:     withStickerPack(chunks, flags, width, height, pack),
+				Width:    width,
+				Height:   height,
+				Animated: animated,
+			}, nil
+		}
+		for _, chunk := range chunks {
+			if chunk.id == "VP8L" {
+				lossless = chunk.data
+			}
+		}
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported sticker image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("sticker image of %dx%d is too large", config.Width, config.Height)
+	}
+
+	var src image.Image
+	if lossless != nil {
+		// The WebP decoder rejects lossless images in the extended format
+		// with the alpha flag set, although that's how encoders write them
+		src, err = vp8l.Decode(bytes.NewReader(lossless))
+	} else {
+		src, _, err = image.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sticker image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := StickerSize, StickerSize
+	if bounds.Dx() >= bounds.Dy() {
+		height = max(1, bounds.Dy()*StickerSize/bounds.Dx())
+	} else {
+		width = max(1, bounds.Dx()*StickerSize/bounds.Dy())
+	}
+	offset := image.Pt((StickerSize-width)/2, (StickerSize-height)/2)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, StickerSize, StickerSize))
+	draw.CatmullRom.Scale(dst, image.Rectangle{offset, offset.Add(image.Pt(width, height))}, src, bounds, draw.Src, nil)
+
+	var flags byte
+	for i := 3; i < len(dst.Pix); i += 4 {
+		if dst.Pix[i] != 0xff {
+			flags |= webpFlagAlpha
+			break
+		}
+	}
+	chunks := []riffChunk{{id: "VP8L", data: encodeVP8L(dst)}}
+
+	return &Sticker{
+		Data:   withStickerPack(chunks, flags, StickerSize, StickerSize, pack),
+		Width:  StickerSize,
+		Height: StickerSize,
+	}, nil
+}
+
+// withStickerPack writes a WebP file in the extended format, with the pack
+// metadata replacing any EXIF data
+func withStickerPack(chunks []riffChunk, flags byte, width, height uint32, pack StickerPack) []byte {
+	out := make([]riffChunk, 0, len(chunks)+2)
+	for _, chunk := range chunks {
+		if chunk.id != "VP8X" && chunk.id != "EXIF" {
+			out = append(out, chunk)
+		}
+	}
+
+	flags &^= webpFlagEXIF
+	if !pack.empty() {
+		flags |= webpFlagEXIF
+		out = append(out, riffChunk{id: "EXIF", data: stickerEXIF(pack)})
+	}
+
+	return writeWebP(append([]riffChunk{vp8xChunk(flags, width, height)}, out...))
+}
+
+// stickerEXIF encodes the pack metadata as JSON in a little endian TIFF
+// structure with a single IFD entry
+func stickerEXIF(pack StickerPack) []byte {
+	if pack.ID == "" {
+		pack.ID = uuid.NewSHA1(stickerPackNamespace, []byte(pack.Name+"\x00"+pack.Publisher)).String()
+	}
+	if pack.Emojis == nil {
+		pack.Emojis = []string{}
+	}
+	metadata, _ := json.Marshal(map[string]any{
+		"sticker-pack-id":        pack.ID,
+		"sticker-pack-name":      pack.Name,
+		"sticker-pack-publisher": pack.Publisher,
+		"emojis":                 pack.Emojis,
+	})
+
+	const valueOffset = 8 + 2 + 12 + 4 // header, entry count, entry, next IFD
+	exif := make([]byte, valueOffset, valueOffset+len(metadata))
+	copy(exif, "II*\x00")
+	binary.LittleEndian.PutUint32(exif[4:], 8)
+	binary.LittleEndian.PutUint16(exif[8:], 1)
+	binary.LittleEndian.PutUint16(exif[10:], exifStickerTag)
+	binary.LittleEndian.PutUint16(exif[12:], 7) // UNDEFINED
+	binary.LittleEndian.PutUint32(exif[14:], uint32(len(metadata)))
+	binary.LittleEndian.PutUint32(exif[18:], valueOffset)
+	return append(exif, metadata...)
+}
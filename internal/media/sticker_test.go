@@ -0,0 +1,175 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"testing"
+
+	"golang.org/x/image/vp8l"
+)
+
+// stickerChunks parses a sticker and returns its chunks by ID
+func stickerChunks(t *testing.T, name string, data []byte) map[string][]byte {
+	t.Helper()
+	chunks, err := parseWebP(data)
+	if err != nil {
+		t.Fatalf("%s: parseWebP: %v", name, err)
+	}
+	if chunks[0].id != "VP8X" {
+		t.Errorf("%s: first chunk is %s, want VP8X", name, chunks[0].id)
+	}
+	byID := make(map[string][]byte)
+	for _, chunk := range chunks {
+		byID[chunk.id] = chunk.data
+	}
+	return byID
+}
+
+func TestStickerEXIF(t *testing.T) {
+	exif := stickerEXIF(StickerPack{Name: "Cats", Publisher: "Ana", Emojis: []string{"🐱"}})
+
+	if string(exif[:4]) != "II*\x00" || binary.LittleEndian.Uint32(exif[4:]) != 8 {
+		t.Fatalf("header %q isn't little endian TIFF with the IFD at 8", exif[:8])
+	}
+	if count := binary.LittleEndian.Uint16(exif[8:]); count != 1 {
+		t.Fatalf("IFD has %d entries, want 1", count)
+	}
+	tag := binary.LittleEndian.Uint16(exif[10:])
+	size := binary.LittleEndian.Uint32(exif[14:])
+	offset := binary.LittleEndian.Uint32(exif[18:])
+	if tag != exifStickerTag || int(offset)+int(size) != len(exif) {
+		t.Fatalf("entry has tag %#x and value %d+%d, want tag %#x ending at %d", tag, offset, size, exifStickerTag, len(exif))
+	}
+
+	metadata := stickerMetadata(t, exif)
+	if metadata["sticker-pack-name"] != "Cats" || metadata["sticker-pack-publisher"] != "Ana" || metadata["sticker-pack-id"] == "" {
+		t.Errorf("metadata = %v", metadata)
+	}
+
+	// Derived IDs are stable and depend on the name and publisher
+	id := metadata["sticker-pack-id"]
+	if same := stickerMetadata(t, stickerEXIF(StickerPack{Name: "Cats", Publisher: "Ana"})); same["sticker-pack-id"] != id {
+		t.Errorf("pack ID %v, then %v for the same pack", id, same["sticker-pack-id"])
+	}
+	if other := stickerMetadata(t, stickerEXIF(StickerPack{Name: "Cat", Publisher: "sAna"})); other["sticker-pack-id"] == id {
+		t.Errorf("different packs share the ID %v", id)
+	}
+	if given := stickerMetadata(t, stickerEXIF(StickerPack{ID: "pack-1"})); given["sticker-pack-id"] != "pack-1" {
+		t.Errorf("pack ID %v, want the given pack-1", given["sticker-pack-id"])
+	}
+}
+
+// stickerMetadata decodes the JSON an EXIF chunk points to
+func stickerMetadata(t *testing.T, exif []byte) map[string]any {
+	t.Helper()
+	offset := binary.LittleEndian.Uint32(exif[18:])
+	var metadata map[string]any
+	if err := json.Unmarshal(exif[offset:], &metadata); err != nil {
+		t.Fatalf("metadata isn't JSON: %v", err)
+	}
+	return metadata
+}
+
+func TestNormalizeSticker(t *testing.T) {
+	pack := StickerPack{Name: "Pack", Publisher: "Me"}
+	animated := writeWebP([]riffChunk{
+		vp8xChunk(webpFlagAnimation|webpFlagEXIF, 200, 100),
+		{id: "ANIM", data: make([]byte, 6)},
+		{id: "EXIF", data: []byte("old")},
+	})
+
+	tests := []struct {
+		name         string
+		data         []byte
+		pack         StickerPack
+		wantWidth    uint32
+		wantHeight   uint32
+		wantAnimated bool
+		wantEXIF     bool
+	}{
+		{"PNG", testPNG(t, 300, 150), pack, StickerSize, StickerSize, false, true},
+		{"no pack", testPNG(t, 20, 40), StickerPack{}, StickerSize, StickerSize, false, false},
+		{"small WebP", testWebP("transparent", 64, 64), pack, StickerSize, StickerSize, false, true},
+		{"sticker sized WebP", testWebP("stripes", StickerSize, StickerSize), pack, StickerSize, StickerSize, false, true},
+		{"animated", animated, pack, 200, 100, true, true},
+		{"animated without pack", animated, StickerPack{}, 200, 100, true, false},
+	}
+
+	for _, tt := range tests {
+		sticker, err := NormalizeSticker(tt.data, tt.pack)
+		if err != nil {
+			t.Errorf("%s: NormalizeSticker: %v", tt.name, err)
+			continue
+		}
+		if sticker.Width != tt.wantWidth || sticker.Height != tt.wantHeight || sticker.Animated != tt.wantAnimated {
+			t.Errorf("%s: sticker is %dx%d animated %v, want %dx%d animated %v", tt.name, sticker.Width, sticker.Height,
+				sticker.Animated, tt.wantWidth, tt.wantHeight, tt.wantAnimated)
+		}
+
+		chunks := stickerChunks(t, tt.name, sticker.Data)
+		flags, width, height := chunks["VP8X"][0], getUint24(chunks["VP8X"][4:])+1, getUint24(chunks["VP8X"][7:])+1
+		if width != tt.wantWidth || height != tt.wantHeight {
+			t.Errorf("%s: canvas is %dx%d, want %dx%d", tt.name, width, height, tt.wantWidth, tt.wantHeight)
+		}
+		exif, hasEXIF := chunks["EXIF"]
+		if hasEXIF != tt.wantEXIF || (flags&webpFlagEXIF != 0) != tt.wantEXIF {
+			t.Errorf("%s: EXIF chunk %v with flags %#x, want EXIF %v", tt.name, hasEXIF, flags, tt.wantEXIF)
+		}
+		if hasEXIF && !bytes.Equal(exif, stickerEXIF(tt.pack)) {
+			t.Errorf("%s: EXIF chunk doesn't hold the pack", tt.name)
+		}
+
+		if tt.wantAnimated {
+			continue
+		}
+		img, err := vp8l.Decode(bytes.NewReader(chunks["VP8L"]))
+		if err != nil {
+			t.Errorf("%s: decoding the bitstream: %v", tt.name, err)
+			continue
+		}
+		if img.Bounds() != image.Rect(0, 0, StickerSize, StickerSize) {
+			t.Errorf("%s: bitstream is %v", tt.name, img.Bounds())
+		}
+	}
+}
+
+func TestNormalizeStickerCentersImage(t *testing.T) {
+	sticker, err := NormalizeSticker(testPNG(t, 200, 100), StickerPack{})
+	if err != nil {
+		t.Fatalf("NormalizeSticker: %v", err)
+	}
+	chunks := stickerChunks(t, "landscape", sticker.Data)
+	if chunks["VP8X"][0]&webpFlagAlpha == 0 {
+		t.Errorf("padded sticker doesn't have the alpha flag")
+	}
+	img, err := vp8l.Decode(bytes.NewReader(chunks["VP8L"]))
+	if err != nil {
+		t.Fatalf("decoding the bitstream: %v", err)
+	}
+
+	// A 512x256 image with transparent bands above and below
+	nrgba := img.(*image.NRGBA)
+	if top, middle, bottom := nrgba.NRGBAAt(256, 10).A, nrgba.NRGBAAt(256, 256).A, nrgba.NRGBAAt(256, 500).A; top != 0 || middle != 0xff || bottom != 0 {
+		t.Errorf("alpha at top, middle and bottom is %d, %d, %d, want 0, 255, 0", top, middle, bottom)
+	}
+}
+
+func TestNormalizeStickerRejectsInvalidImages(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not an image", []byte("hello")},
+		{"huge PNG", withPNGSize(testPNG(t, 4, 4), 100000, 100000)},
+		{"truncated WebP", testWebP("noise", 8, 8)[:30]},
+		{"unknown WebP bitstream", riffFile(16, "VP8L\x04\x00\x00\x001234")},
+	}
+
+	for _, tt := range tests {
+		if sticker, err := NormalizeSticker(tt.data, StickerPack{Name: "x"}); err == nil {
+			t.Errorf("%s: NormalizeSticker = %dx%d, want an error", tt.name, sticker.Width, sticker.Height)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const pruneInterval = time.Hour
+
+// ErrTooLarge is returned for media over the configured size limit
+var ErrTooLarge = errors.New("media exceeds the maximum size")
+
+// Media describes a stored upload, referenced by its ID when sending messages
+// from the account that uploaded it
+type Media struct {
+	ID          string    `json:"id"`
+	WaAccountID string    `json:"wa_account_id"`
+	FileName    string    `json:"file_name"`
+	Mime        string    `json:"mime"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Store keeps uploaded media on disk so it can be sent any number of times
+// until it expires. Every upload is a data file next to a JSON metadata file.
+type Store struct {
+	dir      string
+	maxSize  int64
+	ttl      time.Duration
+	stopChan chan struct{}
+	once     sync.Once
+}
+
+func NewStore(dir string, maxSize int64, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	s := &Store{
+		dir:      dir,
+		maxSize:  maxSize,
+		ttl:      ttl,
+		stopChan: make(chan struct{}),
+	}
+
+	go s.cleanup()
+
+	return s, nil
+}
+
+// MaxSize is the largest accepted media in bytes
+func (s *Store) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Save stores the media read from r for an account, failing with ErrTooLarge
+// past the size limit
+func (s *Store) Save(r io.Reader, waAccountID, fileName, mime string) (*Media, error) {
+	id := uuid.New().String()
+	path := s.dataPath(id)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create media file: %w", err)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(r, s.maxSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size > s.maxSize {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		os.Remove(path)
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to write media file: %w", err)
+	}
+
+	if fileName != "" {
+		fileName = filepath.Base(fileName)
+	}
+
+	now := time.Now()
+	m := &Media{
+		ID:          id,
+		WaAccountID: waAccountID,
+		FileName:    fileName,
+		Mime:        mime,
+		Size:        size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	meta, err := json.Marshal(m)
+	if err == nil {
+		err = os.WriteFile(s.metaPath(id), meta, 0o600)
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to write media metadata: %w", err)
+	}
+
+	return m, nil
+}
+
+// Stat returns the metadata of stored media, or nil if it doesn't exist or expired
+func (s *Store) Stat(id string) (*Media, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+
+	m, err := s.readMeta(id)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	if time.Now().After(m.ExpiresAt) {
+		return nil, nil
+	}
+
+	return m, nil
+}
+
+// Get returns stored media and its content, or nil if it doesn't exist or expired
+func (s *Store) Get(id string) (*Media, []byte, error) {
+	m, err := s.Stat(id)
+	if err != nil || m == nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(s.dataPath(m.ID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read media file: %w", err)
+	}
+
+	return m, data, nil
+}
+
+// Delete removes stored media, it reports false if the media doesn't exist
+func (s *Store) Delete(id string) (bool, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return false, nil
+	}
+
+	err := os.Remove(s.metaPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete media: %w", err)
+	}
+	if err := os.Remove(s.dataPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return true, fmt.Errorf("failed to delete media: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *Store) readMeta(id string) (*Media, error) {
+	raw, err := os.ReadFile(s.metaPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media metadata: %w", err)
+	}
+
+	m := &Media{}
+	if err := json.Unmarshal(raw, m); err != nil {
+		return nil, fmt.Errorf("failed to decode media metadata: %w", err)
+	}
+	return m, nil
+}
+
+func (s *Store) dataPath(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *Store) cleanup() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	s.prune()
+	for {
+		select {
+		case <-ticker.C:
+			s.prune()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// prune removes expired media
+func (s *Store) prune() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		log.Error().Err(err).Str("dir", s.dir).Msg("Failed to list media directory")
+		return
+	}
+
+	pruned := 0
+	now := time.Now()
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		m, err := s.readMeta(id)
+		if err != nil {
+			log.Warn().Err(err).Str("media_id", id).Msg("Failed to read media metadata")
+			continue
+		}
+		if m == nil || now.Before(m.ExpiresAt) {
+			continue
+		}
+		if _, err := s.Delete(id); err != nil {
+			log.Warn().Err(err).Str("media_id", id).Msg("Failed to prune media")
+			continue
+		}
+		pruned++
+	}
+
+	if pruned > 0 {
+		log.Info().Int("media", pruned).Msg("Pruned expired media")
+	}
+}
+
+func (s *Store) Close() error {
+	s.once.Do(func() { close(s.stopChan) })
+	return nil
+}
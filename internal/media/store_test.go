@@ -0,0 +1,45 @@
+package media
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreSaveKeepsAccount(t *testing.T) {
+	s, err := NewStore(t.TempDir(), 16, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	m, err := s.Save(strings.NewReader("hello"), "acc-1", "../hello.txt", "text/plain")
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, data, err := s.Get(m.ID)
+	if err != nil || got == nil {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+	if got.WaAccountID != "acc-1" || got.FileName != "hello.txt" || string(data) != "hello" {
+		t.Errorf("Get = %+v %q, want account acc-1, file hello.txt and the saved content", got, data)
+	}
+
+	if _, err := s.Save(strings.NewReader(strings.Repeat("x", 17)), "acc-1", "", ""); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Save over the limit = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestRequestSizeLimit(t *testing.T) {
+	for _, size := range []int64{0, 1, 1000, 16 << 20} {
+		encoded := base64.StdEncoding.EncodedLen(int(size))
+		// Wrapped at 76 characters with CRLF line breaks, like MIME
+		wrapped := int64(encoded + encoded/76*2)
+		if limit := RequestSizeLimit(size); limit < wrapped+requestOverhead {
+			t.Errorf("RequestSizeLimit(%d) = %d, wrapped base64 alone takes %d", size, limit, wrapped)
+		}
+	}
+}
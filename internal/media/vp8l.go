@@ -0,0 +1,548 @@
+package media
+
+import (
+	"container/heap"
+	"image"
+)
+
+// A lossless VP8L encoder, enough to produce WebP stickers without cgo. It
+// applies the subtract green and predictor transforms and entropy codes the
+// residuals with Huffman codes and run length backward references, no color
+// cache or general LZ77 matching.
+// The format is specified at https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification
+
+const (
+	vp8lSignature    = 0x2f
+	vp8lPredictBits  = 5 // predictor tiles of 32x32 pixels
+	vp8lMaxCodeBits  = 15
+	vp8lMaxRunLength = 4096
+
+	vp8lLiteralCodes  = 256
+	vp8lLengthCodes   = 24
+	vp8lDistanceCodes = 40
+
+	// distance codes of the pixel above and the pixel to the left
+	vp8lDistanceUp   = 1
+	vp8lDistanceLeft = 2
+)
+
+// vp8lPredictors are the predictor modes tried per tile
+var vp8lPredictors = []byte{1, 2, 7, 11, 12}
+
+// vp8lCodeLengthOrder is the order code length code lengths are written in
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// encodeVP8L encodes an image as a VP8L bitstream, the payload of a VP8L chunk
+func encodeVP8L(img *image.NRGBA) []byte {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+
+	// Work on a tightly packed copy, the transforms change it in place
+	pix := make([]byte, 4*width*height)
+	alpha := false
+	for y := 0; y < height; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+4*width]
+		copy(pix[4*width*y:], row)
+		for x := 3; x < len(row); x += 4 {
+			alpha = alpha || row[x] != 0xff
+		}
+	}
+
+	var w bitWriter
+	w.write(vp8lSignature, 8)
+	w.write(uint32(width-1), 14)
+	w.write(uint32(height-1), 14)
+	w.writeBool(alpha)
+	w.write(0, 3) // version
+
+	// Subtract green
+	w.write(1, 1)
+	w.write(2, 2)
+	for p := 0; p < len(pix); p += 4 {
+		pix[p] -= pix[p+1]
+		pix[p+2] -= pix[p+1]
+	}
+
+	// Predictor
+	w.write(1, 1)
+	w.write(0, 2)
+	w.write(vp8lPredictBits-2, 3)
+	modes, tilesWide, tilesHigh := vp8lPredict(pix, width, height)
+	writeVP8LImage(&w, modes, tilesWide, tilesHigh, false)
+
+	w.write(0, 1) // no more transforms
+	writeVP8LImage(&w, pix, width, height, true)
+
+	return w.bytes()
+}
+
+// vp8lPredict replaces pixels with their difference to the best of a few
+// predictors per tile and returns the tile modes as an image
+func vp8lPredict(pix []byte, width, height int) ([]byte, int, int) {
+	tile := 1 << vp8lPredictBits
+	tilesWide := (width + tile - 1) / tile
+	tilesHigh := (height + tile - 1) / tile
+	modes := make([]byte, 4*tilesWide*tilesHigh)
+
+	for ty := 0; ty < tilesHigh; ty++ {
+		for tx := 0; tx < tilesWide; tx++ {
+			best, bestCost := vp8lPredictors[0], -1
+			for _, mode := range vp8lPredictors {
+				cost := 0
+				for y := ty * tile; y < min(height, (ty+1)*tile); y++ {
+					for x := tx * tile; x < min(width, (tx+1)*tile); x++ {
+						predicted := vp8lPrediction(pix, width, x, y, mode)
+						p := 4 * (y*width + x)
+						for c := 0; c < 4; c++ {
+							cost += int(absResidual(pix[p+c] - predicted[c]))
+						}
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[4*(ty*tilesWide+tx)+1] = best // the mode is kept in green
+		}
+	}
+
+	// Predictions are made from the original neighbours, as decoders have
+	// restored them by then
+	residuals := make([]byte, len(pix))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mode := modes[4*((y>>vp8lPredictBits)*tilesWide+x>>vp8lPredictBits)+1]
+			predicted := vp8lPrediction(pix, width, x, y, mode)
+			p := 4 * (y*width + x)
+			for c := 0; c < 4; c++ {
+				residuals[p+c] = pix[p+c] - predicted[c]
+			}
+		}
+	}
+	copy(pix, residuals)
+
+	return modes, tilesWide, tilesHigh
+}
+
+// vp8lPrediction predicts a pixel from its neighbours the way decoders do:
+// opaque black for the first pixel, L on the first row, T on the first column
+func vp8lPrediction(pix []byte, width, x, y int, mode byte) [4]byte {
+	p := 4 * (y*width + x)
+	switch {
+	case x == 0 && y == 0:
+		return [4]byte{0, 0, 0, 0xff}
+	case y == 0:
+		mode = 1
+	case x == 0:
+		mode = 2
+	}
+
+	var left, top, topLeft [4]byte
+	copy(left[:], pix[max(0, p-4):])
+	copy(top[:], pix[max(0, p-4*width):])
+	copy(topLeft[:], pix[max(0, p-4*width-4):])
+
+	var out [4]byte
+	switch mode {
+	case 1:
+		out = left
+	case 2:
+		out = top
+	case 7:
+		for c := range out {
+			out[c] = byte((int(left[c]) + int(top[c])) / 2)
+		}
+	case 11:
+		// Select: the neighbour in the direction with less change
+		predictLeft, predictTop := 0, 0
+		for c := range out {
+			predictLeft += absInt(int(topLeft[c]) - int(top[c]))
+			predictTop += absInt(int(topLeft[c]) - int(left[c]))
+		}
+		out = top
+		if predictLeft < predictTop {
+			out = left
+		}
+	case 12:
+		for c := range out {
+			out[c] = byte(min(255, max(0, int(left[c])+int(top[c])-int(topLeft[c]))))
+		}
+	}
+	return out
+}
+
+// writeVP8LImage entropy codes an image: the main image when topLevel, or the
+// sub-image of a transform
+func writeVP8LImage(w *bitWriter, pix []byte, width, height int, topLevel bool) {
+	w.write(0, 1) // no color cache
+	if topLevel {
+		w.write(0, 1) // a single set of Huffman codes
+	}
+
+	// Symbols: literal pixels, or runs copying the pixel to the left or above
+	type symbol struct {
+		pixel  int // offset in pix, or -1 for a run
+		length int
+		dist   int
+	}
+	var symbols []symbol
+	histograms := [5][]int{
+		make([]int, vp8lLiteralCodes+vp8lLengthCodes),
+		make([]int, vp8lLiteralCodes),
+		make([]int, vp8lLiteralCodes),
+		make([]int, vp8lLiteralCodes),
+		make([]int, vp8lDistanceCodes),
+	}
+
+	count := width * height
+	for i := 0; i < count; {
+		left, up := 0, 0
+		if i >= 1 {
+			left = runLength(pix, i, 1, count)
+		}
+		if i >= width {
+			up = runLength(pix, i, width, count)
+		}
+
+		if max(left, up) < 3 {
+			p := 4 * i
+			symbols = append(symbols, symbol{pixel: p})
+			histograms[0][pix[p+1]]++
+			histograms[1][pix[p]]++
+			histograms[2][pix[p+2]]++
+			histograms[3][pix[p+3]]++
+			i++
+			continue
+		}
+
+		s := symbol{pixel: -1, length: left, dist: vp8lDistanceLeft}
+		if up > left {
+			s.length, s.dist = up, vp8lDistanceUp
+		}
+		symbols = append(symbols, s)
+		lengthCode, _, _ := vp8lPrefix(s.length)
+		distCode, _, _ := vp8lPrefix(s.dist)
+		histograms[0][vp8lLiteralCodes+lengthCode]++
+		histograms[4][distCode]++
+		i += s.length
+	}
+
+	var codes [5]huffmanCode
+	for i, histogram := range histograms {
+		codes[i] = writeHuffmanCode(w, histogram)
+	}
+
+	for _, s := range symbols {
+		if s.pixel >= 0 {
+			codes[0].write(w, int(pix[s.pixel+1]))
+			codes[1].write(w, int(pix[s.pixel]))
+			codes[2].write(w, int(pix[s.pixel+2]))
+			codes[3].write(w, int(pix[s.pixel+3]))
+			continue
+		}
+		code, bits, extra := vp8lPrefix(s.length)
+		codes[0].write(w, vp8lLiteralCodes+code)
+		w.write(extra, bits)
+		code, bits, extra = vp8lPrefix(s.dist)
+		codes[4].write(w, code)
+		w.write(extra, bits)
+	}
+}
+
+// runLength counts the pixels from i on equal to the pixel dist before them
+func runLength(pix []byte, i, dist, count int) int {
+	n := 0
+	for i+n < count && n < vp8lMaxRunLength {
+		p, q := 4*(i+n), 4*(i+n-dist)
+		if pix[p] != pix[q] || pix[p+1] != pix[q+1] || pix[p+2] != pix[q+2] || pix[p+3] != pix[q+3] {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// vp8lPrefix splits a backward reference length or distance into its prefix
+// code and extra bits
+func vp8lPrefix(value int) (int, uint, uint32) {
+	value--
+	if value < 4 {
+		return value, 0, 0
+	}
+	highest := 0
+	for v := value; v > 1; v >>= 1 {
+		highest++
+	}
+	second := (value >> (highest - 1)) & 1
+	bits := uint(highest - 1)
+	return 2*highest + second, bits, uint32(value) & (1<<bits - 1)
+}
+
+// huffmanCode is a canonical Huffman code, codes are stored bit reversed as
+// they're written least significant bit first
+type huffmanCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (h huffmanCode) write(w *bitWriter, symbol int) {
+	w.write(h.codes[symbol], uint(h.lengths[symbol]))
+}
+
+// writeHuffmanCode writes the code for a histogram and returns it, one or
+// two small symbols use the compact simple code
+func writeHuffmanCode(w *bitWriter, histogram []int) huffmanCode {
+	var used []int
+	for symbol, n := range histogram {
+		if n > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		code := huffmanCode{lengths: make([]uint8, len(histogram)), codes: make([]uint32, len(histogram))}
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		w.write(1, 1)
+		w.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			w.write(0, 1)
+			w.write(uint32(used[0]), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			w.write(uint32(used[1]), 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	code := newHuffmanCode(histogram, vp8lMaxCodeBits)
+	w.write(0, 1)
+
+	// The code lengths are themselves Huffman coded, with zero runs
+	type lengthSymbol struct {
+		symbol int
+		extra  uint32
+	}
+	var lengthSymbols []lengthSymbol
+	lengthHistogram := make([]int, len(vp8lCodeLengthOrder))
+	for i := 0; i < len(code.lengths); {
+		zeros := 0
+		for i+zeros < len(code.lengths) && code.lengths[i+zeros] == 0 && zeros < 138 {
+			zeros++
+		}
+		switch {
+		case zeros >= 11:
+			lengthSymbols = append(lengthSymbols, lengthSymbol{18, uint32(zeros - 11)})
+			i += zeros
+		case zeros >= 3:
+			lengthSymbols = append(lengthSymbols, lengthSymbol{17, uint32(zeros - 3)})
+			i += zeros
+		default:
+			lengthSymbols = append(lengthSymbols, lengthSymbol{int(code.lengths[i]), 0})
+			i++
+		}
+		lengthHistogram[lengthSymbols[len(lengthSymbols)-1].symbol]++
+	}
+
+	lengthCode := newHuffmanCode(lengthHistogram, 7)
+	last := 3
+	for i, symbol := range vp8lCodeLengthOrder {
+		if lengthCode.lengths[symbol] > 0 {
+			last = max(last, i)
+		}
+	}
+	w.write(uint32(last+1-4), 4)
+	for _, symbol := range vp8lCodeLengthOrder[:last+1] {
+		w.write(uint32(lengthCode.lengths[symbol]), 3)
+	}
+
+	w.write(0, 1) // lengths for the whole alphabet follow
+	for _, s := range lengthSymbols {
+		lengthCode.write(w, s.symbol)
+		switch s.symbol {
+		case 17:
+			w.write(s.extra, 3)
+		case 18:
+			w.write(s.extra, 7)
+		}
+	}
+
+	return code
+}
+
+// newHuffmanCode builds a canonical Huffman code with at least two symbols
+// and codes no longer than maxBits
+func newHuffmanCode(histogram []int, maxBits int) huffmanCode {
+	counts := make([]int, len(histogram))
+	copy(counts, histogram)
+
+	// A single symbol would get an empty code, pair it with a dummy
+	used := 0
+	for _, n := range counts {
+		if n > 0 {
+			used++
+		}
+	}
+	for i := 0; used < 2 && i < len(counts); i++ {
+		if counts[i] == 0 {
+			counts[i] = 1
+			used++
+		}
+	}
+
+	var lengths []uint8
+	for {
+		lengths = huffmanLengths(counts)
+		longest := uint8(0)
+		for _, l := range lengths {
+			longest = max(longest, l)
+		}
+		if int(longest) <= maxBits {
+			break
+		}
+		// Flatten the distribution until the tree is shallow enough
+		for i, n := range counts {
+			if n > 0 {
+				counts[i] = (n + 1) / 2
+			}
+		}
+	}
+
+	code := huffmanCode{lengths: lengths, codes: make([]uint32, len(lengths))}
+	var lengthCounts [vp8lMaxCodeBits + 1]uint32
+	for _, l := range lengths {
+		lengthCounts[l]++
+	}
+	lengthCounts[0] = 0
+	var next [vp8lMaxCodeBits + 2]uint32
+	for bits := 1; bits <= vp8lMaxCodeBits; bits++ {
+		next[bits] = (next[bits-1] + lengthCounts[bits-1]) << 1
+	}
+	for symbol, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		reversed := uint32(0)
+		for i := uint8(0); i < l; i++ {
+			reversed = reversed<<1 | (c>>i)&1
+		}
+		code.codes[symbol] = reversed
+	}
+	return code
+}
+
+// huffmanLengths returns the optimal code length of every symbol
+func huffmanLengths(counts []int) []uint8 {
+	type node struct {
+		count       int
+		symbol      int
+		left, right int
+	}
+	nodes := make([]node, 0, 2*len(counts))
+	h := &huffmanHeap{}
+	for symbol, n := range counts {
+		if n > 0 {
+			nodes = append(nodes, node{count: n, symbol: symbol, left: -1, right: -1})
+			*h = append(*h, huffmanItem{count: n, node: len(nodes) - 1})
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 1 {
+		a := heap.Pop(h).(huffmanItem)
+		b := heap.Pop(h).(huffmanItem)
+		nodes = append(nodes, node{count: a.count + b.count, symbol: -1, left: a.node, right: b.node})
+		heap.Push(h, huffmanItem{count: a.count + b.count, node: len(nodes) - 1})
+	}
+
+	lengths := make([]uint8, len(counts))
+	var walk func(n, depth int)
+	walk = func(n, depth int) {
+		if nodes[n].symbol >= 0 {
+			lengths[nodes[n].symbol] = uint8(max(1, depth))
+			return
+		}
+		walk(nodes[n].left, depth+1)
+		walk(nodes[n].right, depth+1)
+	}
+	walk(len(nodes)-1, 0)
+	return lengths
+}
+
+type huffmanItem struct {
+	count int
+	node  int
+}
+
+type huffmanHeap []huffmanItem
+
+func (h huffmanHeap) Len() int { return len(h) }
+
+// Less breaks ties by node so codes are deterministic
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count < h[j].count
+	}
+	return h[i].node < h[j].node
+}
+func (h huffmanHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x any)   { *h = append(*h, x.(huffmanItem)) }
+func (h *huffmanHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// bitWriter packs bits least significant first, as VP8L reads them
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nBits uint
+}
+
+func (w *bitWriter) write(value uint32, n uint) {
+	w.bits |= uint64(value&(1<<n-1)) << w.nBits
+	w.nBits += n
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *bitWriter) writeBool(b bool) {
+	if b {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nBits = 0, 0
+	}
+	return w.buf
+}
+
+func absResidual(b byte) byte {
+	if b > 128 {
+		return -b
+	}
+	return b
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,141 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/vp8l"
+)
+
+// testImage fills an image of a kind the encoder handles differently
+func testImage(kind string, width, height int, seed int64) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	r := rand.New(rand.NewSource(seed))
+	for y := range height {
+		for x := range width {
+			var c color.NRGBA
+			switch kind {
+			case "solid":
+				c = color.NRGBA{R: 200, G: 30, B: 90, A: 255}
+			case "gradient":
+				c = color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: 255}
+			case "noise":
+				c = color.NRGBA{R: uint8(r.Intn(256)), G: uint8(r.Intn(256)), B: uint8(r.Intn(256)), A: uint8(r.Intn(256))}
+			case "transparent":
+				c = color.NRGBA{R: uint8(x), G: 100, B: uint8(y), A: uint8((x * y) % 256)}
+			case "stripes":
+				if (x/7)%2 == 0 {
+					c = color.NRGBA{A: 255}
+				} else {
+					c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+				}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// checkVP8LRoundTrip decodes an encoded image and compares every pixel
+func checkVP8LRoundTrip(t *testing.T, name string, img *image.NRGBA) {
+	t.Helper()
+
+	decoded, err := vp8l.Decode(bytes.NewReader(encodeVP8L(img)))
+	if err != nil {
+		t.Errorf("%s: decoding: %v", name, err)
+		return
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("%s: decoded bounds %v, want %v", name, decoded.Bounds(), img.Bounds())
+		return
+	}
+
+	got, ok := decoded.(*image.NRGBA)
+	if !ok {
+		t.Errorf("%s: decoded a %T", name, decoded)
+		return
+	}
+	for y := range img.Rect.Dy() {
+		for x := range img.Rect.Dx() {
+			if want, have := img.NRGBAAt(x, y), got.NRGBAAt(x, y); want != have {
+				t.Errorf("%s: pixel %d,%d is %v, want %v", name, x, y, have, want)
+				return
+			}
+		}
+	}
+}
+
+func TestEncodeVP8LRoundTrip(t *testing.T) {
+	tests := []struct {
+		kind          string
+		width, height int
+	}{
+		{"solid", 1, 1},
+		{"solid", 64, 64},
+		{"gradient", 300, 17},
+		{"noise", 33, 65},
+		{"transparent", 128, 128},
+		{"stripes", 512, 512},
+		{"gradient", 1, 200},
+	}
+
+	for _, tt := range tests {
+		checkVP8LRoundTrip(t, tt.kind, testImage(tt.kind, tt.width, tt.height, 1))
+	}
+}
+
+func TestEncodeVP8LSubImage(t *testing.T) {
+	// The stride of a sub image is wider than its rows
+	img := testImage("gradient", 100, 100, 1).SubImage(image.Rect(10, 20, 60, 45)).(*image.NRGBA)
+	decoded, err := vp8l.Decode(bytes.NewReader(encodeVP8L(img)))
+	if err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	if got := decoded.(*image.NRGBA).NRGBAAt(0, 0); got != img.NRGBAAt(10, 20) {
+		t.Errorf("first pixel is %v, want %v", got, img.NRGBAAt(10, 20))
+	}
+}
+
+func TestNewHuffmanCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		counts  []int
+		maxBits int
+	}{
+		{"even", []int{1, 1, 1, 1}, 15},
+		{"skewed", []int{1000, 1, 1, 1, 1, 1, 1, 1}, 15},
+		{"limited", []int{1 << 20, 1 << 18, 1 << 16, 1 << 14, 1 << 12, 1 << 10, 1 << 8, 1 << 6, 1 << 4, 1 << 2, 1, 1}, 5},
+	}
+
+	for _, tt := range tests {
+		code := newHuffmanCode(tt.counts, tt.maxBits)
+		// A complete prefix code satisfies Kraft's equality
+		kraft := 0.0
+		for symbol, length := range code.lengths {
+			if tt.counts[symbol] == 0 {
+				continue
+			}
+			if length == 0 || int(length) > tt.maxBits {
+				t.Errorf("%s: symbol %d has length %d, limit %d", tt.name, symbol, length, tt.maxBits)
+			}
+			kraft += 1 / float64(uint(1)<<length)
+		}
+		if kraft != 1 {
+			t.Errorf("%s: Kraft sum %v, want 1", tt.name, kraft)
+		}
+	}
+}
+
+func FuzzEncodeVP8L(f *testing.F) {
+	f.Add(uint8(1), uint8(1), int64(0))
+	f.Add(uint8(40), uint8(3), int64(7))
+	f.Fuzz(func(t *testing.T, width, height uint8, seed int64) {
+		if width == 0 || height == 0 {
+			return
+		}
+		checkVP8LRoundTrip(t, "noise", testImage("noise", int(width), int(height), seed))
+	})
+}
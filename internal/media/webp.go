@@ -0,0 +1,109 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// VP8X feature flags
+const (
+	webpFlagAnimation = 0x02
+	webpFlagEXIF      = 0x08
+	webpFlagAlpha     = 0x10
+)
+
+// riffChunk is a chunk of a WebP RIFF container
+type riffChunk struct {
+	id   string
+	data []byte
+}
+
+// isWebP reports whether data is a RIFF WebP file
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// parseWebP splits a WebP file into its chunks
+func parseWebP(data []byte) ([]riffChunk, error) {
+	if !isWebP(data) {
+		return nil, fmt.Errorf("not a WebP file")
+	}
+
+	// Trailing bytes after the declared RIFF size are ignored
+	if size := int(binary.LittleEndian.Uint32(data[4:])) + 8; size >= 12 && size < len(data) {
+		data = data[:size]
+	}
+
+	var chunks []riffChunk
+	for rest := data[12:]; len(rest) > 0; {
+		if len(rest) < 8 {
+			return nil, fmt.Errorf("truncated WebP chunk")
+		}
+		size := int(binary.LittleEndian.Uint32(rest[4:]))
+		if size > len(rest)-8 {
+			return nil, fmt.Errorf("truncated WebP chunk")
+		}
+		chunks = append(chunks, riffChunk{id: string(rest[:4]), data: rest[8 : 8+size]})
+		rest = rest[min(len(rest), 8+size+size&1):]
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("empty WebP file")
+	}
+	return chunks, nil
+}
+
+// writeWebP assembles a WebP file from its chunks
+func writeWebP(chunks []riffChunk) []byte {
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	for _, chunk := range chunks {
+		body.WriteString(chunk.id)
+		binary.Write(&body, binary.LittleEndian, uint32(len(chunk.data)))
+		body.Write(chunk.data)
+		if len(chunk.data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	out := make([]byte, 8, 8+body.Len())
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(body.Len()))
+	return append(out, body.Bytes()...)
+}
+
+// vp8xChunk builds the extended format header
+func vp8xChunk(flags byte, width, height uint32) riffChunk {
+	data := make([]byte, 10)
+	data[0] = flags
+	putUint24(data[4:], width-1)
+	putUint24(data[7:], height-1)
+	return riffChunk{id: "VP8X", data: data}
+}
+
+// webpCanvas returns the feature flags and canvas size of a WebP file, the
+// simple formats get the flags their extended header would have
+func webpCanvas(chunks []riffChunk, data []byte) (byte, uint32, uint32, bool) {
+	if chunks[0].id == "VP8X" && len(chunks[0].data) >= 10 {
+		header := chunks[0].data
+		return header[0], getUint24(header[4:]) + 1, getUint24(header[7:]) + 1, true
+	}
+
+	width, height, ok := imageSize(data)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	var flags byte
+	if header := chunks[0].data; chunks[0].id == "VP8L" && len(header) >= 5 && header[4]&0x10 != 0 {
+		flags |= webpFlagAlpha
+	}
+	return flags, width, height, true
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+func getUint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
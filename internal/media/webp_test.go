@@ -0,0 +1,147 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// testWebP is a simple format lossless WebP file
+func testWebP(kind string, width, height int) []byte {
+	return writeWebP([]riffChunk{{id: "VP8L", data: encodeVP8L(testImage(kind, width, height, 1))}})
+}
+
+// riffFile wraps chunk bytes in a RIFF header declaring size
+func riffFile(size uint32, body string) []byte {
+	data := []byte("RIFF")
+	data = binary.LittleEndian.AppendUint32(data, size)
+	return append(data, "WEBP"+body...)
+}
+
+func TestParseWebP(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantIDs []string
+		wantErr bool
+	}{
+		{"one chunk", riffFile(16, "ABCD\x04\x00\x00\x001234"), []string{"ABCD"}, false},
+		{"odd chunk padded", riffFile(26, "ABCD\x01\x00\x00\x001\x00EFGH\x01\x00\x00\x002\x00"), []string{"ABCD", "EFGH"}, false},
+		{"last chunk unpadded", riffFile(17, "ABCD\x01\x00\x00\x001"), []string{"ABCD"}, false},
+		{"trailing bytes", append(riffFile(16, "ABCD\x04\x00\x00\x001234"), "junk"...), []string{"ABCD"}, false},
+		{"not RIFF", []byte("RIFX\x10\x00\x00\x00WEBPABCD"), nil, true},
+		{"not WebP", []byte("RIFF\x10\x00\x00\x00WAVEABCD"), nil, true},
+		{"empty", riffFile(4, ""), nil, true},
+		{"truncated header", riffFile(8, "ABCD"), nil, true},
+		{"truncated chunk", riffFile(16, "ABCD\x08\x00\x00\x001234"), nil, true},
+		{"huge chunk", riffFile(16, "ABCD\xff\xff\xff\xff1234"), nil, true},
+	}
+
+	for _, tt := range tests {
+		chunks, err := parseWebP(tt.data)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: parseWebP succeeded", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: parseWebP: %v", tt.name, err)
+			continue
+		}
+		var ids []string
+		for _, chunk := range chunks {
+			ids = append(ids, chunk.id)
+		}
+		if len(ids) != len(tt.wantIDs) {
+			t.Errorf("%s: chunks %v, want %v", tt.name, ids, tt.wantIDs)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.wantIDs[i] {
+				t.Errorf("%s: chunks %v, want %v", tt.name, ids, tt.wantIDs)
+				break
+			}
+		}
+	}
+}
+
+func TestWriteWebPRoundTrip(t *testing.T) {
+	chunks := []riffChunk{
+		vp8xChunk(webpFlagAlpha|webpFlagEXIF, 512, 300),
+		{id: "VP8L", data: []byte("odd")},
+		{id: "EXIF", data: []byte("even")},
+	}
+	data := writeWebP(chunks)
+	if len(data)%2 != 0 {
+		t.Errorf("file has odd length %d", len(data))
+	}
+	if size := binary.LittleEndian.Uint32(data[4:]); int(size) != len(data)-8 {
+		t.Errorf("RIFF size %d, file has %d bytes after the header", size, len(data)-8)
+	}
+
+	parsed, err := parseWebP(data)
+	if err != nil {
+		t.Fatalf("parseWebP: %v", err)
+	}
+	if len(parsed) != len(chunks) {
+		t.Fatalf("parsed %d chunks, want %d", len(parsed), len(chunks))
+	}
+	for i := range chunks {
+		if parsed[i].id != chunks[i].id || !bytes.Equal(parsed[i].data, chunks[i].data) {
+			t.Errorf("chunk %d is %s %q, want %s %q", i, parsed[i].id, parsed[i].data, chunks[i].id, chunks[i].data)
+		}
+	}
+}
+
+func TestWebPCanvas(t *testing.T) {
+	extended := writeWebP([]riffChunk{vp8xChunk(webpFlagAnimation, 512, 1<<24), {id: "ANIM", data: make([]byte, 6)}})
+
+	tests := []struct {
+		name                  string
+		data                  []byte
+		wantFlags             byte
+		wantWidth, wantHeight uint32
+		wantOK                bool
+	}{
+		{"extended", extended, webpFlagAnimation, 512, 1 << 24, true},
+		{"lossless", testWebP("solid", 30, 20), 0, 30, 20, true},
+		{"lossless with alpha", testWebP("transparent", 20, 30), webpFlagAlpha, 20, 30, true},
+		{"unknown bitstream", riffFile(16, "VP8L\x04\x00\x00\x001234"), 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		chunks, err := parseWebP(tt.data)
+		if err != nil {
+			t.Errorf("%s: parseWebP: %v", tt.name, err)
+			continue
+		}
+		flags, width, height, ok := webpCanvas(chunks, tt.data)
+		if flags != tt.wantFlags || width != tt.wantWidth || height != tt.wantHeight || ok != tt.wantOK {
+			t.Errorf("%s: webpCanvas = %#x %dx%d %v, want %#x %dx%d %v", tt.name, flags, width, height, ok,
+				tt.wantFlags, tt.wantWidth, tt.wantHeight, tt.wantOK)
+		}
+	}
+}
+
+func FuzzParseWebP(f *testing.F) {
+	f.Add(testWebP("gradient", 3, 2))
+	f.Add(writeWebP([]riffChunk{vp8xChunk(webpFlagAnimation, 512, 512), {id: "ANIM", data: make([]byte, 6)}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		chunks, err := parseWebP(data)
+		if err != nil {
+			return
+		}
+		webpCanvas(chunks, data)
+		// Whatever parses is written back chunk for chunk
+		rewritten, err := parseWebP(writeWebP(chunks))
+		if err != nil || len(rewritten) != len(chunks) {
+			t.Fatalf("rewritten file has %d chunks, %v, want %d", len(rewritten), err, len(chunks))
+		}
+		for i := range chunks {
+			if rewritten[i].id != chunks[i].id || !bytes.Equal(rewritten[i].data, chunks[i].data) {
+				t.Fatalf("rewritten chunk %d differs", i)
+			}
+		}
+	})
+}
@@ -11,6 +11,7 @@ package middleware
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"math/rand"
 	"net/http"
@@ -61,32 +62,46 @@ func NewRateLimiter(perMinute, jitterMinMS, jitterMaxMS int) *RateLimiter {
 
 func (rl *RateLimiter) Limit() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Read the body
-		bodyBytes, err := io.ReadAll(c.Request.Body)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "invalid_request",
-				"message": "failed to read request body",
-			})
-			c.Abort()
-			return
-		}
-
-		// Restore the body for the next handler
-		c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-
-		// Extract wa_account_id from JSON
 		var req struct {
 			WaAccountID string `json:"wa_account_id"`
 		}
 
-		if err := json.Unmarshal(bodyBytes, &req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "invalid_request",
-				"message": "invalid JSON format",
-			})
-			c.Abort()
-			return
+		if c.ContentType() == gin.MIMEMultipartPOSTForm {
+			// The parsed form is kept on the request for the handlers
+			req.WaAccountID = c.PostForm("wa_account_id")
+		} else {
+			// Read the body
+			bodyBytes, err := io.ReadAll(c.Request.Body)
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error":   "request_too_large",
+					"message": "request body is too large",
+				})
+				c.Abort()
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "invalid_request",
+					"message": "failed to read request body",
+				})
+				c.Abort()
+				return
+			}
+
+			// Restore the body for the next handler
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+
+			// Extract wa_account_id from JSON
+			if err := json.Unmarshal(bodyBytes, &req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "invalid_request",
+					"message": "invalid JSON format",
+				})
+				c.Abort()
+				return
+			}
 		}
 
 		if req.WaAccountID == "" {
@@ -102,7 +117,7 @@ func (rl *RateLimiter) Limit() gin.HandlerFunc {
 		c.Set("wa_account_id", req.WaAccountID)
 
 		// Check rate limit
-		if !rl.allow(req.WaAccountID) {
+		if !rl.Allow(req.WaAccountID) {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":       "rate_limit_exceeded",
 				"message":     "Too many messages sent. Please wait before sending more.",
@@ -122,7 +137,8 @@ func (rl *RateLimiter) Limit() gin.HandlerFunc {
 	}
 }
 
-func (rl *RateLimiter) allow(waAccountID string) bool {
+// Allow consumes a send token for the account, reporting whether the send is within the limit
+func (rl *RateLimiter) Allow(waAccountID string) bool {
 	rl.mu.RLock()
 	limit, exists := rl.limits[waAccountID]
 	rl.mu.RUnlock()
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// ValidateRequests rejects requests whose parameters or body don't match the
+// OpenAPI document before they reach the handlers. Routes missing from the
+// document are passed through untouched.
+func ValidateRequests(doc *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authentication is done by the handlers themselves
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+	// Multipart uploads are checked by the handlers, decoding them here would
+	// buffer the whole file and reject every media type without a body decoder
+	uploadOptions := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		ExcludeRequestBody: true,
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			if !errors.Is(err, routers.ErrPathNotFound) && !errors.Is(err, routers.ErrMethodNotAllowed) {
+				log.Warn().Err(err).Str("path", c.Request.URL.Path).Msg("Failed to match request against OpenAPI document")
+			}
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			input.Options = uploadOptions
+		}
+
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "invalid_request",
+				"message":    validationMessage(err),
+				"request_id": c.GetString("request_id"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// validationMessage turns a validation error into a short message naming the
+// offending parameter or body field
+func validationMessage(err error) string {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return err.Error()
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		field := strings.Join(schemaErr.JSONPointer(), ".")
+		prefix := "request body"
+		if reqErr.Parameter != nil {
+			prefix = reqErr.Parameter.In + " parameter " + reqErr.Parameter.Name
+		}
+		if field != "" {
+			return prefix + " field " + field + ": " + schemaErr.Reason
+		}
+		return prefix + ": " + schemaErr.Reason
+	}
+
+	parts := []string{}
+	if reqErr.Parameter != nil {
+		parts = append(parts, reqErr.Parameter.In+" parameter "+reqErr.Parameter.Name)
+	}
+	if reqErr.Reason != "" {
+		parts = append(parts, reqErr.Reason)
+	}
+	if reqErr.Err != nil && reqErr.Err.Error() != reqErr.Reason {
+		parts = append(parts, reqErr.Err.Error())
+	}
+	if len(parts) == 0 {
+		return reqErr.Error()
+	}
+	return strings.Join(parts, ": ")
+}
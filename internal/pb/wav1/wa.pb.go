@@ -0,0 +1,2821 @@
+// gRPC API mirroring the REST surface under /v1.
+//
+// Every call must carry an "authorization: Bearer <token>" metadata entry with
+// the same signed token the WebSocket API accepts; calls are only allowed for
+// the accounts the token grants.
+//
+// Regenerate internal/pb/wav1 with:
+//   protoc -I proto --go_out=. --go_opt=module=github.com/whatsapp-api/go-whatsapp-service \
+//     --go-grpc_out=. --go-grpc_opt=module=github.com/whatsapp-api/go-whatsapp-service \
+//     wa/v1/wa.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: wa/v1/wa.proto
+
+package wav1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_wa_v1_wa_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{0}
+}
+
+type AccountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountRequest) Reset() {
+	*x = AccountRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountRequest) ProtoMessage() {}
+
+func (x *AccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountRequest.ProtoReflect.Descriptor instead.
+func (*AccountRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AccountRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+type SessionStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Jid           string                 `protobuf:"bytes,3,opt,name=jid,proto3" json:"jid,omitempty"`
+	Connected     bool                   `protobuf:"varint,4,opt,name=connected,proto3" json:"connected,omitempty"`
+	LoggedIn      bool                   `protobuf:"varint,5,opt,name=logged_in,json=loggedIn,proto3" json:"logged_in,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionStatus) Reset() {
+	*x = SessionStatus{}
+	mi := &file_wa_v1_wa_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionStatus) ProtoMessage() {}
+
+func (x *SessionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionStatus.ProtoReflect.Descriptor instead.
+func (*SessionStatus) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SessionStatus) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *SessionStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SessionStatus) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+func (x *SessionStatus) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *SessionStatus) GetLoggedIn() bool {
+	if x != nil {
+		return x.LoggedIn
+	}
+	return false
+}
+
+type CallPolicy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	AutoReject    bool                   `protobuf:"varint,2,opt,name=auto_reject,json=autoReject,proto3" json:"auto_reject,omitempty"`
+	ReplyText     string                 `protobuf:"bytes,3,opt,name=reply_text,json=replyText,proto3" json:"reply_text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallPolicy) Reset() {
+	*x = CallPolicy{}
+	mi := &file_wa_v1_wa_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallPolicy) ProtoMessage() {}
+
+func (x *CallPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallPolicy.ProtoReflect.Descriptor instead.
+func (*CallPolicy) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CallPolicy) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *CallPolicy) GetAutoReject() bool {
+	if x != nil {
+		return x.AutoReject
+	}
+	return false
+}
+
+func (x *CallPolicy) GetReplyText() string {
+	if x != nil {
+		return x.ReplyText
+	}
+	return ""
+}
+
+// SendMessageRequest carries the same fields as POST /v1/messages
+type SendMessageRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	To          string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Type        string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Body        string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Image       *Media                 `protobuf:"bytes,5,opt,name=image,proto3" json:"image,omitempty"`
+	Video       *Media                 `protobuf:"bytes,6,opt,name=video,proto3" json:"video,omitempty"`
+	Audio       *Audio                 `protobuf:"bytes,7,opt,name=audio,proto3" json:"audio,omitempty"`
+	Document    *Document              `protobuf:"bytes,8,opt,name=document,proto3" json:"document,omitempty"`
+	Location    *Location              `protobuf:"bytes,9,opt,name=location,proto3" json:"location,omitempty"`
+	Contact     *Contact               `protobuf:"bytes,10,opt,name=contact,proto3" json:"contact,omitempty"`
+	Poll        *Poll                  `protobuf:"bytes,11,opt,name=poll,proto3" json:"poll,omitempty"`
+	Link        *Media                 `protobuf:"bytes,12,opt,name=link,proto3" json:"link,omitempty"`
+	ReplyTo     *ReplyTo               `protobuf:"bytes,13,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`
+	// JIDs or phone numbers, @<phone> tokens in the text are added
+	Mentions []string `protobuf:"bytes,14,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	// Media content, sent instead of the URL of the type specific object
+	MediaData []byte `protobuf:"bytes,15,opt,name=media_data,json=mediaData,proto3" json:"media_data,omitempty"`
+	// ID of media uploaded via POST /v1/media
+	MediaId  string   `protobuf:"bytes,16,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
+	Mime     string   `protobuf:"bytes,17,opt,name=mime,proto3" json:"mime,omitempty"`
+	FileName string   `protobuf:"bytes,18,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Sticker  *Sticker `protobuf:"bytes,19,opt,name=sticker,proto3" json:"sticker,omitempty"`
+	// Preview the first URL of text messages
+	LinkPreview bool `protobuf:"varint,20,opt,name=link_preview,json=linkPreview,proto3" json:"link_preview,omitempty"`
+	// Template filling in the body, caption or poll, in place of the type
+	Template string `protobuf:"bytes,21,opt,name=template,proto3" json:"template,omitempty"`
+	// Defaults to the latest version
+	TemplateVersion int32 `protobuf:"varint,22,opt,name=template_version,json=templateVersion,proto3" json:"template_version,omitempty"`
+	// Defaults to the default locale of the template
+	Locale        string            `protobuf:"bytes,23,opt,name=locale,proto3" json:"locale,omitempty"`
+	Variables     map[string]string `protobuf:"bytes,24,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessageRequest) Reset() {
+	*x = SendMessageRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageRequest) ProtoMessage() {}
+
+func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SendMessageRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetImage() *Media {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetVideo() *Media {
+	if x != nil {
+		return x.Video
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetAudio() *Audio {
+	if x != nil {
+		return x.Audio
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetDocument() *Document {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetContact() *Contact {
+	if x != nil {
+		return x.Contact
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetPoll() *Poll {
+	if x != nil {
+		return x.Poll
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetLink() *Media {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetReplyTo() *ReplyTo {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetMentions() []string {
+	if x != nil {
+		return x.Mentions
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetMediaData() []byte {
+	if x != nil {
+		return x.MediaData
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetMediaId() string {
+	if x != nil {
+		return x.MediaId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetMime() string {
+	if x != nil {
+		return x.Mime
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetSticker() *Sticker {
+	if x != nil {
+		return x.Sticker
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetLinkPreview() bool {
+	if x != nil {
+		return x.LinkPreview
+	}
+	return false
+}
+
+func (x *SendMessageRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetTemplateVersion() int32 {
+	if x != nil {
+		return x.TemplateVersion
+	}
+	return 0
+}
+
+func (x *SendMessageRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetVariables() map[string]string {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+// The message a message replies to
+type ReplyTo struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// Chat of the quoted message, defaults to the recipient
+	Chat string `protobuf:"bytes,2,opt,name=chat,proto3" json:"chat,omitempty"`
+	// Sender of the quoted message, looked up if empty
+	Participant   string `protobuf:"bytes,3,opt,name=participant,proto3" json:"participant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplyTo) Reset() {
+	*x = ReplyTo{}
+	mi := &file_wa_v1_wa_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplyTo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplyTo) ProtoMessage() {}
+
+func (x *ReplyTo) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplyTo.ProtoReflect.Descriptor instead.
+func (*ReplyTo) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReplyTo) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ReplyTo) GetChat() string {
+	if x != nil {
+		return x.Chat
+	}
+	return ""
+}
+
+func (x *ReplyTo) GetParticipant() string {
+	if x != nil {
+		return x.Participant
+	}
+	return ""
+}
+
+type Media struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Caption       string                 `protobuf:"bytes,2,opt,name=caption,proto3" json:"caption,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Media) Reset() {
+	*x = Media{}
+	mi := &file_wa_v1_wa_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Media) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Media) ProtoMessage() {}
+
+func (x *Media) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Media.ProtoReflect.Descriptor instead.
+func (*Media) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Media) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Media) GetCaption() string {
+	if x != nil {
+		return x.Caption
+	}
+	return ""
+}
+
+type Audio struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Ptt           bool                   `protobuf:"varint,2,opt,name=ptt,proto3" json:"ptt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Audio) Reset() {
+	*x = Audio{}
+	mi := &file_wa_v1_wa_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Audio) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Audio) ProtoMessage() {}
+
+func (x *Audio) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Audio.ProtoReflect.Descriptor instead.
+func (*Audio) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Audio) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Audio) GetPtt() bool {
+	if x != nil {
+		return x.Ptt
+	}
+	return false
+}
+
+type Sticker struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Sticker pack metadata embedded in the WebP file
+	PackName      string   `protobuf:"bytes,2,opt,name=pack_name,json=packName,proto3" json:"pack_name,omitempty"`
+	PackAuthor    string   `protobuf:"bytes,3,opt,name=pack_author,json=packAuthor,proto3" json:"pack_author,omitempty"`
+	Emojis        []string `protobuf:"bytes,4,rep,name=emojis,proto3" json:"emojis,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sticker) Reset() {
+	*x = Sticker{}
+	mi := &file_wa_v1_wa_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sticker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sticker) ProtoMessage() {}
+
+func (x *Sticker) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sticker.ProtoReflect.Descriptor instead.
+func (*Sticker) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Sticker) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Sticker) GetPackName() string {
+	if x != nil {
+		return x.PackName
+	}
+	return ""
+}
+
+func (x *Sticker) GetPackAuthor() string {
+	if x != nil {
+		return x.PackAuthor
+	}
+	return ""
+}
+
+func (x *Sticker) GetEmojis() []string {
+	if x != nil {
+		return x.Emojis
+	}
+	return nil
+}
+
+type Document struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	Mimetype      string                 `protobuf:"bytes,3,opt,name=mimetype,proto3" json:"mimetype,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Document) Reset() {
+	*x = Document{}
+	mi := &file_wa_v1_wa_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Document) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Document) ProtoMessage() {}
+
+func (x *Document) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Document.ProtoReflect.Descriptor instead.
+func (*Document) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Document) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Document) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Document) GetMimetype() string {
+	if x != nil {
+		return x.Mimetype
+	}
+	return ""
+}
+
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_wa_v1_wa_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Location) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Location) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Location) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Contact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Phones        []string               `protobuf:"bytes,2,rep,name=phones,proto3" json:"phones,omitempty"`
+	Org           string                 `protobuf:"bytes,3,opt,name=org,proto3" json:"org,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Contact) Reset() {
+	*x = Contact{}
+	mi := &file_wa_v1_wa_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Contact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contact) ProtoMessage() {}
+
+func (x *Contact) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contact.ProtoReflect.Descriptor instead.
+func (*Contact) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Contact) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Contact) GetPhones() []string {
+	if x != nil {
+		return x.Phones
+	}
+	return nil
+}
+
+func (x *Contact) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+type Poll struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Question      string                 `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	Options       []string               `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Poll) Reset() {
+	*x = Poll{}
+	mi := &file_wa_v1_wa_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Poll) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Poll) ProtoMessage() {}
+
+func (x *Poll) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Poll.ProtoReflect.Descriptor instead.
+func (*Poll) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Poll) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *Poll) GetOptions() []string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type SendMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessageResponse) Reset() {
+	*x = SendMessageResponse{}
+	mi := &file_wa_v1_wa_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageResponse) ProtoMessage() {}
+
+func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
+func (*SendMessageResponse) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SendMessageResponse) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *SendMessageResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type GetMessageStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMessageStatusRequest) Reset() {
+	*x = GetMessageStatusRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMessageStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMessageStatusRequest) ProtoMessage() {}
+
+func (x *GetMessageStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMessageStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetMessageStatusRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetMessageStatusRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *GetMessageStatusRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+type MessageStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Chat          string                 `protobuf:"bytes,2,opt,name=chat,proto3" json:"chat,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Recipients    []*RecipientStatus     `protobuf:"bytes,7,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageStatus) Reset() {
+	*x = MessageStatus{}
+	mi := &file_wa_v1_wa_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageStatus) ProtoMessage() {}
+
+func (x *MessageStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageStatus.ProtoReflect.Descriptor instead.
+func (*MessageStatus) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MessageStatus) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *MessageStatus) GetChat() string {
+	if x != nil {
+		return x.Chat
+	}
+	return ""
+}
+
+func (x *MessageStatus) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MessageStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MessageStatus) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *MessageStatus) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *MessageStatus) GetRecipients() []*RecipientStatus {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+type RecipientStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jid           string                 `protobuf:"bytes,1,opt,name=jid,proto3" json:"jid,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecipientStatus) Reset() {
+	*x = RecipientStatus{}
+	mi := &file_wa_v1_wa_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecipientStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecipientStatus) ProtoMessage() {}
+
+func (x *RecipientStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecipientStatus.ProtoReflect.Descriptor instead.
+func (*RecipientStatus) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RecipientStatus) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+func (x *RecipientStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RecipientStatus) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ChatPresenceRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Chat        string                 `protobuf:"bytes,2,opt,name=chat,proto3" json:"chat,omitempty"`
+	// typing, recording or paused
+	State         string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatPresenceRequest) Reset() {
+	*x = ChatPresenceRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatPresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatPresenceRequest) ProtoMessage() {}
+
+func (x *ChatPresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatPresenceRequest.ProtoReflect.Descriptor instead.
+func (*ChatPresenceRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ChatPresenceRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *ChatPresenceRequest) GetChat() string {
+	if x != nil {
+		return x.Chat
+	}
+	return ""
+}
+
+func (x *ChatPresenceRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type GroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	GroupJid      string                 `protobuf:"bytes,2,opt,name=group_jid,json=groupJid,proto3" json:"group_jid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupRequest) Reset() {
+	*x = GroupRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupRequest) ProtoMessage() {}
+
+func (x *GroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupRequest.ProtoReflect.Descriptor instead.
+func (*GroupRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GroupRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *GroupRequest) GetGroupJid() string {
+	if x != nil {
+		return x.GroupJid
+	}
+	return ""
+}
+
+type CreateGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Participants  []string               `protobuf:"bytes,3,rep,name=participants,proto3" json:"participants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGroupRequest) Reset() {
+	*x = CreateGroupRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGroupRequest) ProtoMessage() {}
+
+func (x *CreateGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGroupRequest.ProtoReflect.Descriptor instead.
+func (*CreateGroupRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateGroupRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *CreateGroupRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *CreateGroupRequest) GetParticipants() []string {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+type Group struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Jid              string                 `protobuf:"bytes,1,opt,name=jid,proto3" json:"jid,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Owner            string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Topic            string                 `protobuf:"bytes,4,opt,name=topic,proto3" json:"topic,omitempty"`
+	CreatedAt        int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ParticipantCount int32                  `protobuf:"varint,6,opt,name=participant_count,json=participantCount,proto3" json:"participant_count,omitempty"`
+	Participants     []*GroupParticipant    `protobuf:"bytes,7,rep,name=participants,proto3" json:"participants,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_wa_v1_wa_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Group) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Group) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Group) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Group) GetParticipantCount() int32 {
+	if x != nil {
+		return x.ParticipantCount
+	}
+	return 0
+}
+
+func (x *Group) GetParticipants() []*GroupParticipant {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+type GroupParticipant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jid           string                 `protobuf:"bytes,1,opt,name=jid,proto3" json:"jid,omitempty"`
+	IsAdmin       bool                   `protobuf:"varint,2,opt,name=is_admin,json=isAdmin,proto3" json:"is_admin,omitempty"`
+	IsSuperAdmin  bool                   `protobuf:"varint,3,opt,name=is_super_admin,json=isSuperAdmin,proto3" json:"is_super_admin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupParticipant) Reset() {
+	*x = GroupParticipant{}
+	mi := &file_wa_v1_wa_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupParticipant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupParticipant) ProtoMessage() {}
+
+func (x *GroupParticipant) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupParticipant.ProtoReflect.Descriptor instead.
+func (*GroupParticipant) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GroupParticipant) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+func (x *GroupParticipant) GetIsAdmin() bool {
+	if x != nil {
+		return x.IsAdmin
+	}
+	return false
+}
+
+func (x *GroupParticipant) GetIsSuperAdmin() bool {
+	if x != nil {
+		return x.IsSuperAdmin
+	}
+	return false
+}
+
+type ListGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*Group               `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResponse) Reset() {
+	*x = ListGroupsResponse{}
+	mi := &file_wa_v1_wa_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResponse) ProtoMessage() {}
+
+func (x *ListGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListGroupsResponse) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type MarkReadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Chat          string                 `protobuf:"bytes,2,opt,name=chat,proto3" json:"chat,omitempty"`
+	MessageIds    []string               `protobuf:"bytes,3,rep,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"`
+	Sender        string                 `protobuf:"bytes,4,opt,name=sender,proto3" json:"sender,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkReadRequest) Reset() {
+	*x = MarkReadRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkReadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkReadRequest) ProtoMessage() {}
+
+func (x *MarkReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkReadRequest.ProtoReflect.Descriptor instead.
+func (*MarkReadRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MarkReadRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *MarkReadRequest) GetChat() string {
+	if x != nil {
+		return x.Chat
+	}
+	return ""
+}
+
+func (x *MarkReadRequest) GetMessageIds() []string {
+	if x != nil {
+		return x.MessageIds
+	}
+	return nil
+}
+
+func (x *MarkReadRequest) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+type ChatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Chat          string                 `protobuf:"bytes,2,opt,name=chat,proto3" json:"chat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatRequest) Reset() {
+	*x = ChatRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatRequest) ProtoMessage() {}
+
+func (x *ChatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatRequest.ProtoReflect.Descriptor instead.
+func (*ChatRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ChatRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *ChatRequest) GetChat() string {
+	if x != nil {
+		return x.Chat
+	}
+	return ""
+}
+
+type ChatState struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Chat     string                 `protobuf:"bytes,1,opt,name=chat,proto3" json:"chat,omitempty"`
+	Archived bool                   `protobuf:"varint,2,opt,name=archived,proto3" json:"archived,omitempty"`
+	Pinned   bool                   `protobuf:"varint,3,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	Muted    bool                   `protobuf:"varint,4,opt,name=muted,proto3" json:"muted,omitempty"`
+	// Unix time, 0 while muted means muted indefinitely
+	MutedUntil    int64    `protobuf:"varint,5,opt,name=muted_until,json=mutedUntil,proto3" json:"muted_until,omitempty"`
+	Labels        []string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatState) Reset() {
+	*x = ChatState{}
+	mi := &file_wa_v1_wa_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatState) ProtoMessage() {}
+
+func (x *ChatState) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatState.ProtoReflect.Descriptor instead.
+func (*ChatState) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ChatState) GetChat() string {
+	if x != nil {
+		return x.Chat
+	}
+	return ""
+}
+
+func (x *ChatState) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+func (x *ChatState) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+func (x *ChatState) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+func (x *ChatState) GetMutedUntil() int64 {
+	if x != nil {
+		return x.MutedUntil
+	}
+	return 0
+}
+
+func (x *ChatState) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type ListContactsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Search        string                 `protobuf:"bytes,2,opt,name=search,proto3" json:"search,omitempty"`
+	ChangedSince  int64                  `protobuf:"varint,3,opt,name=changed_since,json=changedSince,proto3" json:"changed_since,omitempty"`
+	Page          int32                  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage       int32                  `protobuf:"varint,5,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContactsRequest) Reset() {
+	*x = ListContactsRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContactsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContactsRequest) ProtoMessage() {}
+
+func (x *ListContactsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContactsRequest.ProtoReflect.Descriptor instead.
+func (*ListContactsRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListContactsRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *ListContactsRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListContactsRequest) GetChangedSince() int64 {
+	if x != nil {
+		return x.ChangedSince
+	}
+	return 0
+}
+
+func (x *ListContactsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListContactsRequest) GetPerPage() int32 {
+	if x != nil {
+		return x.PerPage
+	}
+	return 0
+}
+
+type DirectoryContact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jid           string                 `protobuf:"bytes,1,opt,name=jid,proto3" json:"jid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	NameSource    string                 `protobuf:"bytes,3,opt,name=name_source,json=nameSource,proto3" json:"name_source,omitempty"`
+	FullName      string                 `protobuf:"bytes,4,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	FirstName     string                 `protobuf:"bytes,5,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	PushName      string                 `protobuf:"bytes,6,opt,name=push_name,json=pushName,proto3" json:"push_name,omitempty"`
+	BusinessName  string                 `protobuf:"bytes,7,opt,name=business_name,json=businessName,proto3" json:"business_name,omitempty"`
+	LastChangedAt int64                  `protobuf:"varint,8,opt,name=last_changed_at,json=lastChangedAt,proto3" json:"last_changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectoryContact) Reset() {
+	*x = DirectoryContact{}
+	mi := &file_wa_v1_wa_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectoryContact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectoryContact) ProtoMessage() {}
+
+func (x *DirectoryContact) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectoryContact.ProtoReflect.Descriptor instead.
+func (*DirectoryContact) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DirectoryContact) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+func (x *DirectoryContact) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DirectoryContact) GetNameSource() string {
+	if x != nil {
+		return x.NameSource
+	}
+	return ""
+}
+
+func (x *DirectoryContact) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *DirectoryContact) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *DirectoryContact) GetPushName() string {
+	if x != nil {
+		return x.PushName
+	}
+	return ""
+}
+
+func (x *DirectoryContact) GetBusinessName() string {
+	if x != nil {
+		return x.BusinessName
+	}
+	return ""
+}
+
+func (x *DirectoryContact) GetLastChangedAt() int64 {
+	if x != nil {
+		return x.LastChangedAt
+	}
+	return 0
+}
+
+type ListContactsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contacts      []*DirectoryContact    `protobuf:"bytes,1,rep,name=contacts,proto3" json:"contacts,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContactsResponse) Reset() {
+	*x = ListContactsResponse{}
+	mi := &file_wa_v1_wa_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContactsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContactsResponse) ProtoMessage() {}
+
+func (x *ListContactsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContactsResponse.ProtoReflect.Descriptor instead.
+func (*ListContactsResponse) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListContactsResponse) GetContacts() []*DirectoryContact {
+	if x != nil {
+		return x.Contacts
+	}
+	return nil
+}
+
+func (x *ListContactsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type CheckUserExistsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Phones        []string               `protobuf:"bytes,2,rep,name=phones,proto3" json:"phones,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckUserExistsRequest) Reset() {
+	*x = CheckUserExistsRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckUserExistsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckUserExistsRequest) ProtoMessage() {}
+
+func (x *CheckUserExistsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckUserExistsRequest.ProtoReflect.Descriptor instead.
+func (*CheckUserExistsRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CheckUserExistsRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *CheckUserExistsRequest) GetPhones() []string {
+	if x != nil {
+		return x.Phones
+	}
+	return nil
+}
+
+type UserExists struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phone         string                 `protobuf:"bytes,1,opt,name=phone,proto3" json:"phone,omitempty"`
+	Exists        bool                   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	Jid           string                 `protobuf:"bytes,3,opt,name=jid,proto3" json:"jid,omitempty"`
+	VerifiedName  string                 `protobuf:"bytes,4,opt,name=verified_name,json=verifiedName,proto3" json:"verified_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserExists) Reset() {
+	*x = UserExists{}
+	mi := &file_wa_v1_wa_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserExists) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserExists) ProtoMessage() {}
+
+func (x *UserExists) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserExists.ProtoReflect.Descriptor instead.
+func (*UserExists) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UserExists) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *UserExists) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
+
+func (x *UserExists) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+func (x *UserExists) GetVerifiedName() string {
+	if x != nil {
+		return x.VerifiedName
+	}
+	return ""
+}
+
+type CheckUserExistsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*UserExists          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckUserExistsResponse) Reset() {
+	*x = CheckUserExistsResponse{}
+	mi := &file_wa_v1_wa_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckUserExistsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckUserExistsResponse) ProtoMessage() {}
+
+func (x *CheckUserExistsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckUserExistsResponse.ProtoReflect.Descriptor instead.
+func (*CheckUserExistsResponse) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CheckUserExistsResponse) GetResults() []*UserExists {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetUserInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountId   string                 `protobuf:"bytes,1,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Jid           string                 `protobuf:"bytes,2,opt,name=jid,proto3" json:"jid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserInfoRequest) Reset() {
+	*x = GetUserInfoRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserInfoRequest) ProtoMessage() {}
+
+func (x *GetUserInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetUserInfoRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetUserInfoRequest) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *GetUserInfoRequest) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+type UserInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jid           string                 `protobuf:"bytes,1,opt,name=jid,proto3" json:"jid,omitempty"`
+	VerifiedName  string                 `protobuf:"bytes,2,opt,name=verified_name,json=verifiedName,proto3" json:"verified_name,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	PictureId     string                 `protobuf:"bytes,4,opt,name=picture_id,json=pictureId,proto3" json:"picture_id,omitempty"`
+	Devices       []string               `protobuf:"bytes,5,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserInfo) Reset() {
+	*x = UserInfo{}
+	mi := &file_wa_v1_wa_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserInfo) ProtoMessage() {}
+
+func (x *UserInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserInfo.ProtoReflect.Descriptor instead.
+func (*UserInfo) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UserInfo) GetJid() string {
+	if x != nil {
+		return x.Jid
+	}
+	return ""
+}
+
+func (x *UserInfo) GetVerifiedName() string {
+	if x != nil {
+		return x.VerifiedName
+	}
+	return ""
+}
+
+func (x *UserInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UserInfo) GetPictureId() string {
+	if x != nil {
+		return x.PictureId
+	}
+	return ""
+}
+
+func (x *UserInfo) GetDevices() []string {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type StreamEventsRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	WaAccountIds []string               `protobuf:"bytes,1,rep,name=wa_account_ids,json=waAccountIds,proto3" json:"wa_account_ids,omitempty"`
+	EventTypes   []string               `protobuf:"bytes,2,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	// Resume after this event ID from the in-memory buffer, 0 for live events only
+	LastEventId   uint64 `protobuf:"varint,3,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_wa_v1_wa_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *StreamEventsRequest) GetWaAccountIds() []string {
+	if x != nil {
+		return x.WaAccountIds
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	EventType     string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	WaAccountId   string                 `protobuf:"bytes,3,opt,name=wa_account_id,json=waAccountId,proto3" json:"wa_account_id,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Data          *structpb.Struct       `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	SchemaVersion int32                  `protobuf:"varint,6,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_wa_v1_wa_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_wa_v1_wa_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_wa_v1_wa_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *Event) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Event) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *Event) GetWaAccountId() string {
+	if x != nil {
+		return x.WaAccountId
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Event) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Event) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+var File_wa_v1_wa_proto protoreflect.FileDescriptor
+
+const file_wa_v1_wa_proto_rawDesc = "" +
+	"\n" +
+	"\x0ewa/v1/wa.proto\x12\x05wa.v1\x1a\x1cgoogle/protobuf/struct.proto\"\a\n" +
+	"\x05Empty\"4\n" +
+	"\x0eAccountRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\"\x98\x01\n" +
+	"\rSessionStatus\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x10\n" +
+	"\x03jid\x18\x03 \x01(\tR\x03jid\x12\x1c\n" +
+	"\tconnected\x18\x04 \x01(\bR\tconnected\x12\x1b\n" +
+	"\tlogged_in\x18\x05 \x01(\bR\bloggedIn\"p\n" +
+	"\n" +
+	"CallPolicy\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x1f\n" +
+	"\vauto_reject\x18\x02 \x01(\bR\n" +
+	"autoReject\x12\x1d\n" +
+	"\n" +
+	"reply_text\x18\x03 \x01(\tR\treplyText\"\x87\a\n" +
+	"\x12SendMessageRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\"\n" +
+	"\x05image\x18\x05 \x01(\v2\f.wa.v1.MediaR\x05image\x12\"\n" +
+	"\x05video\x18\x06 \x01(\v2\f.wa.v1.MediaR\x05video\x12\"\n" +
+	"\x05audio\x18\a \x01(\v2\f.wa.v1.AudioR\x05audio\x12+\n" +
+	"\bdocument\x18\b \x01(\v2\x0f.wa.v1.DocumentR\bdocument\x12+\n" +
+	"\blocation\x18\t \x01(\v2\x0f.wa.v1.LocationR\blocation\x12(\n" +
+	"\acontact\x18\n" +
+	" \x01(\v2\x0e.wa.v1.ContactR\acontact\x12\x1f\n" +
+	"\x04poll\x18\v \x01(\v2\v.wa.v1.PollR\x04poll\x12 \n" +
+	"\x04link\x18\f \x01(\v2\f.wa.v1.MediaR\x04link\x12)\n" +
+	"\breply_to\x18\r \x01(\v2\x0e.wa.v1.ReplyToR\areplyTo\x12\x1a\n" +
+	"\bmentions\x18\x0e \x03(\tR\bmentions\x12\x1d\n" +
+	"\n" +
+	"media_data\x18\x0f \x01(\fR\tmediaData\x12\x19\n" +
+	"\bmedia_id\x18\x10 \x01(\tR\amediaId\x12\x12\n" +
+	"\x04mime\x18\x11 \x01(\tR\x04mime\x12\x1b\n" +
+	"\tfile_name\x18\x12 \x01(\tR\bfileName\x12(\n" +
+	"\asticker\x18\x13 \x01(\v2\x0e.wa.v1.StickerR\asticker\x12!\n" +
+	"\flink_preview\x18\x14 \x01(\bR\vlinkPreview\x12\x1a\n" +
+	"\btemplate\x18\x15 \x01(\tR\btemplate\x12)\n" +
+	"\x10template_version\x18\x16 \x01(\x05R\x0ftemplateVersion\x12\x16\n" +
+	"\x06locale\x18\x17 \x01(\tR\x06locale\x12F\n" +
+	"\tvariables\x18\x18 \x03(\v2(.wa.v1.SendMessageRequest.VariablesEntryR\tvariables\x1a<\n" +
+	"\x0eVariablesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"^\n" +
+	"\aReplyTo\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x12\n" +
+	"\x04chat\x18\x02 \x01(\tR\x04chat\x12 \n" +
+	"\vparticipant\x18\x03 \x01(\tR\vparticipant\"3\n" +
+	"\x05Media\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x18\n" +
+	"\acaption\x18\x02 \x01(\tR\acaption\"+\n" +
+	"\x05Audio\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x10\n" +
+	"\x03ptt\x18\x02 \x01(\bR\x03ptt\"q\n" +
+	"\aSticker\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1b\n" +
+	"\tpack_name\x18\x02 \x01(\tR\bpackName\x12\x1f\n" +
+	"\vpack_author\x18\x03 \x01(\tR\n" +
+	"packAuthor\x12\x16\n" +
+	"\x06emojis\x18\x04 \x03(\tR\x06emojis\"T\n" +
+	"\bDocument\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1a\n" +
+	"\bmimetype\x18\x03 \x01(\tR\bmimetype\"X\n" +
+	"\bLocation\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"G\n" +
+	"\aContact\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06phones\x18\x02 \x03(\tR\x06phones\x12\x10\n" +
+	"\x03org\x18\x03 \x01(\tR\x03org\"<\n" +
+	"\x04Poll\x12\x1a\n" +
+	"\bquestion\x18\x01 \x01(\tR\bquestion\x12\x18\n" +
+	"\aoptions\x18\x02 \x03(\tR\aoptions\"R\n" +
+	"\x13SendMessageResponse\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"\\\n" +
+	"\x17GetMessageStatusRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\"\xe4\x01\n" +
+	"\rMessageStatus\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x12\n" +
+	"\x04chat\x18\x02 \x01(\tR\x04chat\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\x126\n" +
+	"\n" +
+	"recipients\x18\a \x03(\v2\x16.wa.v1.RecipientStatusR\n" +
+	"recipients\"Z\n" +
+	"\x0fRecipientStatus\x12\x10\n" +
+	"\x03jid\x18\x01 \x01(\tR\x03jid\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\x03R\tupdatedAt\"c\n" +
+	"\x13ChatPresenceRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x12\n" +
+	"\x04chat\x18\x02 \x01(\tR\x04chat\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\"O\n" +
+	"\fGroupRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x1b\n" +
+	"\tgroup_jid\x18\x02 \x01(\tR\bgroupJid\"v\n" +
+	"\x12CreateGroupRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x12\"\n" +
+	"\fparticipants\x18\x03 \x03(\tR\fparticipants\"\xe2\x01\n" +
+	"\x05Group\x12\x10\n" +
+	"\x03jid\x18\x01 \x01(\tR\x03jid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05topic\x18\x04 \x01(\tR\x05topic\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12+\n" +
+	"\x11participant_count\x18\x06 \x01(\x05R\x10participantCount\x12;\n" +
+	"\fparticipants\x18\a \x03(\v2\x17.wa.v1.GroupParticipantR\fparticipants\"e\n" +
+	"\x10GroupParticipant\x12\x10\n" +
+	"\x03jid\x18\x01 \x01(\tR\x03jid\x12\x19\n" +
+	"\bis_admin\x18\x02 \x01(\bR\aisAdmin\x12$\n" +
+	"\x0eis_super_admin\x18\x03 \x01(\bR\fisSuperAdmin\":\n" +
+	"\x12ListGroupsResponse\x12$\n" +
+	"\x06groups\x18\x01 \x03(\v2\f.wa.v1.GroupR\x06groups\"\x82\x01\n" +
+	"\x0fMarkReadRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x12\n" +
+	"\x04chat\x18\x02 \x01(\tR\x04chat\x12\x1f\n" +
+	"\vmessage_ids\x18\x03 \x03(\tR\n" +
+	"messageIds\x12\x16\n" +
+	"\x06sender\x18\x04 \x01(\tR\x06sender\"E\n" +
+	"\vChatRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x12\n" +
+	"\x04chat\x18\x02 \x01(\tR\x04chat\"\xa2\x01\n" +
+	"\tChatState\x12\x12\n" +
+	"\x04chat\x18\x01 \x01(\tR\x04chat\x12\x1a\n" +
+	"\barchived\x18\x02 \x01(\bR\barchived\x12\x16\n" +
+	"\x06pinned\x18\x03 \x01(\bR\x06pinned\x12\x14\n" +
+	"\x05muted\x18\x04 \x01(\bR\x05muted\x12\x1f\n" +
+	"\vmuted_until\x18\x05 \x01(\x03R\n" +
+	"mutedUntil\x12\x16\n" +
+	"\x06labels\x18\x06 \x03(\tR\x06labels\"\xa5\x01\n" +
+	"\x13ListContactsRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x16\n" +
+	"\x06search\x18\x02 \x01(\tR\x06search\x12#\n" +
+	"\rchanged_since\x18\x03 \x01(\x03R\fchangedSince\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x19\n" +
+	"\bper_page\x18\x05 \x01(\x05R\aperPage\"\xff\x01\n" +
+	"\x10DirectoryContact\x12\x10\n" +
+	"\x03jid\x18\x01 \x01(\tR\x03jid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vname_source\x18\x03 \x01(\tR\n" +
+	"nameSource\x12\x1b\n" +
+	"\tfull_name\x18\x04 \x01(\tR\bfullName\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x05 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tpush_name\x18\x06 \x01(\tR\bpushName\x12#\n" +
+	"\rbusiness_name\x18\a \x01(\tR\fbusinessName\x12&\n" +
+	"\x0flast_changed_at\x18\b \x01(\x03R\rlastChangedAt\"a\n" +
+	"\x14ListContactsResponse\x123\n" +
+	"\bcontacts\x18\x01 \x03(\v2\x17.wa.v1.DirectoryContactR\bcontacts\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"T\n" +
+	"\x16CheckUserExistsRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x16\n" +
+	"\x06phones\x18\x02 \x03(\tR\x06phones\"q\n" +
+	"\n" +
+	"UserExists\x12\x14\n" +
+	"\x05phone\x18\x01 \x01(\tR\x05phone\x12\x16\n" +
+	"\x06exists\x18\x02 \x01(\bR\x06exists\x12\x10\n" +
+	"\x03jid\x18\x03 \x01(\tR\x03jid\x12#\n" +
+	"\rverified_name\x18\x04 \x01(\tR\fverifiedName\"F\n" +
+	"\x17CheckUserExistsResponse\x12+\n" +
+	"\aresults\x18\x01 \x03(\v2\x11.wa.v1.UserExistsR\aresults\"J\n" +
+	"\x12GetUserInfoRequest\x12\"\n" +
+	"\rwa_account_id\x18\x01 \x01(\tR\vwaAccountId\x12\x10\n" +
+	"\x03jid\x18\x02 \x01(\tR\x03jid\"\x92\x01\n" +
+	"\bUserInfo\x12\x10\n" +
+	"\x03jid\x18\x01 \x01(\tR\x03jid\x12#\n" +
+	"\rverified_name\x18\x02 \x01(\tR\fverifiedName\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"picture_id\x18\x04 \x01(\tR\tpictureId\x12\x18\n" +
+	"\adevices\x18\x05 \x03(\tR\adevices\"\x80\x01\n" +
+	"\x13StreamEventsRequest\x12$\n" +
+	"\x0ewa_account_ids\x18\x01 \x03(\tR\fwaAccountIds\x12\x1f\n" +
+	"\vevent_types\x18\x02 \x03(\tR\n" +
+	"eventTypes\x12\"\n" +
+	"\rlast_event_id\x18\x03 \x01(\x04R\vlastEventId\"\xcc\x01\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\"\n" +
+	"\rwa_account_id\x18\x03 \x01(\tR\vwaAccountId\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12+\n" +
+	"\x04data\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x04data\x12%\n" +
+	"\x0eschema_version\x18\x06 \x01(\x05R\rschemaVersion2\x9d\x02\n" +
+	"\x0eSessionService\x128\n" +
+	"\tGetStatus\x12\x15.wa.v1.AccountRequest\x1a\x14.wa.v1.SessionStatus\x120\n" +
+	"\tReconnect\x12\x15.wa.v1.AccountRequest\x1a\f.wa.v1.Empty\x12-\n" +
+	"\x06Logout\x12\x15.wa.v1.AccountRequest\x1a\f.wa.v1.Empty\x129\n" +
+	"\rGetCallPolicy\x12\x15.wa.v1.AccountRequest\x1a\x11.wa.v1.CallPolicy\x125\n" +
+	"\rSetCallPolicy\x12\x11.wa.v1.CallPolicy\x1a\x11.wa.v1.CallPolicy2\xde\x01\n" +
+	"\x0eMessageService\x12D\n" +
+	"\vSendMessage\x12\x19.wa.v1.SendMessageRequest\x1a\x1a.wa.v1.SendMessageResponse\x12H\n" +
+	"\x10GetMessageStatus\x12\x1e.wa.v1.GetMessageStatusRequest\x1a\x14.wa.v1.MessageStatus\x12<\n" +
+	"\x10SendChatPresence\x12\x1a.wa.v1.ChatPresenceRequest\x1a\f.wa.v1.Empty2\xea\x01\n" +
+	"\fGroupService\x12>\n" +
+	"\n" +
+	"ListGroups\x12\x15.wa.v1.AccountRequest\x1a\x19.wa.v1.ListGroupsResponse\x121\n" +
+	"\fGetGroupInfo\x12\x13.wa.v1.GroupRequest\x1a\f.wa.v1.Group\x126\n" +
+	"\vCreateGroup\x12\x19.wa.v1.CreateGroupRequest\x1a\f.wa.v1.Group\x12/\n" +
+	"\n" +
+	"LeaveGroup\x12\x13.wa.v1.GroupRequest\x1a\f.wa.v1.Empty2u\n" +
+	"\vChatService\x120\n" +
+	"\bMarkRead\x12\x16.wa.v1.MarkReadRequest\x1a\f.wa.v1.Empty\x124\n" +
+	"\fGetChatState\x12\x12.wa.v1.ChatRequest\x1a\x10.wa.v1.ChatState2Y\n" +
+	"\x0eContactService\x12G\n" +
+	"\fListContacts\x12\x1a.wa.v1.ListContactsRequest\x1a\x1b.wa.v1.ListContactsResponse2\x9d\x01\n" +
+	"\x0eAccountService\x12P\n" +
+	"\x0fCheckUserExists\x12\x1d.wa.v1.CheckUserExistsRequest\x1a\x1e.wa.v1.CheckUserExistsResponse\x129\n" +
+	"\vGetUserInfo\x12\x19.wa.v1.GetUserInfoRequest\x1a\x0f.wa.v1.UserInfo2J\n" +
+	"\fEventService\x12:\n" +
+	"\fStreamEvents\x12\x1a.wa.v1.StreamEventsRequest\x1a\f.wa.v1.Event0\x01BCZAgithub.com/whatsapp-api/go-whatsapp-service/internal/pb/wav1;wav1b\x06proto3"
+
+var (
+	file_wa_v1_wa_proto_rawDescOnce sync.Once
+	file_wa_v1_wa_proto_rawDescData []byte
+)
+
+func file_wa_v1_wa_proto_rawDescGZIP() []byte {
+	file_wa_v1_wa_proto_rawDescOnce.Do(func() {
+		file_wa_v1_wa_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wa_v1_wa_proto_rawDesc), len(file_wa_v1_wa_proto_rawDesc)))
+	})
+	return file_wa_v1_wa_proto_rawDescData
+}
+
+var file_wa_v1_wa_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
+var file_wa_v1_wa_proto_goTypes = []any{
+	(*Empty)(nil),                   // 0: wa.v1.Empty
+	(*AccountRequest)(nil),          // 1: wa.v1.AccountRequest
+	(*SessionStatus)(nil),           // 2: wa.v1.SessionStatus
+	(*CallPolicy)(nil),              // 3: wa.v1.CallPolicy
+	(*SendMessageRequest)(nil),      // 4: wa.v1.SendMessageRequest
+	(*ReplyTo)(nil),                 // 5: wa.v1.ReplyTo
+	(*Media)(nil),                   // 6: wa.v1.Media
+	(*Audio)(nil),                   // 7: wa.v1.Audio
+	(*Sticker)(nil),                 // 8: wa.v1.Sticker
+	(*Document)(nil),                // 9: wa.v1.Document
+	(*Location)(nil),                // 10: wa.v1.Location
+	(*Contact)(nil),                 // 11: wa.v1.Contact
+	(*Poll)(nil),                    // 12: wa.v1.Poll
+	(*SendMessageResponse)(nil),     // 13: wa.v1.SendMessageResponse
+	(*GetMessageStatusRequest)(nil), // 14: wa.v1.GetMessageStatusRequest
+	(*MessageStatus)(nil),           // 15: wa.v1.MessageStatus
+	(*RecipientStatus)(nil),         // 16: wa.v1.RecipientStatus
+	(*ChatPresenceRequest)(nil),     // 17: wa.v1.ChatPresenceRequest
+	(*GroupRequest)(nil),            // 18: wa.v1.GroupRequest
+	(*CreateGroupRequest)(nil),      // 19: wa.v1.CreateGroupRequest
+	(*Group)(nil),                   // 20: wa.v1.Group
+	(*GroupParticipant)(nil),        // 21: wa.v1.GroupParticipant
+	(*ListGroupsResponse)(nil),      // 22: wa.v1.ListGroupsResponse
+	(*MarkReadRequest)(nil),         // 23: wa.v1.MarkReadRequest
+	(*ChatRequest)(nil),             // 24: wa.v1.ChatRequest
+	(*ChatState)(nil),               // 25: wa.v1.ChatState
+	(*ListContactsRequest)(nil),     // 26: wa.v1.ListContactsRequest
+	(*DirectoryContact)(nil),        // 27: wa.v1.DirectoryContact
+	(*ListContactsResponse)(nil),    // 28: wa.v1.ListContactsResponse
+	(*CheckUserExistsRequest)(nil),  // 29: wa.v1.CheckUserExistsRequest
+	(*UserExists)(nil),              // 30: wa.v1.UserExists
+	(*CheckUserExistsResponse)(nil), // 31: wa.v1.CheckUserExistsResponse
+	(*GetUserInfoRequest)(nil),      // 32: wa.v1.GetUserInfoRequest
+	(*UserInfo)(nil),                // 33: wa.v1.UserInfo
+	(*StreamEventsRequest)(nil),     // 34: wa.v1.StreamEventsRequest
+	(*Event)(nil),                   // 35: wa.v1.Event
+	nil,                             // 36: wa.v1.SendMessageRequest.VariablesEntry
+	(*structpb.Struct)(nil),         // 37: google.protobuf.Struct
+}
+var file_wa_v1_wa_proto_depIdxs = []int32{
+	6,  // 0: wa.v1.SendMessageRequest.image:type_name -> wa.v1.Media
+	6,  // 1: wa.v1.SendMessageRequest.video:type_name -> wa.v1.Media
+	7,  // 2: wa.v1.SendMessageRequest.audio:type_name -> wa.v1.Audio
+	9,  // 3: wa.v1.SendMessageRequest.document:type_name -> wa.v1.Document
+	10, // 4: wa.v1.SendMessageRequest.location:type_name -> wa.v1.Location
+	11, // 5: wa.v1.SendMessageRequest.contact:type_name -> wa.v1.Contact
+	12, // 6: wa.v1.SendMessageRequest.poll:type_name -> wa.v1.Poll
+	6,  // 7: wa.v1.SendMessageRequest.link:type_name -> wa.v1.Media
+	5,  // 8: wa.v1.SendMessageRequest.reply_to:type_name -> wa.v1.ReplyTo
+	8,  // 9: wa.v1.SendMessageRequest.sticker:type_name -> wa.v1.Sticker
+	36, // 10: wa.v1.SendMessageRequest.variables:type_name -> wa.v1.SendMessageRequest.VariablesEntry
+	16, // 11: wa.v1.MessageStatus.recipients:type_name -> wa.v1.RecipientStatus
+	21, // 12: wa.v1.Group.participants:type_name -> wa.v1.GroupParticipant
+	20, // 13: wa.v1.ListGroupsResponse.groups:type_name -> wa.v1.Group
+	27, // 14: wa.v1.ListContactsResponse.contacts:type_name -> wa.v1.DirectoryContact
+	30, // 15: wa.v1.CheckUserExistsResponse.results:type_name -> wa.v1.UserExists
+	37, // 16: wa.v1.Event.data:type_name -> google.protobuf.Struct
+	1,  // 17: wa.v1.SessionService.GetStatus:input_type -> wa.v1.AccountRequest
+	1,  // 18: wa.v1.SessionService.Reconnect:input_type -> wa.v1.AccountRequest
+	1,  // 19: wa.v1.SessionService.Logout:input_type -> wa.v1.AccountRequest
+	1,  // 20: wa.v1.SessionService.GetCallPolicy:input_type -> wa.v1.AccountRequest
+	3,  // 21: wa.v1.SessionService.SetCallPolicy:input_type -> wa.v1.CallPolicy
+	4,  // 22: wa.v1.MessageService.SendMessage:input_type -> wa.v1.SendMessageRequest
+	14, // 23: wa.v1.MessageService.GetMessageStatus:input_type -> wa.v1.GetMessageStatusRequest
+	17, // 24: wa.v1.MessageService.SendChatPresence:input_type -> wa.v1.ChatPresenceRequest
+	1,  // 25: wa.v1.GroupService.ListGroups:input_type -> wa.v1.AccountRequest
+	18, // 26: wa.v1.GroupService.GetGroupInfo:input_type -> wa.v1.GroupRequest
+	19, // 27: wa.v1.GroupService.CreateGroup:input_type -> wa.v1.CreateGroupRequest
+	18, // 28: wa.v1.GroupService.LeaveGroup:input_type -> wa.v1.GroupRequest
+	23, // 29: wa.v1.ChatService.MarkRead:input_type -> wa.v1.MarkReadRequest
+	24, // 30: wa.v1.ChatService.GetChatState:input_type -> wa.v1.ChatRequest
+	26, // 31: wa.v1.ContactService.ListContacts:input_type -> wa.v1.ListContactsRequest
+	29, // 32: wa.v1.AccountService.CheckUserExists:input_type -> wa.v1.CheckUserExistsRequest
+	32, // 33: wa.v1.AccountService.GetUserInfo:input_type -> wa.v1.GetUserInfoRequest
+	34, // 34: wa.v1.EventService.StreamEvents:input_type -> wa.v1.StreamEventsRequest
+	2,  // 35: wa.v1.SessionService.GetStatus:output_type -> wa.v1.SessionStatus
+	0,  // 36: wa.v1.SessionService.Reconnect:output_type -> wa.v1.Empty
+	0,  // 37: wa.v1.SessionService.Logout:output_type -> wa.v1.Empty
+	3,  // 38: wa.v1.SessionService.GetCallPolicy:output_type -> wa.v1.CallPolicy
+	3,  // 39: wa.v1.SessionService.SetCallPolicy:output_type -> wa.v1.CallPolicy
+	13, // 40: wa.v1.MessageService.SendMessage:output_type -> wa.v1.SendMessageResponse
+	15, // 41: wa.v1.MessageService.GetMessageStatus:output_type -> wa.v1.MessageStatus
+	0,  // 42: wa.v1.MessageService.SendChatPresence:output_type -> wa.v1.Empty
+	22, // 43: wa.v1.GroupService.ListGroups:output_type -> wa.v1.ListGroupsResponse
+	20, // 44: wa.v1.GroupService.GetGroupInfo:output_type -> wa.v1.Group
+	20, // 45: wa.v1.GroupService.CreateGroup:output_type -> wa.v1.Group
+	0,  // 46: wa.v1.GroupService.LeaveGroup:output_type -> wa.v1.Empty
+	0,  // 47: wa.v1.ChatService.MarkRead:output_type -> wa.v1.Empty
+	25, // 48: wa.v1.ChatService.GetChatState:output_type -> wa.v1.ChatState
+	28, // 49: wa.v1.ContactService.ListContacts:output_type -> wa.v1.ListContactsResponse
+	31, // 50: wa.v1.AccountService.CheckUserExists:output_type -> wa.v1.CheckUserExistsResponse
+	33, // 51: wa.v1.AccountService.GetUserInfo:output_type -> wa.v1.UserInfo
+	35, // 52: wa.v1.EventService.StreamEvents:output_type -> wa.v1.Event
+	35, // [35:53] is the sub-list for method output_type
+	17, // [17:35] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_wa_v1_wa_proto_init() }
+func file_wa_v1_wa_proto_init() {
+	if File_wa_v1_wa_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wa_v1_wa_proto_rawDesc), len(file_wa_v1_wa_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   37,
+			NumExtensions: 0,
+			NumServices:   7,
+		},
+		GoTypes:           file_wa_v1_wa_proto_goTypes,
+		DependencyIndexes: file_wa_v1_wa_proto_depIdxs,
+		MessageInfos:      file_wa_v1_wa_proto_msgTypes,
+	}.Build()
+	File_wa_v1_wa_proto = out.File
+	file_wa_v1_wa_proto_goTypes = nil
+	file_wa_v1_wa_proto_depIdxs = nil
+}
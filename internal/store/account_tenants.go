@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetAccountTenant returns the tenant an account is assigned to, or "" if none
+func (s *PostgresStore) GetAccountTenant(waAccountID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var tenantID string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT tenant_id FROM wa_account_tenants WHERE wa_account_id = $1`, waAccountID,
+	).Scan(&tenantID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get account tenant: %w", err)
+	}
+
+	return tenantID, nil
+}
+
+// SetAccountTenant assigns an account to a tenant, an empty tenant removes
+// the assignment
+func (s *PostgresStore) SetAccountTenant(waAccountID, tenantID string) error {
+	var err error
+	if tenantID == "" {
+		_, err = s.Exec(`DELETE FROM wa_account_tenants WHERE wa_account_id = $1`, waAccountID)
+	} else {
+		_, err = s.Exec(`
+			INSERT INTO wa_account_tenants (wa_account_id, tenant_id, updated_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (wa_account_id) DO UPDATE SET tenant_id = $2, updated_at = NOW()
+		`, waAccountID, tenantID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set account tenant: %w", err)
+	}
+
+	return nil
+}
+
+// ListAccountTenants returns the tenant of every account assigned to one
+func (s *PostgresStore) ListAccountTenants() (map[string]string, error) {
+	// Query cancels its context on return, so rows are read under a context we own
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT wa_account_id, tenant_id FROM wa_account_tenants`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := make(map[string]string)
+	for rows.Next() {
+		var waAccountID, tenantID string
+		if err := rows.Scan(&waAccountID, &tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan account tenant: %w", err)
+		}
+		tenants[waAccountID] = tenantID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read account tenants: %w", err)
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CallPolicy controls how incoming calls are handled for an account
+type CallPolicy struct {
+	WaAccountID string
+	AutoReject  bool
+	ReplyText   string
+	UpdatedAt   time.Time
+}
+
+// GetCallPolicy returns the stored call policy for an account, or nil if none has been saved
+func (s *PostgresStore) GetCallPolicy(waAccountID string) (*CallPolicy, error) {
+	// QueryRow cancels its context before Scan runs, so use a context we own
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	policy := &CallPolicy{WaAccountID: waAccountID}
+	query := `SELECT auto_reject, reply_text, updated_at FROM wa_call_policies WHERE wa_account_id = $1`
+
+	err := s.db.QueryRowContext(ctx, query, waAccountID).Scan(&policy.AutoReject, &policy.ReplyText, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get call policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (s *PostgresStore) SaveCallPolicy(policy *CallPolicy) error {
+	query := `
+		INSERT INTO wa_call_policies (wa_account_id, auto_reject, reply_text, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (wa_account_id)
+		DO UPDATE SET auto_reject = $2, reply_text = $3, updated_at = NOW()
+	`
+
+	_, err := s.Exec(query, policy.WaAccountID, policy.AutoReject, policy.ReplyText)
+	if err != nil {
+		return fmt.Errorf("failed to save call policy: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ChatState is the current archive, pin, mute and label state of a chat
+type ChatState struct {
+	ChatJID    string
+	Archived   bool
+	Pinned     bool
+	Muted      bool
+	MutedUntil *time.Time // nil while muted means muted indefinitely
+	Labels     []string
+	UpdatedAt  time.Time
+}
+
+// ChatStateUpdate holds the state fields to change; nil fields are left untouched.
+// MutedUntil is only applied together with Muted.
+type ChatStateUpdate struct {
+	Archived   *bool
+	Pinned     *bool
+	Muted      *bool
+	MutedUntil *time.Time
+}
+
+// UpdateChatState applies a state update to a chat and reports whether anything
+// changed; unchanged updates are not written
+func (s *PostgresStore) UpdateChatState(waAccountID, chatJID string, update ChatStateUpdate) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO wa_chats (wa_account_id, chat_jid, archived, pinned, muted, muted_until, updated_at)
+		VALUES ($1, $2, COALESCE($3, FALSE), COALESCE($4, FALSE), COALESCE($5, FALSE), $6, NOW())
+		ON CONFLICT (wa_account_id, chat_jid) DO UPDATE SET
+			archived = COALESCE($3, wa_chats.archived),
+			pinned = COALESCE($4, wa_chats.pinned),
+			muted = COALESCE($5, wa_chats.muted),
+			muted_until = CASE WHEN $5::boolean IS NULL THEN wa_chats.muted_until ELSE $6::timestamptz END,
+			updated_at = NOW()
+		WHERE (COALESCE($3, wa_chats.archived), COALESCE($4, wa_chats.pinned), COALESCE($5, wa_chats.muted),
+			CASE WHEN $5::boolean IS NULL THEN wa_chats.muted_until ELSE $6::timestamptz END)
+			IS DISTINCT FROM
+			(wa_chats.archived, wa_chats.pinned, wa_chats.muted, wa_chats.muted_until)
+	`, waAccountID, chatJID, update.Archived, update.Pinned, update.Muted, update.MutedUntil)
+	if err != nil {
+		return false, fmt.Errorf("failed to update chat state: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update chat state: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetChatState returns the stored state of a chat, or nil if the chat is unknown
+func (s *PostgresStore) GetChatState(waAccountID, chatJID string) (*ChatState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state := &ChatState{ChatJID: chatJID, Labels: []string{}}
+	var mutedUntil sql.NullTime
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT archived, pinned, muted, muted_until, updated_at
+		FROM wa_chats WHERE wa_account_id = $1 AND chat_jid = $2
+	`, waAccountID, chatJID).Scan(&state.Archived, &state.Pinned, &state.Muted, &mutedUntil, &state.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat state: %w", err)
+	}
+
+	if mutedUntil.Valid {
+		state.MutedUntil = &mutedUntil.Time
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT label_id FROM wa_chat_labels WHERE wa_account_id = $1 AND chat_jid = $2 ORDER BY label_id
+	`, waAccountID, chatJID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat labels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var labelID string
+		if err := rows.Scan(&labelID); err != nil {
+			return nil, fmt.Errorf("failed to scan chat label: %w", err)
+		}
+		state.Labels = append(state.Labels, labelID)
+	}
+
+	return state, rows.Err()
+}
+
+// SetChatLabel adds or removes a label from a chat and reports whether anything changed
+func (s *PostgresStore) SetChatLabel(waAccountID, chatJID, labelID string, labeled bool) (bool, error) {
+	if labeled {
+		return s.execChanged("set chat label", `
+			INSERT INTO wa_chat_labels (wa_account_id, chat_jid, label_id) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`, waAccountID, chatJID, labelID)
+	}
+
+	return s.execChanged("set chat label", `
+		DELETE FROM wa_chat_labels WHERE wa_account_id = $1 AND chat_jid = $2 AND label_id = $3
+	`, waAccountID, chatJID, labelID)
+}
+
+// SetMessageLabel adds or removes a label from a message and reports whether anything changed
+func (s *PostgresStore) SetMessageLabel(waAccountID, chatJID, messageID, labelID string, labeled bool) (bool, error) {
+	if labeled {
+		return s.execChanged("set message label", `
+			INSERT INTO wa_message_labels (wa_account_id, chat_jid, message_id, label_id) VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, waAccountID, chatJID, messageID, labelID)
+	}
+
+	return s.execChanged("set message label", `
+		DELETE FROM wa_message_labels
+		WHERE wa_account_id = $1 AND chat_jid = $2 AND message_id = $3 AND label_id = $4
+	`, waAccountID, chatJID, messageID, labelID)
+}
+
+// SetMessageStarred stars or unstars a message and reports whether anything changed
+func (s *PostgresStore) SetMessageStarred(waAccountID, chatJID, messageID, senderJID string, fromMe, starred bool) (bool, error) {
+	if starred {
+		return s.execChanged("set message star", `
+			INSERT INTO wa_starred_messages (wa_account_id, chat_jid, message_id, sender_jid, from_me)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT DO NOTHING
+		`, waAccountID, chatJID, messageID, senderJID, fromMe)
+	}
+
+	return s.execChanged("set message star", `
+		DELETE FROM wa_starred_messages WHERE wa_account_id = $1 AND chat_jid = $2 AND message_id = $3
+	`, waAccountID, chatJID, messageID)
+}
+
+// SaveLabel stores a label definition and reports whether anything changed
+func (s *PostgresStore) SaveLabel(waAccountID, labelID, name string, color int, deleted bool) (bool, error) {
+	return s.execChanged("save label", `
+		INSERT INTO wa_labels (wa_account_id, label_id, name, color, deleted, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (wa_account_id, label_id) DO UPDATE SET
+			name = EXCLUDED.name, color = EXCLUDED.color, deleted = EXCLUDED.deleted, updated_at = NOW()
+		WHERE (EXCLUDED.name, EXCLUDED.color, EXCLUDED.deleted)
+			IS DISTINCT FROM (wa_labels.name, wa_labels.color, wa_labels.deleted)
+	`, waAccountID, labelID, name, color, deleted)
+}
+
+// execChanged runs a write and reports whether it affected any rows
+func (s *PostgresStore) execChanged(op, query string, args ...interface{}) (bool, error) {
+	result, err := s.Exec(query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to %s: %w", op, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to %s: %w", op, err)
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Name sources, in order of precedence for a contact's display name
+const (
+	NameSourceAddressBook = "address_book"
+	NameSourceBusiness    = "business"
+	NameSourcePushName    = "push_name"
+)
+
+// DirectoryContact is a cached contact entry. Rows are only written when a
+// name actually changes, so LastChangedAt is the time of the last change.
+type DirectoryContact struct {
+	JID           string
+	Name          string
+	NameSource    string
+	FullName      string
+	FirstName     string
+	PushName      string
+	BusinessName  string
+	LastChangedAt time.Time
+}
+
+// ContactNameUpdate holds the name fields to change; nil fields are left untouched
+type ContactNameUpdate struct {
+	FullName     *string
+	FirstName    *string
+	PushName     *string
+	BusinessName *string
+}
+
+func (c *DirectoryContact) resolveName() {
+	switch {
+	case c.FullName != "":
+		c.Name, c.NameSource = c.FullName, NameSourceAddressBook
+	case c.BusinessName != "":
+		c.Name, c.NameSource = c.BusinessName, NameSourceBusiness
+	case c.PushName != "":
+		c.Name, c.NameSource = c.PushName, NameSourcePushName
+	default:
+		c.Name, c.NameSource = "", ""
+	}
+}
+
+// UpdateContactNames applies a name update to the contact directory. It returns
+// the resulting contact and whether anything changed; unchanged updates are not written.
+func (s *PostgresStore) UpdateContactNames(waAccountID, jid string, update ContactNameUpdate) (*DirectoryContact, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contact := &DirectoryContact{JID: jid}
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_contacts (wa_account_id, jid, full_name, first_name, push_name, business_name, updated_at)
+		VALUES ($1, $2, COALESCE($3, ''), COALESCE($4, ''), COALESCE($5, ''), COALESCE($6, ''), NOW())
+		ON CONFLICT (wa_account_id, jid) DO UPDATE SET
+			full_name = COALESCE($3, wa_contacts.full_name),
+			first_name = COALESCE($4, wa_contacts.first_name),
+			push_name = COALESCE($5, wa_contacts.push_name),
+			business_name = COALESCE($6, wa_contacts.business_name),
+			updated_at = NOW()
+		WHERE (COALESCE($3, wa_contacts.full_name), COALESCE($4, wa_contacts.first_name),
+			COALESCE($5, wa_contacts.push_name), COALESCE($6, wa_contacts.business_name))
+			IS DISTINCT FROM
+			(wa_contacts.full_name, wa_contacts.first_name, wa_contacts.push_name, wa_contacts.business_name)
+		RETURNING full_name, first_name, push_name, business_name, updated_at
+	`, waAccountID, jid, update.FullName, update.FirstName, update.PushName, update.BusinessName).Scan(
+		&contact.FullName, &contact.FirstName, &contact.PushName, &contact.BusinessName, &contact.LastChangedAt)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to update contact: %w", err)
+	}
+
+	contact.resolveName()
+	return contact, true, nil
+}
+
+// ListDirectoryContacts returns a page of the contact directory, most recently
+// changed first, along with the total number of matching contacts
+func (s *PostgresStore) ListDirectoryContacts(waAccountID, search string, changedSince time.Time, limit, offset int) ([]DirectoryContact, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	where := `wa_account_id = $1 AND updated_at >= $2 AND ($3 = '' OR
+		jid ILIKE $3 ESCAPE '\' OR full_name ILIKE $3 ESCAPE '\' OR
+		push_name ILIKE $3 ESCAPE '\' OR business_name ILIKE $3 ESCAPE '\')`
+	pattern := containsPattern(search)
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM wa_contacts WHERE `+where,
+		waAccountID, changedSince, pattern).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count contacts: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT jid, full_name, first_name, push_name, business_name, updated_at
+		FROM wa_contacts WHERE `+where+`
+		ORDER BY updated_at DESC, jid LIMIT $4 OFFSET $5
+	`, waAccountID, changedSince, pattern, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	defer rows.Close()
+
+	contacts := []DirectoryContact{}
+	for rows.Next() {
+		var c DirectoryContact
+		if err := rows.Scan(&c.JID, &c.FullName, &c.FirstName, &c.PushName, &c.BusinessName, &c.LastChangedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan contact: %w", err)
+		}
+		c.resolveName()
+		contacts = append(contacts, c)
+	}
+
+	return contacts, total, rows.Err()
+}
+
+// containsPattern returns the LIKE pattern matching values containing search
+// literally, or "" for an empty search
+func containsPattern(search string) string {
+	if search == "" {
+		return ""
+	}
+	return "%" + likeEscaper.Replace(search) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
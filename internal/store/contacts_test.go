@@ -0,0 +1,22 @@
+package store
+
+import "testing"
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{"", ""},
+		{"ana", "%ana%"},
+		{"100%", `%100\%%`},
+		{"first_name", `%first\_name%`},
+		{`a\b`, `%a\\b%`},
+	}
+
+	for _, tt := range tests {
+		if got := containsPattern(tt.search); got != tt.want {
+			t.Errorf("containsPattern(%q) = %q, want %q", tt.search, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type DecryptFailure struct {
+	MessageID       string
+	ChatJID         string
+	SenderJID       string
+	Unavailable     bool
+	UnavailableType string
+	FailMode        string
+	Failures        int
+	FailedAt        time.Time
+	RecoveredAt     *time.Time
+}
+
+// RecordDecryptFailure stores a failed decryption and returns how many times
+// decrypting this message has failed so far
+func (s *PostgresStore) RecordDecryptFailure(waAccountID string, failure *DecryptFailure) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var failures int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_decrypt_failures (wa_account_id, message_id, chat_jid, sender_jid, unavailable, unavailable_type, fail_mode, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (wa_account_id, message_id) DO UPDATE SET
+			failures = wa_decrypt_failures.failures + 1,
+			unavailable = EXCLUDED.unavailable,
+			unavailable_type = EXCLUDED.unavailable_type,
+			fail_mode = EXCLUDED.fail_mode,
+			failed_at = EXCLUDED.failed_at,
+			recovered_at = NULL
+		RETURNING failures
+	`, waAccountID, failure.MessageID, failure.ChatJID, failure.SenderJID, failure.Unavailable,
+		failure.UnavailableType, failure.FailMode, failure.FailedAt).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record decrypt failure: %w", err)
+	}
+
+	return failures, nil
+}
+
+// MarkDecryptRecovered marks an earlier decrypt failure as recovered. It returns
+// the failure, or nil if the message had no outstanding failure.
+func (s *PostgresStore) MarkDecryptRecovered(waAccountID, messageID string) (*DecryptFailure, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	failure := &DecryptFailure{MessageID: messageID}
+	var recoveredAt time.Time
+
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE wa_decrypt_failures SET recovered_at = NOW()
+		WHERE wa_account_id = $1 AND message_id = $2 AND recovered_at IS NULL
+		RETURNING chat_jid, sender_jid, unavailable, unavailable_type, fail_mode, failures, failed_at, recovered_at
+	`, waAccountID, messageID).Scan(&failure.ChatJID, &failure.SenderJID, &failure.Unavailable,
+		&failure.UnavailableType, &failure.FailMode, &failure.Failures, &failure.FailedAt, &recoveredAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark decrypt recovery: %w", err)
+	}
+
+	failure.RecoveredAt = &recoveredAt
+	return failure, nil
+}
+
+func (s *PostgresStore) RecordIdentityChange(waAccountID, jid string, implicit bool, changedAt time.Time) error {
+	_, err := s.Exec(`
+		INSERT INTO wa_identity_changes (wa_account_id, jid, implicit, changed_at) VALUES ($1, $2, $3, $4)
+	`, waAccountID, jid, implicit, changedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record identity change: %w", err)
+	}
+
+	return nil
+}
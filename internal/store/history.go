@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type HistoryConversation struct {
+	ChatJID       string
+	Name          string
+	UnreadCount   int
+	Archived      bool
+	Pinned        bool
+	Muted         bool
+	MutedUntil    *time.Time
+	LastMessageAt *time.Time
+}
+
+type HistoryMessage struct {
+	ChatJID     string
+	MessageID   string
+	SenderJID   string
+	FromMe      bool
+	MessageType string
+	Text        string
+	SentAt      time.Time
+}
+
+// HistorySyncChunk is one decoded history sync blob
+type HistorySyncChunk struct {
+	WaAccountID   string
+	SyncType      string
+	ChunkOrder    int
+	Progress      int
+	Conversations []HistoryConversation
+	Messages      []HistoryMessage
+	PushNames     map[string]string // JID -> push name
+}
+
+// HistorySyncProgress is the running total of a history sync of one type
+type HistorySyncProgress struct {
+	SyncType      string
+	Progress      int
+	Chunks        int
+	Conversations int
+	Messages      int
+	UpdatedAt     time.Time
+}
+
+// SaveHistorySyncChunk stores the conversations, messages and push names of a
+// chunk in a single transaction and returns the updated progress totals.
+// Totals only count chunks, conversations and messages stored for the first
+// time, so redelivered chunks leave them as they are.
+func (s *PostgresStore) SaveHistorySyncChunk(chunk *HistorySyncChunk) (*HistorySyncProgress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	chunkResult, err := tx.ExecContext(ctx, `
+		INSERT INTO wa_history_sync_chunks (wa_account_id, sync_type, chunk_order)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (wa_account_id, sync_type, chunk_order) DO NOTHING
+	`, chunk.WaAccountID, chunk.SyncType, chunk.ChunkOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record history sync chunk: %w", err)
+	}
+	newChunks, err := chunkResult.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record history sync chunk: %w", err)
+	}
+
+	// Archived, pinned and muted are only taken for new chats, history syncs
+	// can arrive after app state updates and would revert them. xmax is 0 for
+	// rows inserted rather than updated by the upsert.
+	convStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO wa_chats (wa_account_id, chat_jid, name, unread_count, archived, pinned, muted, muted_until, last_message_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		ON CONFLICT (wa_account_id, chat_jid) DO UPDATE SET
+			name = CASE WHEN EXCLUDED.name <> '' THEN EXCLUDED.name ELSE wa_chats.name END,
+			unread_count = EXCLUDED.unread_count,
+			last_message_at = GREATEST(wa_chats.last_message_at, EXCLUDED.last_message_at),
+			updated_at = NOW()
+		RETURNING xmax = 0
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare conversation insert: %w", err)
+	}
+	defer convStmt.Close()
+
+	newConversations := 0
+	for _, conv := range chunk.Conversations {
+		var inserted bool
+		if err := convStmt.QueryRowContext(ctx, chunk.WaAccountID, conv.ChatJID, conv.Name, conv.UnreadCount,
+			conv.Archived, conv.Pinned, conv.Muted, conv.MutedUntil, conv.LastMessageAt).Scan(&inserted); err != nil {
+			return nil, fmt.Errorf("failed to store conversation %s: %w", conv.ChatJID, err)
+		}
+		if inserted {
+			newConversations++
+		}
+	}
+
+	msgStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO wa_messages (wa_account_id, chat_jid, message_id, sender_jid, from_me, message_type, text, sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (wa_account_id, chat_jid, message_id) DO NOTHING
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare message insert: %w", err)
+	}
+	defer msgStmt.Close()
+
+	var newMessages int64
+	for _, msg := range chunk.Messages {
+		result, err := msgStmt.ExecContext(ctx, chunk.WaAccountID, msg.ChatJID, msg.MessageID, msg.SenderJID,
+			msg.FromMe, msg.MessageType, msg.Text, msg.SentAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store message %s: %w", msg.MessageID, err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to store message %s: %w", msg.MessageID, err)
+		}
+		newMessages += inserted
+	}
+
+	nameStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO wa_contacts (wa_account_id, jid, push_name, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (wa_account_id, jid) DO UPDATE SET push_name = EXCLUDED.push_name, updated_at = NOW()
+		WHERE wa_contacts.push_name IS DISTINCT FROM EXCLUDED.push_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare push name insert: %w", err)
+	}
+	defer nameStmt.Close()
+
+	for jid, pushName := range chunk.PushNames {
+		if _, err := nameStmt.ExecContext(ctx, chunk.WaAccountID, jid, pushName); err != nil {
+			return nil, fmt.Errorf("failed to store push name for %s: %w", jid, err)
+		}
+	}
+
+	progress := &HistorySyncProgress{SyncType: chunk.SyncType}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO wa_history_sync_progress (wa_account_id, sync_type, progress, chunks, conversations, messages, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (wa_account_id, sync_type) DO UPDATE SET
+			progress = GREATEST(wa_history_sync_progress.progress, EXCLUDED.progress),
+			chunks = wa_history_sync_progress.chunks + EXCLUDED.chunks,
+			conversations = wa_history_sync_progress.conversations + EXCLUDED.conversations,
+			messages = wa_history_sync_progress.messages + EXCLUDED.messages,
+			updated_at = NOW()
+		RETURNING progress, chunks, conversations, messages, updated_at
+	`, chunk.WaAccountID, chunk.SyncType, chunk.Progress, newChunks, newConversations, newMessages).Scan(
+		&progress.Progress, &progress.Chunks, &progress.Conversations, &progress.Messages, &progress.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update history sync progress: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit history sync chunk: %w", err)
+	}
+
+	return progress, nil
+}
+
+func (s *PostgresStore) GetHistorySyncProgress(waAccountID string) ([]HistorySyncProgress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT sync_type, progress, chunks, conversations, messages, updated_at
+		FROM wa_history_sync_progress WHERE wa_account_id = $1 ORDER BY sync_type
+	`, waAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history sync progress: %w", err)
+	}
+	defer rows.Close()
+
+	result := []HistorySyncProgress{}
+	for rows.Next() {
+		var p HistorySyncProgress
+		if err := rows.Scan(&p.SyncType, &p.Progress, &p.Chunks, &p.Conversations, &p.Messages, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan history sync progress: %w", err)
+		}
+		result = append(result, p)
+	}
+
+	return result, rows.Err()
+}
+
+// SaveMessage stores a single message sent or received live, a message that
+// is already stored is kept as it is
+func (s *PostgresStore) SaveMessage(waAccountID string, msg *HistoryMessage) error {
+	_, err := s.Exec(`
+		INSERT INTO wa_messages (wa_account_id, chat_jid, message_id, sender_jid, from_me, message_type, text, sent_at, live)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, TRUE)
+		ON CONFLICT (wa_account_id, chat_jid, message_id) DO NOTHING
+	`, waAccountID, msg.ChatJID, msg.MessageID, msg.SenderJID, msg.FromMe, msg.MessageType, msg.Text, msg.SentAt)
+	if err != nil {
+		return fmt.Errorf("failed to store message %s: %w", msg.MessageID, err)
+	}
+
+	return nil
+}
+
+// PruneLiveMessages deletes the messages stored live that were sent before
+// the cutoff, it returns how many were deleted
+func (s *PostgresStore) PruneLiveMessages(before time.Time) (int64, error) {
+	result, err := s.Exec(`DELETE FROM wa_messages WHERE live AND sent_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune live messages: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetHistoryMessage returns a stored message, or nil if it isn't stored
+func (s *PostgresStore) GetHistoryMessage(waAccountID, chatJID, messageID string) (*HistoryMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msg := &HistoryMessage{}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT chat_jid, message_id, sender_jid, from_me, message_type, text, sent_at
+		FROM wa_messages WHERE wa_account_id = $1 AND chat_jid = $2 AND message_id = $3
+	`, waAccountID, chatJID, messageID).Scan(&msg.ChatJID, &msg.MessageID, &msg.SenderJID,
+		&msg.FromMe, &msg.MessageType, &msg.Text, &msg.SentAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	return msg, nil
+}
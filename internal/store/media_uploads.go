@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UploadedMedia is media uploaded to the WhatsApp servers, which messages
+// with the same content can reference instead of uploading it again
+type UploadedMedia struct {
+	SHA256        string // hex SHA-256 of the plaintext content
+	MediaType     string
+	URL           string
+	DirectPath    string
+	Handle        string
+	ObjectID      string
+	MediaKey      []byte
+	FileEncSHA256 []byte
+	FileSHA256    []byte
+	FileLength    uint64
+	UploadedAt    time.Time
+}
+
+// GetUploadedMedia returns the upload of content of a media type made after
+// the cutoff, or nil if there is none
+func (s *PostgresStore) GetUploadedMedia(sha256, mediaType string, after time.Time) (*UploadedMedia, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m := &UploadedMedia{}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT sha256, media_type, url, direct_path, handle, object_id, media_key, file_enc_sha256, file_sha256, file_length, uploaded_at
+		FROM wa_media_uploads
+		WHERE sha256 = $1 AND media_type = $2 AND uploaded_at > $3
+	`, sha256, mediaType, after).Scan(&m.SHA256, &m.MediaType, &m.URL, &m.DirectPath, &m.Handle, &m.ObjectID,
+		&m.MediaKey, &m.FileEncSHA256, &m.FileSHA256, &m.FileLength, &m.UploadedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get uploaded media: %w", err)
+	}
+
+	return m, nil
+}
+
+// SaveUploadedMedia stores an upload, replacing an earlier upload of the same
+// content and media type
+func (s *PostgresStore) SaveUploadedMedia(m *UploadedMedia) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_media_uploads (sha256, media_type, url, direct_path, handle, object_id, media_key, file_enc_sha256, file_sha256, file_length)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (sha256, media_type) DO UPDATE SET
+			url = EXCLUDED.url,
+			direct_path = EXCLUDED.direct_path,
+			handle = EXCLUDED.handle,
+			object_id = EXCLUDED.object_id,
+			media_key = EXCLUDED.media_key,
+			file_enc_sha256 = EXCLUDED.file_enc_sha256,
+			file_sha256 = EXCLUDED.file_sha256,
+			file_length = EXCLUDED.file_length,
+			uploaded_at = NOW()
+		RETURNING uploaded_at
+	`, m.SHA256, m.MediaType, m.URL, m.DirectPath, m.Handle, m.ObjectID,
+		m.MediaKey, m.FileEncSHA256, m.FileSHA256, m.FileLength).Scan(&m.UploadedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save uploaded media: %w", err)
+	}
+
+	return nil
+}
+
+// PruneUploadedMedia deletes the uploads made before the cutoff
+func (s *PostgresStore) PruneUploadedMedia(before time.Time) (int64, error) {
+	result, err := s.Exec(`DELETE FROM wa_media_uploads WHERE uploaded_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune uploaded media: %w", err)
+	}
+
+	return result.RowsAffected()
+}
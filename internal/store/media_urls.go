@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// CachedMediaURL is the content last downloaded from a media URL, kept on
+// disk by its hash, with the validators to ask the server whether it changed
+type CachedMediaURL struct {
+	URL          string
+	SHA256       string // hex SHA-256 of the content
+	ContentType  string
+	ETag         string
+	LastModified string
+	Revalidate   bool // the server asked to be checked with before each use
+	FetchedAt    time.Time
+}
+
+// GetCachedMediaURL returns the cached download of a URL fetched or
+// revalidated after the cutoff, or nil if there is none
+func (s *PostgresStore) GetCachedMediaURL(url string, after time.Time) (*CachedMediaURL, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m := &CachedMediaURL{}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT url, sha256, content_type, etag, last_modified, revalidate, fetched_at
+		FROM wa_media_url_cache
+		WHERE url_sha256 = $1 AND url = $2 AND fetched_at > $3
+	`, urlKey(url), url, after).Scan(&m.URL, &m.SHA256, &m.ContentType, &m.ETag, &m.LastModified, &m.Revalidate, &m.FetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached media URL: %w", err)
+	}
+
+	return m, nil
+}
+
+// SaveCachedMediaURL stores the download of a URL, replacing the earlier one
+func (s *PostgresStore) SaveCachedMediaURL(m *CachedMediaURL) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_media_url_cache (url_sha256, url, sha256, content_type, etag, last_modified, revalidate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (url_sha256) DO UPDATE SET
+			url = EXCLUDED.url,
+			sha256 = EXCLUDED.sha256,
+			content_type = EXCLUDED.content_type,
+			etag = EXCLUDED.etag,
+			last_modified = EXCLUDED.last_modified,
+			revalidate = EXCLUDED.revalidate,
+			fetched_at = NOW()
+		RETURNING fetched_at
+	`, urlKey(m.URL), m.URL, m.SHA256, m.ContentType, m.ETag, m.LastModified, m.Revalidate).Scan(&m.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save cached media URL: %w", err)
+	}
+
+	return nil
+}
+
+// PruneCachedMediaURLs deletes the downloads fetched before the cutoff and
+// returns the content hashes still referenced
+func (s *PostgresStore) PruneCachedMediaURLs(before time.Time) (int64, map[string]bool, error) {
+	result, err := s.Exec(`DELETE FROM wa_media_url_cache WHERE fetched_at < $1`, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to prune cached media URLs: %w", err)
+	}
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to prune cached media URLs: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT sha256 FROM wa_media_url_cache`)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list cached media content: %w", err)
+	}
+	defer rows.Close()
+
+	referenced := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan cached media content: %w", err)
+		}
+		referenced[hash] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("failed to list cached media content: %w", err)
+	}
+
+	return pruned, referenced, nil
+}
+
+// urlKey is the primary key of a URL, URLs can be too long to index
+func urlKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OutboundMessage is a message sent through this service whose delivery is tracked.
+// Status is the furthest status reached by any recipient.
+type OutboundMessage struct {
+	WaAccountID string
+	MessageID   string
+	ChatJID     string
+	MessageType string
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type RecipientStatus struct {
+	RecipientJID string
+	Status       string
+	UpdatedAt    time.Time
+}
+
+type MessageStatusTransition struct {
+	RecipientJID string
+	Status       string
+	OccurredAt   time.Time
+}
+
+type MessageStatusReport struct {
+	Message    *OutboundMessage
+	Recipients []RecipientStatus
+	History    []MessageStatusTransition
+}
+
+// StatusAdvance describes the outcome of AdvanceMessageStatus
+type StatusAdvance struct {
+	Tracked      bool // The message was sent through this service
+	Advanced     bool // The recipient moved to a later status
+	PreviousRank int  // Rank of the recipient's status before the update, 0 if none
+}
+
+func (s *PostgresStore) CreateOutboundMessage(msg *OutboundMessage, rank int) error {
+	query := `
+		INSERT INTO wa_outbound_messages (wa_account_id, message_id, chat_jid, message_type, status, status_rank, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (wa_account_id, message_id) DO NOTHING
+	`
+
+	_, err := s.Exec(query, msg.WaAccountID, msg.MessageID, msg.ChatJID, msg.MessageType, msg.Status, rank)
+	if err != nil {
+		return fmt.Errorf("failed to create outbound message: %w", err)
+	}
+
+	return nil
+}
+
+// AdvanceMessageStatus moves a recipient of a tracked outbound message to a new
+// status if it ranks higher than the current one and the current one ranks at
+// most replaces, recording the transition. Receipts for messages that were not
+// sent through this service are ignored.
+func (s *PostgresStore) AdvanceMessageStatus(waAccountID, messageID, recipientJID, status string, rank, replaces int, at time.Time) (StatusAdvance, error) {
+	var result StatusAdvance
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var messageRank int
+	err = tx.QueryRowContext(ctx,
+		`SELECT status_rank FROM wa_outbound_messages WHERE wa_account_id = $1 AND message_id = $2 FOR UPDATE`,
+		waAccountID, messageID,
+	).Scan(&messageRank)
+	if err == sql.ErrNoRows {
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to get outbound message: %w", err)
+	}
+	result.Tracked = true
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT status_rank FROM wa_message_recipients WHERE wa_account_id = $1 AND message_id = $2 AND recipient_jid = $3`,
+		waAccountID, messageID, recipientJID,
+	).Scan(&result.PreviousRank)
+	if err != nil && err != sql.ErrNoRows {
+		return result, fmt.Errorf("failed to get recipient status: %w", err)
+	}
+
+	if result.PreviousRank >= rank || result.PreviousRank > replaces {
+		return result, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO wa_message_recipients (wa_account_id, message_id, recipient_jid, status, status_rank, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (wa_account_id, message_id, recipient_jid)
+		DO UPDATE SET status = $4, status_rank = $5, updated_at = $6
+	`, waAccountID, messageID, recipientJID, status, rank, at)
+	if err != nil {
+		return result, fmt.Errorf("failed to update recipient status: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO wa_message_status_history (wa_account_id, message_id, recipient_jid, status, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, waAccountID, messageID, recipientJID, status, at)
+	if err != nil {
+		return result, fmt.Errorf("failed to record status transition: %w", err)
+	}
+
+	if rank > messageRank && messageRank <= replaces {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE wa_outbound_messages SET status = $3, status_rank = $4, updated_at = $5
+			WHERE wa_account_id = $1 AND message_id = $2
+		`, waAccountID, messageID, status, rank, at)
+		if err != nil {
+			return result, fmt.Errorf("failed to update message status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit status update: %w", err)
+	}
+
+	result.Advanced = true
+	return result, nil
+}
+
+// AdvanceOutboundMessage moves a tracked outbound message to a new status
+// without a recipient, under the same conditions as AdvanceMessageStatus
+func (s *PostgresStore) AdvanceOutboundMessage(waAccountID, messageID, status string, rank, replaces int, at time.Time) error {
+	query := `
+		UPDATE wa_outbound_messages SET status = $3, status_rank = $4, updated_at = $6
+		WHERE wa_account_id = $1 AND message_id = $2 AND status_rank < $4 AND status_rank <= $5
+	`
+
+	_, err := s.Exec(query, waAccountID, messageID, status, rank, replaces, at)
+	if err != nil {
+		return fmt.Errorf("failed to update message status: %w", err)
+	}
+
+	return nil
+}
+
+// GetMessageStatusReport returns the tracked status of an outbound message, or nil if it is unknown
+func (s *PostgresStore) GetMessageStatusReport(waAccountID, messageID string) (*MessageStatusReport, error) {
+	// Query cancels its context on return, so rows are read under a context we own
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	msg := &OutboundMessage{WaAccountID: waAccountID, MessageID: messageID}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT chat_jid, message_type, status, created_at, updated_at
+		FROM wa_outbound_messages WHERE wa_account_id = $1 AND message_id = $2
+	`, waAccountID, messageID).Scan(&msg.ChatJID, &msg.MessageType, &msg.Status, &msg.CreatedAt, &msg.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbound message: %w", err)
+	}
+
+	report := &MessageStatusReport{
+		Message:    msg,
+		Recipients: []RecipientStatus{},
+		History:    []MessageStatusTransition{},
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT recipient_jid, status, updated_at FROM wa_message_recipients
+		WHERE wa_account_id = $1 AND message_id = $2 ORDER BY recipient_jid
+	`, waAccountID, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipient statuses: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r RecipientStatus
+		if err := rows.Scan(&r.RecipientJID, &r.Status, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recipient status: %w", err)
+		}
+		report.Recipients = append(report.Recipients, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recipient statuses: %w", err)
+	}
+
+	historyRows, err := s.db.QueryContext(ctx, `
+		SELECT recipient_jid, status, occurred_at FROM wa_message_status_history
+		WHERE wa_account_id = $1 AND message_id = $2 ORDER BY occurred_at, id
+	`, waAccountID, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+	defer historyRows.Close()
+
+	for historyRows.Next() {
+		var t MessageStatusTransition
+		if err := historyRows.Scan(&t.RecipientJID, &t.Status, &t.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status transition: %w", err)
+		}
+		report.History = append(report.History, t)
+	}
+	if err := historyRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read status history: %w", err)
+	}
+
+	return report, nil
+}
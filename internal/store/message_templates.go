@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MessageTemplate is a version of a named message template. Versions are
+// immutable, changing a template adds a version.
+type MessageTemplate struct {
+	Name          string
+	Version       int
+	Type          string
+	DefaultLocale string
+	Variants      map[string]TemplateVariant // by locale
+	CreatedAt     time.Time
+}
+
+// TemplateVariant is the content of a template in one locale: the text,
+// caption or poll question, and the poll options
+type TemplateVariant struct {
+	Body    string   `json:"body"`
+	Options []string `json:"options,omitempty"`
+}
+
+const messageTemplateColumns = `name, version, message_type, default_locale, variants, created_at`
+
+// CreateMessageTemplate stores a template version, it reports false if the
+// version already exists
+func (s *PostgresStore) CreateMessageTemplate(t *MessageTemplate) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	variants, err := json.Marshal(t.Variants)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode template variants: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_message_templates (name, version, message_type, default_locale, variants)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name, version) DO NOTHING
+		RETURNING created_at
+	`, t.Name, t.Version, t.Type, t.DefaultLocale, variants).Scan(&t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create message template: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetMessageTemplate returns a version of a template, the latest if version
+// is 0, or nil if it doesn't exist
+func (s *PostgresStore) GetMessageTemplate(name string, version int) (*MessageTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	templates, err := s.queryMessageTemplates(ctx, `
+		WHERE name = $1 AND ($2 = 0 OR version = $2)
+		ORDER BY version DESC
+		LIMIT 1
+	`, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	return templates[0], nil
+}
+
+// ListMessageTemplates returns the latest version of every template
+func (s *PostgresStore) ListMessageTemplates() ([]*MessageTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.queryMessageTemplates(ctx, `
+		WHERE (name, version) IN (SELECT name, MAX(version) FROM wa_message_templates GROUP BY name)
+		ORDER BY name
+	`)
+}
+
+// ListMessageTemplateVersions returns every version of a template, newest first
+func (s *PostgresStore) ListMessageTemplateVersions(name string) ([]*MessageTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.queryMessageTemplates(ctx, `WHERE name = $1 ORDER BY version DESC`, name)
+}
+
+// DeleteMessageTemplate deletes all versions of a template
+func (s *PostgresStore) DeleteMessageTemplate(name string) (bool, error) {
+	result, err := s.Exec(`DELETE FROM wa_message_templates WHERE name = $1`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete message template: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete message template: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (s *PostgresStore) queryMessageTemplates(ctx context.Context, where string, args ...interface{}) ([]*MessageTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+messageTemplateColumns+` FROM wa_message_templates `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query message templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*MessageTemplate{}
+	for rows.Next() {
+		t := &MessageTemplate{}
+		var variants []byte
+		if err := rows.Scan(&t.Name, &t.Version, &t.Type, &t.DefaultLocale, &variants, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message template: %w", err)
+		}
+		if err := json.Unmarshal(variants, &t.Variants); err != nil {
+			return nil, fmt.Errorf("failed to decode template variants: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query message templates: %w", err)
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// serviceMigrations creates the tables owned by this service (as opposed to
+// the whatsmeow tables managed by sqlstore). Every statement must be idempotent
+// because the full list runs on each startup.
+var serviceMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS wa_call_policies (
+		wa_account_id VARCHAR(255) PRIMARY KEY,
+		auto_reject   BOOLEAN NOT NULL DEFAULT FALSE,
+		reply_text    TEXT NOT NULL DEFAULT '',
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_outbound_messages (
+		wa_account_id VARCHAR(255) NOT NULL,
+		message_id    VARCHAR(128) NOT NULL,
+		chat_jid      VARCHAR(255) NOT NULL,
+		message_type  VARCHAR(32) NOT NULL,
+		status        VARCHAR(32) NOT NULL,
+		status_rank   SMALLINT NOT NULL,
+		created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_message_recipients (
+		wa_account_id VARCHAR(255) NOT NULL,
+		message_id    VARCHAR(128) NOT NULL,
+		recipient_jid VARCHAR(255) NOT NULL,
+		status        VARCHAR(32) NOT NULL,
+		status_rank   SMALLINT NOT NULL,
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, message_id, recipient_jid)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_message_status_history (
+		id            BIGSERIAL PRIMARY KEY,
+		wa_account_id VARCHAR(255) NOT NULL,
+		message_id    VARCHAR(128) NOT NULL,
+		recipient_jid VARCHAR(255) NOT NULL,
+		status        VARCHAR(32) NOT NULL,
+		occurred_at   TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_message_status_history_message
+		ON wa_message_status_history (wa_account_id, message_id)`,
+	`CREATE TABLE IF NOT EXISTS wa_chats (
+		wa_account_id   VARCHAR(255) NOT NULL,
+		chat_jid        VARCHAR(255) NOT NULL,
+		name            TEXT NOT NULL DEFAULT '',
+		unread_count    INTEGER NOT NULL DEFAULT 0,
+		archived        BOOLEAN NOT NULL DEFAULT FALSE,
+		pinned          BOOLEAN NOT NULL DEFAULT FALSE,
+		muted_until     TIMESTAMPTZ,
+		last_message_at TIMESTAMPTZ,
+		updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, chat_jid)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_messages (
+		wa_account_id VARCHAR(255) NOT NULL,
+		chat_jid      VARCHAR(255) NOT NULL,
+		message_id    VARCHAR(128) NOT NULL,
+		sender_jid    VARCHAR(255) NOT NULL,
+		from_me       BOOLEAN NOT NULL,
+		message_type  VARCHAR(32) NOT NULL,
+		text          TEXT NOT NULL DEFAULT '',
+		sent_at       TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (wa_account_id, chat_jid, message_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_messages_chat_time
+		ON wa_messages (wa_account_id, chat_jid, sent_at DESC)`,
+	`CREATE TABLE IF NOT EXISTS wa_contacts (
+		wa_account_id VARCHAR(255) NOT NULL,
+		jid           VARCHAR(255) NOT NULL,
+		push_name     TEXT NOT NULL DEFAULT '',
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, jid)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_history_sync_progress (
+		wa_account_id VARCHAR(255) NOT NULL,
+		sync_type     VARCHAR(32) NOT NULL,
+		progress      INTEGER NOT NULL DEFAULT 0,
+		chunks        INTEGER NOT NULL DEFAULT 0,
+		conversations INTEGER NOT NULL DEFAULT 0,
+		messages      INTEGER NOT NULL DEFAULT 0,
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, sync_type)
+	)`,
+	`ALTER TABLE wa_contacts
+		ADD COLUMN IF NOT EXISTS full_name TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS first_name TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS business_name TEXT NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_contacts_updated
+		ON wa_contacts (wa_account_id, updated_at DESC)`,
+	`ALTER TABLE wa_chats ADD COLUMN IF NOT EXISTS muted BOOLEAN NOT NULL DEFAULT FALSE`,
+	`CREATE TABLE IF NOT EXISTS wa_labels (
+		wa_account_id VARCHAR(255) NOT NULL,
+		label_id      VARCHAR(64) NOT NULL,
+		name          TEXT NOT NULL DEFAULT '',
+		color         INTEGER NOT NULL DEFAULT 0,
+		deleted       BOOLEAN NOT NULL DEFAULT FALSE,
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, label_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_chat_labels (
+		wa_account_id VARCHAR(255) NOT NULL,
+		chat_jid      VARCHAR(255) NOT NULL,
+		label_id      VARCHAR(64) NOT NULL,
+		labeled_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, chat_jid, label_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_message_labels (
+		wa_account_id VARCHAR(255) NOT NULL,
+		chat_jid      VARCHAR(255) NOT NULL,
+		message_id    VARCHAR(128) NOT NULL,
+		label_id      VARCHAR(64) NOT NULL,
+		labeled_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, chat_jid, message_id, label_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_starred_messages (
+		wa_account_id VARCHAR(255) NOT NULL,
+		chat_jid      VARCHAR(255) NOT NULL,
+		message_id    VARCHAR(128) NOT NULL,
+		sender_jid    VARCHAR(255) NOT NULL DEFAULT '',
+		from_me       BOOLEAN NOT NULL DEFAULT FALSE,
+		starred_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, chat_jid, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_decrypt_failures (
+		wa_account_id VARCHAR(255) NOT NULL,
+		message_id    VARCHAR(128) NOT NULL,
+		chat_jid      VARCHAR(255) NOT NULL,
+		sender_jid    VARCHAR(255) NOT NULL,
+		unavailable   BOOLEAN NOT NULL DEFAULT FALSE,
+		unavailable_type VARCHAR(32) NOT NULL DEFAULT '',
+		fail_mode     VARCHAR(32) NOT NULL DEFAULT '',
+		failures      INTEGER NOT NULL DEFAULT 1,
+		failed_at     TIMESTAMPTZ NOT NULL,
+		recovered_at  TIMESTAMPTZ,
+		PRIMARY KEY (wa_account_id, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_identity_changes (
+		id            BIGSERIAL PRIMARY KEY,
+		wa_account_id VARCHAR(255) NOT NULL,
+		jid           VARCHAR(255) NOT NULL,
+		implicit      BOOLEAN NOT NULL DEFAULT FALSE,
+		changed_at    TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_webhook_subscriptions (
+		id            UUID PRIMARY KEY,
+		wa_account_id VARCHAR(255) NOT NULL DEFAULT '',
+		tenant_id     VARCHAR(255) NOT NULL DEFAULT '',
+		url           TEXT NOT NULL,
+		secret        TEXT NOT NULL,
+		event_types   TEXT[] NOT NULL DEFAULT '{}',
+		chat_jids     TEXT[] NOT NULL DEFAULT '{}',
+		jids          TEXT[] NOT NULL DEFAULT '{}',
+		active        BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_webhook_deliveries (
+		id             BIGSERIAL PRIMARY KEY,
+		url            TEXT NOT NULL,
+		request_id     VARCHAR(64) NOT NULL,
+		wa_account_ids TEXT[] NOT NULL DEFAULT '{}',
+		event_types    TEXT[] NOT NULL DEFAULT '{}',
+		batch_size     INTEGER NOT NULL DEFAULT 0,
+		payload        TEXT NOT NULL,
+		signature      TEXT NOT NULL,
+		status_code    INTEGER NOT NULL DEFAULT 0,
+		succeeded      BOOLEAN NOT NULL,
+		latency_ms     BIGINT NOT NULL,
+		response       TEXT NOT NULL DEFAULT '',
+		error          TEXT NOT NULL DEFAULT '',
+		attempted_at   TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_webhook_deliveries_attempted
+		ON wa_webhook_deliveries (attempted_at DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_webhook_deliveries_accounts
+		ON wa_webhook_deliveries USING GIN (wa_account_ids)`,
+	`ALTER TABLE wa_webhook_subscriptions ADD COLUMN IF NOT EXISTS format VARCHAR(32) NOT NULL DEFAULT 'native'`,
+	`ALTER TABLE wa_webhook_deliveries
+		ADD COLUMN IF NOT EXISTS format VARCHAR(32) NOT NULL DEFAULT 'native',
+		ADD COLUMN IF NOT EXISTS headers JSONB NOT NULL DEFAULT '{}'`,
+	`CREATE TABLE IF NOT EXISTS wa_message_templates (
+		name           VARCHAR(128) NOT NULL,
+		version        INTEGER NOT NULL,
+		message_type   VARCHAR(32) NOT NULL,
+		default_locale VARCHAR(35) NOT NULL,
+		variants       JSONB NOT NULL,
+		created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (name, version)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wa_media_uploads (
+		sha256          CHAR(64) NOT NULL,
+		media_type      VARCHAR(64) NOT NULL,
+		url             TEXT NOT NULL DEFAULT '',
+		direct_path     TEXT NOT NULL,
+		handle          TEXT NOT NULL DEFAULT '',
+		object_id       TEXT NOT NULL DEFAULT '',
+		media_key       BYTEA NOT NULL,
+		file_enc_sha256 BYTEA NOT NULL,
+		file_sha256     BYTEA NOT NULL,
+		file_length     BIGINT NOT NULL,
+		uploaded_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (sha256, media_type)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_media_uploads_uploaded
+		ON wa_media_uploads (uploaded_at)`,
+	`CREATE TABLE IF NOT EXISTS wa_history_sync_chunks (
+		wa_account_id VARCHAR(255) NOT NULL,
+		sync_type     VARCHAR(32) NOT NULL,
+		chunk_order   INTEGER NOT NULL,
+		received_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (wa_account_id, sync_type, chunk_order)
+	)`,
+	// Deliveries recorded before hold only their own request ID, backfilled
+	// once when the column is added
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'wa_webhook_deliveries' AND column_name = 'request_ids'
+		) THEN
+			ALTER TABLE wa_webhook_deliveries ADD COLUMN request_ids TEXT[] NOT NULL DEFAULT '{}';
+			UPDATE wa_webhook_deliveries SET request_ids = ARRAY[request_id];
+		END IF;
+	END $$`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_webhook_deliveries_request_ids
+		ON wa_webhook_deliveries USING GIN (request_ids)`,
+	`CREATE TABLE IF NOT EXISTS wa_media_url_cache (
+		url_sha256    CHAR(64) PRIMARY KEY,
+		url           TEXT NOT NULL,
+		sha256        CHAR(64) NOT NULL,
+		content_type  TEXT NOT NULL DEFAULT '',
+		etag          TEXT NOT NULL DEFAULT '',
+		last_modified TEXT NOT NULL DEFAULT '',
+		fetched_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_media_url_cache_fetched
+		ON wa_media_url_cache (fetched_at)`,
+	`ALTER TABLE wa_media_url_cache ADD COLUMN IF NOT EXISTS revalidate BOOLEAN NOT NULL DEFAULT FALSE`,
+	// Live messages are pruned after a retention, history sync imports are kept
+	`ALTER TABLE wa_messages ADD COLUMN IF NOT EXISTS live BOOLEAN NOT NULL DEFAULT FALSE`,
+	`CREATE INDEX IF NOT EXISTS idx_wa_messages_live
+		ON wa_messages (sent_at) WHERE live`,
+	`CREATE TABLE IF NOT EXISTS wa_account_tenants (
+		wa_account_id VARCHAR(255) PRIMARY KEY,
+		tenant_id     VARCHAR(255) NOT NULL,
+		updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+}
+
+func (s *PostgresStore) migrate(ctx context.Context) error {
+	for i, stmt := range serviceMigrations {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("service migration %d failed: %w", i, err)
+		}
+	}
+	return nil
+}
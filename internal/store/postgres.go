@@ -61,12 +61,18 @@ func NewPostgresStore(databaseURL string) (*PostgresStore, error) {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	log.Info().Msg("Database store initialized successfully")
-
-	return &PostgresStore{
+	s := &PostgresStore{
 		db:        db,
 		container: container,
-	}, nil
+	}
+
+	if err := s.migrate(upgradeCtx); err != nil {
+		return nil, fmt.Errorf("failed to run service migrations: %w", err)
+	}
+
+	log.Info().Msg("Database store initialized successfully")
+
+	return s, nil
 }
 
 // GetDeviceStore returns a device store for a specific WhatsApp account
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WebhookDelivery is a recorded webhook delivery attempt. RequestIDs holds
+// the request ID of the delivery and those of its events, to find a delivery
+// by any of them.
+type WebhookDelivery struct {
+	ID           int64
+	URL          string
+	RequestID    string
+	RequestIDs   []string
+	Format       string
+	Headers      map[string]string
+	WaAccountIDs []string
+	EventTypes   []string
+	BatchSize    int
+	Payload      string
+	Signature    string
+	StatusCode   int
+	Succeeded    bool
+	LatencyMS    int64
+	Response     string
+	Error        string
+	AttemptedAt  time.Time
+}
+
+// WebhookDeliveryFilter narrows a delivery listing, zero fields match everything
+type WebhookDeliveryFilter struct {
+	WaAccountID string
+	EventType   string
+	RequestID   string
+	Succeeded   *bool
+	StatusCode  int
+	Since       time.Time
+	Until       time.Time
+}
+
+func (s *PostgresStore) CreateWebhookDelivery(d *WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	headers, err := json.Marshal(d.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook delivery headers: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_webhook_deliveries (url, request_id, request_ids, format, headers, wa_account_ids, event_types,
+			batch_size, payload, signature, status_code, succeeded, latency_ms, response, error, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id
+	`, d.URL, d.RequestID, textArray(d.RequestIDs), d.Format, headers, textArray(d.WaAccountIDs), textArray(d.EventTypes),
+		d.BatchSize, d.Payload, d.Signature, d.StatusCode, d.Succeeded, d.LatencyMS, d.Response, d.Error,
+		d.AttemptedAt).Scan(&d.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns a page of deliveries without their payloads,
+// most recent first, along with the total number of matching deliveries
+func (s *PostgresStore) ListWebhookDeliveries(filter WebhookDeliveryFilter, limit, offset int) ([]WebhookDelivery, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var succeeded sql.NullBool
+	if filter.Succeeded != nil {
+		succeeded = sql.NullBool{Bool: *filter.Succeeded, Valid: true}
+	}
+	var since, until sql.NullTime
+	if !filter.Since.IsZero() {
+		since = sql.NullTime{Time: filter.Since, Valid: true}
+	}
+	if !filter.Until.IsZero() {
+		until = sql.NullTime{Time: filter.Until, Valid: true}
+	}
+
+	where := `($1 = '' OR $1 = ANY(wa_account_ids)) AND ($2 = '' OR $2 = ANY(event_types))
+		AND ($3 = '' OR request_ids @> ARRAY[$3]::TEXT[])
+		AND ($4::BOOLEAN IS NULL OR succeeded = $4) AND ($5 = 0 OR status_code = $5)
+		AND ($6::TIMESTAMPTZ IS NULL OR attempted_at >= $6) AND ($7::TIMESTAMPTZ IS NULL OR attempted_at < $7)`
+	args := []interface{}{filter.WaAccountID, filter.EventType, filter.RequestID, succeeded, filter.StatusCode, since, until}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM wa_webhook_deliveries WHERE `+where,
+		args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, url, request_id, format, wa_account_ids, event_types, batch_size, status_code,
+			succeeded, latency_ms, response, error, attempted_at
+		FROM wa_webhook_deliveries WHERE `+where+`
+		ORDER BY attempted_at DESC, id DESC LIMIT $8 OFFSET $9
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.URL, &d.RequestID, &d.Format, pq.Array(&d.WaAccountIDs), pq.Array(&d.EventTypes),
+			&d.BatchSize, &d.StatusCode, &d.Succeeded, &d.LatencyMS, &d.Response, &d.Error, &d.AttemptedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+// GetWebhookDelivery returns a delivery with its payload, or nil if it doesn't exist
+func (s *PostgresStore) GetWebhookDelivery(id int64) (*WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	d := &WebhookDelivery{}
+	var headers []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, url, request_id, format, headers, wa_account_ids, event_types, batch_size, payload, signature,
+			status_code, succeeded, latency_ms, response, error, attempted_at
+		FROM wa_webhook_deliveries WHERE id = $1
+	`, id).Scan(&d.ID, &d.URL, &d.RequestID, &d.Format, &headers, pq.Array(&d.WaAccountIDs), pq.Array(&d.EventTypes),
+		&d.BatchSize, &d.Payload, &d.Signature, &d.StatusCode, &d.Succeeded, &d.LatencyMS,
+		&d.Response, &d.Error, &d.AttemptedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if err := json.Unmarshal(headers, &d.Headers); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook delivery headers: %w", err)
+	}
+
+	return d, nil
+}
+
+// PruneWebhookDeliveries deletes the deliveries attempted before the cutoff
+func (s *PostgresStore) PruneWebhookDeliveries(before time.Time) (int64, error) {
+	result, err := s.Exec(`DELETE FROM wa_webhook_deliveries WHERE attempted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune webhook deliveries: %w", err)
+	}
+
+	return result.RowsAffected()
+}
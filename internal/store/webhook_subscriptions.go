@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WebhookSubscription routes matching events to a URL. Empty scope and filter
+// fields match everything.
+type WebhookSubscription struct {
+	ID          string
+	WaAccountID string
+	TenantID    string
+	URL         string
+	Secret      string
+	EventTypes  []string
+	ChatJIDs    []string
+	JIDs        []string
+	Format      string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+const webhookSubscriptionColumns = `id, wa_account_id, tenant_id, url, secret, event_types, chat_jids, jids, format, active, created_at, updated_at`
+
+func (s *PostgresStore) CreateWebhookSubscription(sub *WebhookSubscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_webhook_subscriptions (id, wa_account_id, tenant_id, url, secret, event_types, chat_jids, jids, format, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`, sub.ID, sub.WaAccountID, sub.TenantID, sub.URL, sub.Secret, textArray(sub.EventTypes),
+		textArray(sub.ChatJIDs), textArray(sub.JIDs), sub.Format, sub.Active).Scan(&sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateWebhookSubscription overwrites a subscription, it reports false if the
+// subscription doesn't exist
+func (s *PostgresStore) UpdateWebhookSubscription(sub *WebhookSubscription) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE wa_webhook_subscriptions SET
+			wa_account_id = $2, tenant_id = $3, url = $4, secret = $5,
+			event_types = $6, chat_jids = $7, jids = $8, format = $9, active = $10, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, sub.ID, sub.WaAccountID, sub.TenantID, sub.URL, sub.Secret, textArray(sub.EventTypes),
+		textArray(sub.ChatJIDs), textArray(sub.JIDs), sub.Format, sub.Active).Scan(&sub.CreatedAt, &sub.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *PostgresStore) DeleteWebhookSubscription(id string) (bool, error) {
+	result, err := s.Exec(`DELETE FROM wa_webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetWebhookSubscription returns a subscription, or nil if it doesn't exist
+func (s *PostgresStore) GetWebhookSubscription(id string) (*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subs, err := s.queryWebhookSubscriptions(ctx, `WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, nil
+	}
+
+	return subs[0], nil
+}
+
+// ListWebhookSubscriptions lists subscriptions, optionally narrowed to an
+// account and/or tenant
+func (s *PostgresStore) ListWebhookSubscriptions(waAccountID, tenantID string) ([]*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.queryWebhookSubscriptions(ctx, `
+		WHERE ($1 = '' OR wa_account_id = $1) AND ($2 = '' OR tenant_id = $2)
+		ORDER BY created_at
+	`, waAccountID, tenantID)
+}
+
+func (s *PostgresStore) ListActiveWebhookSubscriptions() ([]*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.queryWebhookSubscriptions(ctx, `WHERE active`)
+}
+
+func (s *PostgresStore) queryWebhookSubscriptions(ctx context.Context, where string, args ...interface{}) ([]*WebhookSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookSubscriptionColumns+` FROM wa_webhook_subscriptions `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []*WebhookSubscription{}
+	for rows.Next() {
+		sub := &WebhookSubscription{}
+		if err := rows.Scan(&sub.ID, &sub.WaAccountID, &sub.TenantID, &sub.URL, &sub.Secret,
+			pq.Array(&sub.EventTypes), pq.Array(&sub.ChatJIDs), pq.Array(&sub.JIDs),
+			&sub.Format, &sub.Active, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// textArray binds a string slice to a NOT NULL text[] column
+func textArray(values []string) interface{} {
+	if values == nil {
+		values = []string{}
+	}
+	return pq.Array(values)
+}
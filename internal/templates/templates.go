@@ -0,0 +1,90 @@
+// Package templates renders message templates: text with {{variable}}
+// placeholders, kept in per-locale variants.
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// placeholderPattern matches {{name}}, spaces inside the braces are allowed
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+	namePattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,127}$`)
+	localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+)
+
+// MissingVariablesError lists the variables a template needs but wasn't given
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("missing template variables: %s", strings.Join(e.Names, ", "))
+}
+
+// ValidName reports whether name can name a template: lowercase letters,
+// digits, dots, dashes and underscores
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// NormalizeLocale lowercases a locale and uses dashes as separators, so
+// "pt_BR" and "pt-br" are the same. It reports false for malformed locales.
+func NormalizeLocale(locale string) (string, bool) {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	return locale, localePattern.MatchString(locale)
+}
+
+// Placeholders returns the variables used in texts, in order of first use
+func Placeholders(texts ...string) []string {
+	var names []string
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(names, match[1]) {
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// Render replaces the placeholders of texts with the values of variables.
+// Every placeholder is required, variables not used by the texts are ignored.
+func Render(variables map[string]string, texts ...string) ([]string, error) {
+	var missing []string
+	for _, name := range Placeholders(texts...) {
+		if _, ok := variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingVariablesError{Names: missing}
+	}
+
+	rendered := make([]string, len(texts))
+	for i, text := range texts {
+		rendered[i] = placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			return variables[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+		})
+	}
+	return rendered, nil
+}
+
+// MatchLocale picks the variant for a requested locale among the available
+// ones: the exact locale, then its language ("pt" for "pt-br"), then the
+// fallback. The requested locale must be normalized.
+func MatchLocale(available []string, locale, fallback string) string {
+	if locale != "" {
+		if slices.Contains(available, locale) {
+			return locale
+		}
+		if language, _, ok := strings.Cut(locale, "-"); ok && slices.Contains(available, language) {
+			return language
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,157 @@
+package templates
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"order_shipped", true},
+		{"v2.welcome-message", true},
+		{"0day", true},
+		{"", false},
+		{"_hidden", false},
+		{"Welcome", false},
+		{"with space", false},
+		{string(make([]byte, 129)), false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidName(tt.name); got != tt.want {
+			t.Errorf("ValidName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+		wantOK bool
+	}{
+		{"pt_BR", "pt-br", true},
+		{" EN-us ", "en-us", true},
+		{"fil", "fil", true},
+		{"zh-Hant-TW", "zh-hant-tw", true},
+		{"", "", false},
+		{"e", "e", false},
+		{"english", "english", false},
+		{"pt-", "pt-", false},
+		{"pt--br", "pt--br", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeLocale(tt.locale)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NormalizeLocale(%q) = %q, %v, want %q, %v", tt.locale, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{"none", []string{"Hello"}, nil},
+		{"order of first use", []string{"{{b}} {{a}} {{ b }}"}, []string{"b", "a"}},
+		{"across texts", []string{"Hi {{name}}", "Order {{order.id}} for {{name}}"}, []string{"name", "order.id"}},
+		{"not placeholders", []string{"{{}} {{1st}} {name} {{ two words }}"}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := Placeholders(tt.texts...); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Placeholders = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		variables   map[string]string
+		texts       []string
+		want        []string
+		wantMissing []string
+	}{
+		{
+			name:      "replaces every use",
+			variables: map[string]string{"name": "Ana", "code": "42"},
+			texts:     []string{"Hi {{name}}, {{ name }}!", "Code {{code}}"},
+			want:      []string{"Hi Ana, Ana!", "Code 42"},
+		},
+		{
+			name:      "unused variables",
+			variables: map[string]string{"name": "Ana", "extra": "x"},
+			texts:     []string{"Hi {{name}}", ""},
+			want:      []string{"Hi Ana", ""},
+		},
+		{
+			name:      "empty value",
+			variables: map[string]string{"name": ""},
+			texts:     []string{"Hi {{name}}"},
+			want:      []string{"Hi "},
+		},
+		{
+			name:      "values aren't expanded",
+			variables: map[string]string{"a": "{{b}}", "b": "no"},
+			texts:     []string{"{{a}}"},
+			want:      []string{"{{b}}"},
+		},
+		{
+			name:        "missing variables",
+			variables:   map[string]string{"name": "Ana"},
+			texts:       []string{"{{greeting}} {{name}}", "{{order}} {{greeting}}"},
+			wantMissing: []string{"greeting", "order"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Render(tt.variables, tt.texts...)
+		if tt.wantMissing != nil {
+			var missing *MissingVariablesError
+			if !errors.As(err, &missing) || !slices.Equal(missing.Names, tt.wantMissing) {
+				t.Errorf("%s: Render = %v, want missing %v", tt.name, err, tt.wantMissing)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Render: %v", tt.name, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Render = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	available := []string{"en", "pt", "pt-br", "es-mx"}
+
+	tests := []struct {
+		name     string
+		locale   string
+		fallback string
+		want     string
+	}{
+		{"exact", "pt-br", "en", "pt-br"},
+		{"language", "pt-pt", "en", "pt"},
+		{"language only", "pt", "en", "pt"},
+		{"region without language", "es-ar", "en", "en"},
+		{"unknown", "fr-fr", "en", "en"},
+		{"none requested", "", "pt", "pt"},
+		{"no fallback", "de", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := MatchLocale(available, tt.locale, tt.fallback); got != tt.want {
+			t.Errorf("%s: MatchLocale(%q) = %q, want %q", tt.name, tt.locale, got, tt.want)
+		}
+	}
+}
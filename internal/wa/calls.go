@@ -0,0 +1,121 @@
+package wa
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// GetCallPolicy returns the call policy for an account, falling back to the
+// configured defaults when the account has no stored policy
+func (cm *ClientManager) GetCallPolicy(waAccountID string) (*store.CallPolicy, error) {
+	policy, err := cm.store.GetCallPolicy(waAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == nil {
+		policy = &store.CallPolicy{
+			WaAccountID: waAccountID,
+			AutoReject:  cm.config.CallAutoReject,
+			ReplyText:   cm.config.CallRejectMessage,
+		}
+	}
+
+	return policy, nil
+}
+
+func (cm *ClientManager) SetCallPolicy(policy *store.CallPolicy) error {
+	return cm.store.SaveCallPolicy(policy)
+}
+
+// seenCallTTL is how long a call ID is remembered, the events of a call arrive
+// well within it
+const seenCallTTL = 5 * time.Minute
+
+// applyCallPolicy rejects an incoming call and sends the optional reply text
+// if the account is configured to auto-reject calls
+func applyCallPolicy(mc *ManagedClient, sink EventSink, call types.BasicCallMeta) {
+	if !mc.firstCallEvent(call.CallID, time.Now()) {
+		return
+	}
+
+	policy, err := mc.manager.GetCallPolicy(mc.WaAccountID)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", mc.WaAccountID).Msg("Failed to load call policy")
+		return
+	}
+
+	if !policy.AutoReject {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := mc.Client.RejectCall(ctx, call.From, call.CallID); err != nil {
+		log.Error().
+			Err(err).
+			Str("wa_account_id", mc.WaAccountID).
+			Str("call_id", call.CallID).
+			Msg("Failed to reject call")
+		return
+	}
+
+	log.Info().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("call_id", call.CallID).
+		Str("from", call.From.String()).
+		Msg("Call auto-rejected")
+
+	rejected := webhooks.CallAutoRejectedEvent{
+		Event:     "call_auto_rejected",
+		CallID:    call.CallID,
+		From:      call.From.String(),
+		Timestamp: call.Timestamp.Unix(),
+	}
+
+	if policy.ReplyText != "" {
+		_, err := mc.Client.SendMessage(ctx, call.From.ToNonAD(), &waE2E.Message{
+			Conversation: proto.String(policy.ReplyText),
+		})
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("wa_account_id", mc.WaAccountID).
+				Str("call_id", call.CallID).
+				Msg("Failed to send call reject reply")
+		} else {
+			rejected.ReplySent = true
+		}
+	}
+
+	sink.Publish("call", webhooks.NewPayload(mc.WaAccountID, "", rejected))
+}
+
+// firstCallEvent reports whether the call wasn't seen yet, and remembers it
+func (mc *ManagedClient) firstCallEvent(callID string, now time.Time) bool {
+	mc.callsMu.Lock()
+	defer mc.callsMu.Unlock()
+
+	for id, seen := range mc.seenCalls {
+		if now.Sub(seen) > seenCallTTL {
+			delete(mc.seenCalls, id)
+		}
+	}
+
+	if _, ok := mc.seenCalls[callID]; ok {
+		return false
+	}
+	if mc.seenCalls == nil {
+		mc.seenCalls = make(map[string]time.Time)
+	}
+	mc.seenCalls[callID] = now
+	return true
+}
@@ -0,0 +1,29 @@
+package wa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFirstCallEvent(t *testing.T) {
+	mc := &ManagedClient{}
+	start := time.Now()
+
+	steps := []struct {
+		name   string
+		callID string
+		at     time.Time
+		want   bool
+	}{
+		{"offer", "call-1", start, true},
+		{"offer notice of the same call", "call-1", start.Add(time.Second), false},
+		{"another call", "call-2", start.Add(2 * time.Second), true},
+		{"same call after it expired", "call-1", start.Add(seenCallTTL + time.Minute), true},
+	}
+
+	for _, step := range steps {
+		if got := mc.firstCallEvent(step.callID, step.at); got != step.want {
+			t.Errorf("%s: firstCallEvent(%q) = %v, want %v", step.name, step.callID, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package wa
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// UpdateChatState records a chat state change made through the API, so the
+// echoed app state event from WhatsApp is recognised as a no-op
+func (cm *ClientManager) UpdateChatState(waAccountID string, chat types.JID, update store.ChatStateUpdate) error {
+	_, err := cm.store.UpdateChatState(waAccountID, chat.String(), update)
+	return err
+}
+
+// GetChatState returns the stored state of a chat, or nil if the chat is unknown
+func (cm *ClientManager) GetChatState(waAccountID string, chat types.JID) (*store.ChatState, error) {
+	return cm.store.GetChatState(waAccountID, chat.String())
+}
+
+func handleArchiveEvent(mc *ManagedClient, sink EventSink, evt *events.Archive) {
+	archived := evt.Action.GetArchived()
+	changed, err := mc.manager.store.UpdateChatState(mc.WaAccountID, evt.JID.String(), store.ChatStateUpdate{
+		Archived: &archived,
+	})
+	if err != nil {
+		logChatStateError(mc, err, evt.JID)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	sendChatStateChanged(mc, sink, evt.JID, "archive", evt.Timestamp, evt.FromFullSync, webhooks.ChatStateChangedEvent{
+		Archived: &archived,
+	})
+}
+
+func handlePinEvent(mc *ManagedClient, sink EventSink, evt *events.Pin) {
+	pinned := evt.Action.GetPinned()
+	changed, err := mc.manager.store.UpdateChatState(mc.WaAccountID, evt.JID.String(), store.ChatStateUpdate{
+		Pinned: &pinned,
+	})
+	if err != nil {
+		logChatStateError(mc, err, evt.JID)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	sendChatStateChanged(mc, sink, evt.JID, "pin", evt.Timestamp, evt.FromFullSync, webhooks.ChatStateChangedEvent{
+		Pinned: &pinned,
+	})
+}
+
+func handleMuteEvent(mc *ManagedClient, sink EventSink, evt *events.Mute) {
+	muted := evt.Action.GetMuted()
+
+	// The mute end is in milliseconds, -1 means muted until unmuted
+	var mutedUntil *time.Time
+	if end := evt.Action.GetMuteEndTimestamp(); muted && end > 0 {
+		t := time.UnixMilli(end)
+		mutedUntil = &t
+	}
+
+	changed, err := mc.manager.store.UpdateChatState(mc.WaAccountID, evt.JID.String(), store.ChatStateUpdate{
+		Muted:      &muted,
+		MutedUntil: mutedUntil,
+	})
+	if err != nil {
+		logChatStateError(mc, err, evt.JID)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	details := webhooks.ChatStateChangedEvent{
+		Muted: &muted,
+	}
+	if mutedUntil != nil {
+		details.MutedUntil = mutedUntil.Unix()
+	}
+
+	sendChatStateChanged(mc, sink, evt.JID, "mute", evt.Timestamp, evt.FromFullSync, details)
+}
+
+func handleStarEvent(mc *ManagedClient, sink EventSink, evt *events.Star) {
+	starred := evt.Action.GetStarred()
+	changed, err := mc.manager.store.SetMessageStarred(mc.WaAccountID, evt.ChatJID.String(), evt.MessageID,
+		evt.SenderJID.String(), evt.IsFromMe, starred)
+	if err != nil {
+		logChatStateError(mc, err, evt.ChatJID)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	details := webhooks.ChatStateChangedEvent{
+		MessageID: evt.MessageID,
+		FromMe:    &evt.IsFromMe,
+		Starred:   &starred,
+	}
+	if !evt.SenderJID.IsEmpty() {
+		details.Sender = evt.SenderJID.String()
+	}
+
+	sendChatStateChanged(mc, sink, evt.ChatJID, "star", evt.Timestamp, evt.FromFullSync, details)
+}
+
+func handleLabelAssociationChatEvent(mc *ManagedClient, sink EventSink, evt *events.LabelAssociationChat) {
+	labeled := evt.Action.GetLabeled()
+	changed, err := mc.manager.store.SetChatLabel(mc.WaAccountID, evt.JID.String(), evt.LabelID, labeled)
+	if err != nil {
+		logChatStateError(mc, err, evt.JID)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	sendChatStateChanged(mc, sink, evt.JID, "label", evt.Timestamp, evt.FromFullSync, webhooks.ChatStateChangedEvent{
+		LabelID: evt.LabelID,
+		Labeled: &labeled,
+	})
+}
+
+func handleLabelAssociationMessageEvent(mc *ManagedClient, sink EventSink, evt *events.LabelAssociationMessage) {
+	labeled := evt.Action.GetLabeled()
+	changed, err := mc.manager.store.SetMessageLabel(mc.WaAccountID, evt.JID.String(), evt.MessageID, evt.LabelID, labeled)
+	if err != nil {
+		logChatStateError(mc, err, evt.JID)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	sendChatStateChanged(mc, sink, evt.JID, "message_label", evt.Timestamp, evt.FromFullSync, webhooks.ChatStateChangedEvent{
+		MessageID: evt.MessageID,
+		LabelID:   evt.LabelID,
+		Labeled:   &labeled,
+	})
+}
+
+func handleLabelEditEvent(mc *ManagedClient, sink EventSink, evt *events.LabelEdit) {
+	name := evt.Action.GetName()
+	color := int(evt.Action.GetColor())
+	deleted := evt.Action.GetDeleted()
+
+	changed, err := mc.manager.store.SaveLabel(mc.WaAccountID, evt.LabelID, name, color, deleted)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", mc.WaAccountID).Str("label_id", evt.LabelID).Msg("Failed to store label")
+		return
+	}
+	if !changed {
+		return
+	}
+
+	sink.Publish("chat_state", webhooks.NewPayload(mc.WaAccountID, "", webhooks.LabelUpdatedEvent{
+		Event:        "label_updated",
+		LabelID:      evt.LabelID,
+		Name:         name,
+		Color:        color,
+		Deleted:      deleted,
+		Timestamp:    evt.Timestamp.Unix(),
+		FromFullSync: evt.FromFullSync,
+	}))
+}
+
+// sendChatStateChanged emits a chat_state_changed webhook describing one change,
+// whose fields are set in details, together with the full current state of the chat
+func sendChatStateChanged(mc *ManagedClient, sink EventSink, chat types.JID, change string, timestamp time.Time, fromFullSync bool, details webhooks.ChatStateChangedEvent) {
+	details.Event = "chat_state_changed"
+	details.Chat = chat.String()
+	details.Change = change
+	details.Timestamp = timestamp.Unix()
+	details.FromFullSync = fromFullSync
+
+	state, err := mc.manager.store.GetChatState(mc.WaAccountID, chat.String())
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", mc.WaAccountID).Str("chat", chat.String()).Msg("Failed to load chat state")
+	} else if state != nil {
+		details.State = chatStatePayload(state)
+	}
+
+	log.Debug().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("chat", chat.String()).
+		Str("change", change).
+		Msg("Chat state changed")
+
+	sink.Publish("chat_state", webhooks.NewPayload(mc.WaAccountID, "", details))
+}
+
+func chatStatePayload(state *store.ChatState) *webhooks.ChatState {
+	payload := &webhooks.ChatState{
+		Archived: state.Archived,
+		Pinned:   state.Pinned,
+		Muted:    state.Muted,
+		Labels:   state.Labels,
+	}
+	if state.MutedUntil != nil {
+		payload.MutedUntil = state.MutedUntil.Unix()
+	}
+	return payload
+}
+
+func logChatStateError(mc *ManagedClient, err error, chat types.JID) {
+	log.Error().
+		Err(err).
+		Str("wa_account_id", mc.WaAccountID).
+		Str("chat", chat.String()).
+		Msg("Failed to update chat state")
+}
@@ -14,19 +14,18 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/config"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
-	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
 	"go.mau.fi/whatsmeow"
 	waLog "go.mau.fi/whatsmeow/util/log"
 )
 
 type ClientManager struct {
-	clients       map[string]*ManagedClient
-	mu            sync.RWMutex
-	store         *store.PostgresStore
-	config        *config.Config
-	webhookSender *webhooks.Sender
-	stopChan      chan struct{}
-	wg            sync.WaitGroup
+	clients  map[string]*ManagedClient
+	mu       sync.RWMutex
+	store    *store.PostgresStore
+	config   *config.Config
+	events   *EventDispatcher
+	stopChan chan struct{}
+	wg       sync.WaitGroup
 }
 
 type ManagedClient struct {
@@ -35,22 +34,33 @@ type ManagedClient struct {
 	LastActivity time.Time
 	Connected    bool
 	mu           sync.RWMutex
+	manager      *ClientManager
+
+	// seenCalls holds the IDs of recent calls, the call policy is applied
+	// once per call though it can arrive as an offer and an offer notice
+	seenCalls map[string]time.Time
+	callsMu   sync.Mutex
 }
 
-func NewClientManager(store *store.PostgresStore, cfg *config.Config, webhookSender *webhooks.Sender) *ClientManager {
+func NewClientManager(store *store.PostgresStore, cfg *config.Config, sinks ...EventSink) *ClientManager {
 	cm := &ClientManager{
-		clients:       make(map[string]*ManagedClient),
-		store:         store,
-		config:        cfg,
-		webhookSender: webhookSender,
-		stopChan:      make(chan struct{}),
+		clients:  make(map[string]*ManagedClient),
+		store:    store,
+		config:   cfg,
+		events:   NewEventDispatcher(sinks...),
+		stopChan: make(chan struct{}),
 	}
 
 	// Start idle session cleanup goroutine
 	cm.wg.Add(1)
 	go cm.cleanupIdleSessions()
 
-	log.Info().Msg("Client manager initialized with webhook integration")
+	if cfg.LiveMessageRetention > 0 {
+		cm.wg.Add(1)
+		go cm.pruneLiveMessages()
+	}
+
+	log.Info().Int("event_sinks", len(sinks)).Msg("Client manager initialized")
 
 	return cm
 }
@@ -91,10 +101,11 @@ func (cm *ClientManager) GetOrCreateClient(ctx context.Context, waAccountID stri
 		WaAccountID:  waAccountID,
 		LastActivity: time.Now(),
 		Connected:    false,
+		manager:      cm,
 	}
 
 	// Setup event handlers for this client
-	SetupEventHandlers(mc, cm.webhookSender)
+	SetupEventHandlers(mc, cm.events)
 
 	cm.clients[waAccountID] = mc
 
@@ -0,0 +1,79 @@
+package wa
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// ListDirectoryContacts returns a page of the cached contact directory of an account
+func (cm *ClientManager) ListDirectoryContacts(waAccountID, search string, changedSince time.Time, limit, offset int) ([]store.DirectoryContact, int, error) {
+	return cm.store.ListDirectoryContacts(waAccountID, search, changedSince, limit, offset)
+}
+
+func handlePushNameEvent(mc *ManagedClient, sink EventSink, evt *events.PushName) {
+	updateContact(mc, sink, evt.JID, store.NameSourcePushName, evt.OldPushName, store.ContactNameUpdate{
+		PushName: &evt.NewPushName,
+	})
+}
+
+func handleBusinessNameEvent(mc *ManagedClient, sink EventSink, evt *events.BusinessName) {
+	updateContact(mc, sink, evt.JID, store.NameSourceBusiness, evt.OldBusinessName, store.ContactNameUpdate{
+		BusinessName: &evt.NewBusinessName,
+	})
+}
+
+func handleContactEvent(mc *ManagedClient, sink EventSink, evt *events.Contact) {
+	// A nil action means the contact was removed from the address book
+	fullName := evt.Action.GetFullName()
+	firstName := evt.Action.GetFirstName()
+
+	updateContact(mc, sink, evt.JID, store.NameSourceAddressBook, "", store.ContactNameUpdate{
+		FullName:  &fullName,
+		FirstName: &firstName,
+	})
+}
+
+// updateContact writes a name change to the contact directory and emits a
+// contact_updated webhook if the stored contact actually changed
+func updateContact(mc *ManagedClient, sink EventSink, jid types.JID, source, previous string, update store.ContactNameUpdate) {
+	jid = jid.ToNonAD()
+
+	contact, changed, err := mc.manager.store.UpdateContactNames(mc.WaAccountID, jid.String(), update)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("wa_account_id", mc.WaAccountID).
+			Str("jid", jid.String()).
+			Msg("Failed to update contact directory")
+		return
+	}
+
+	if !changed {
+		return
+	}
+
+	log.Debug().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("jid", jid.String()).
+		Str("source", source).
+		Msg("Contact updated")
+
+	sink.Publish("contact", webhooks.NewPayload(mc.WaAccountID, "", webhooks.ContactUpdatedEvent{
+		Event:         "contact_updated",
+		JID:           contact.JID,
+		Changed:       source,
+		Name:          contact.Name,
+		NameSource:    contact.NameSource,
+		FullName:      contact.FullName,
+		FirstName:     contact.FirstName,
+		PushName:      contact.PushName,
+		BusinessName:  contact.BusinessName,
+		LastChangedAt: contact.LastChangedAt.Unix(),
+		Previous:      previous,
+	}))
+}
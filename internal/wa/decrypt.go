@@ -0,0 +1,110 @@
+package wa
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func handleUndecryptableMessageEvent(mc *ManagedClient, sink EventSink, evt *events.UndecryptableMessage) {
+	info := evt.Info
+	undecryptableMessages.WithLabelValues(strconv.FormatBool(evt.IsUnavailable)).Inc()
+
+	failures, err := mc.manager.store.RecordDecryptFailure(mc.WaAccountID, &store.DecryptFailure{
+		MessageID:       info.ID,
+		ChatJID:         info.Chat.String(),
+		SenderJID:       info.Sender.String(),
+		Unavailable:     evt.IsUnavailable,
+		UnavailableType: string(evt.UnavailableType),
+		FailMode:        string(evt.DecryptFailMode),
+		FailedAt:        info.Timestamp,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", mc.WaAccountID).Str("message_id", info.ID).Msg("Failed to record decrypt failure")
+	}
+
+	log.Warn().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("message_id", info.ID).
+		Str("from", info.Sender.String()).
+		Bool("unavailable", evt.IsUnavailable).
+		Int("failures", failures).
+		Msg("Failed to decrypt message")
+
+	sink.Publish("encryption", webhooks.NewPayload(mc.WaAccountID, "", webhooks.UndecryptableMessageEvent{
+		Event:           "undecryptable_message",
+		MessageID:       info.ID,
+		From:            info.Sender.String(),
+		Chat:            info.Chat.String(),
+		Timestamp:       info.Timestamp.Unix(),
+		IsGroup:         info.IsGroup,
+		Unavailable:     evt.IsUnavailable,
+		UnavailableType: string(evt.UnavailableType),
+		Hidden:          evt.DecryptFailMode == events.DecryptFailHide,
+		Failures:        failures,
+	}))
+}
+
+func handleIdentityChangeEvent(mc *ManagedClient, sink EventSink, evt *events.IdentityChange) {
+	identityChanges.WithLabelValues(strconv.FormatBool(evt.Implicit)).Inc()
+
+	if err := mc.manager.store.RecordIdentityChange(mc.WaAccountID, evt.JID.String(), evt.Implicit, evt.Timestamp); err != nil {
+		log.Error().Err(err).Str("wa_account_id", mc.WaAccountID).Msg("Failed to record identity change")
+	}
+
+	log.Info().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("jid", evt.JID.String()).
+		Bool("implicit", evt.Implicit).
+		Msg("Contact identity changed")
+
+	sink.Publish("encryption", webhooks.NewPayload(mc.WaAccountID, "", webhooks.IdentityChangedEvent{
+		Event:     "identity_changed",
+		JID:       evt.JID.String(),
+		Implicit:  evt.Implicit,
+		Timestamp: evt.Timestamp.Unix(),
+	}))
+}
+
+// checkDecryptRecovery correlates a successfully decrypted message with an
+// earlier decrypt failure of the same message ID. Only redeliveries (retried
+// or requested from the phone) are checked to keep the normal path off the database.
+func checkDecryptRecovery(mc *ManagedClient, sink EventSink, evt *events.Message) {
+	if evt.RetryCount == 0 && evt.UnavailableRequestID == "" {
+		return
+	}
+
+	failure, err := mc.manager.store.MarkDecryptRecovered(mc.WaAccountID, evt.Info.ID)
+	if err != nil {
+		log.Error().Err(err).Str("wa_account_id", mc.WaAccountID).Str("message_id", evt.Info.ID).Msg("Failed to check decrypt recovery")
+		return
+	}
+	if failure == nil {
+		return
+	}
+
+	decryptRecoveries.Inc()
+
+	log.Info().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("message_id", evt.Info.ID).
+		Int("retry_count", evt.RetryCount).
+		Dur("delay", failure.RecoveredAt.Sub(failure.FailedAt)).
+		Msg("Recovered previously undecryptable message")
+
+	sink.Publish("encryption", webhooks.NewPayload(mc.WaAccountID, "", webhooks.DecryptRecoveredEvent{
+		Event:        "decrypt_recovered",
+		MessageID:    evt.Info.ID,
+		From:         failure.SenderJID,
+		Chat:         failure.ChatJID,
+		Failures:     failure.Failures,
+		RetryCount:   evt.RetryCount,
+		FailedAt:     failure.FailedAt.Unix(),
+		RecoveredAt:  failure.RecoveredAt.Unix(),
+		DelaySeconds: int64(failure.RecoveredAt.Sub(failure.FailedAt) / time.Second),
+	}))
+}
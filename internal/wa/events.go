@@ -12,13 +12,16 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
 )
 
 // SetupEventHandlers configures event handlers for a managed WhatsApp client
-func SetupEventHandlers(mc *ManagedClient, webhookSender *webhooks.Sender) {
+func SetupEventHandlers(mc *ManagedClient, sink EventSink) {
 	mc.Client.AddEventHandler(func(evt interface{}) {
-		handleEvent(mc, webhookSender, evt)
+		handleEvent(mc, sink, evt)
 	})
 
 	log.Info().
@@ -26,28 +29,64 @@ func SetupEventHandlers(mc *ManagedClient, webhookSender *webhooks.Sender) {
 		Msg("Event handlers registered for client")
 }
 
-func handleEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt interface{}) {
+func handleEvent(mc *ManagedClient, sink EventSink, evt interface{}) {
 	switch v := evt.(type) {
 	case *events.Message:
-		handleMessageEvent(mc, webhookSender, v)
+		handleMessageEvent(mc, sink, v)
 	case *events.Receipt:
-		handleReceiptEvent(mc, webhookSender, v)
+		handleReceiptEvent(mc, sink, v)
 	case *events.Connected:
-		handleConnectedEvent(mc, webhookSender)
+		handleConnectedEvent(mc, sink)
 	case *events.Disconnected:
-		handleDisconnectedEvent(mc, webhookSender)
+		handleDisconnectedEvent(mc, sink)
 	case *events.LoggedOut:
-		handleLoggedOutEvent(mc, webhookSender, v)
+		handleLoggedOutEvent(mc, sink, v)
 	case *events.StreamReplaced:
-		handleStreamReplacedEvent(mc, webhookSender)
+		handleStreamReplacedEvent(mc, sink)
 	case *events.QR:
-		handleQREvent(mc, webhookSender, v)
+		handleQREvent(mc, sink, v)
 	case *events.PairSuccess:
-		handlePairSuccessEvent(mc, webhookSender, v)
+		handlePairSuccessEvent(mc, sink, v)
 	case *events.GroupInfo:
-		handleGroupInfoEvent(mc, webhookSender, v)
+		handleGroupInfoEvent(mc, sink, v)
 	case *events.JoinedGroup:
-		handleJoinedGroupEvent(mc, webhookSender, v)
+		handleJoinedGroupEvent(mc, sink, v)
+	case *events.HistorySync:
+		handleHistorySyncEvent(mc, sink, v)
+	case *events.PushName:
+		handlePushNameEvent(mc, sink, v)
+	case *events.BusinessName:
+		handleBusinessNameEvent(mc, sink, v)
+	case *events.Contact:
+		handleContactEvent(mc, sink, v)
+	case *events.Archive:
+		handleArchiveEvent(mc, sink, v)
+	case *events.Pin:
+		handlePinEvent(mc, sink, v)
+	case *events.Mute:
+		handleMuteEvent(mc, sink, v)
+	case *events.Star:
+		handleStarEvent(mc, sink, v)
+	case *events.LabelEdit:
+		handleLabelEditEvent(mc, sink, v)
+	case *events.LabelAssociationChat:
+		handleLabelAssociationChatEvent(mc, sink, v)
+	case *events.LabelAssociationMessage:
+		handleLabelAssociationMessageEvent(mc, sink, v)
+	case *events.CallOffer:
+		handleCallOfferEvent(mc, sink, v)
+	case *events.CallOfferNotice:
+		handleCallOfferNoticeEvent(mc, sink, v)
+	case *events.CallAccept:
+		handleCallAcceptEvent(mc, sink, v)
+	case *events.CallTerminate:
+		handleCallTerminateEvent(mc, sink, v)
+	case *events.CallReject:
+		handleCallRejectEvent(mc, sink, v)
+	case *events.UndecryptableMessage:
+		handleUndecryptableMessageEvent(mc, sink, v)
+	case *events.IdentityChange:
+		handleIdentityChangeEvent(mc, sink, v)
 	default:
 		// Log unhandled events for debugging
 		log.Debug().
@@ -57,7 +96,7 @@ func handleEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt interfac
 	}
 }
 
-func handleMessageEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *events.Message) {
+func handleMessageEvent(mc *ManagedClient, sink EventSink, evt *events.Message) {
 	mc.mu.Lock()
 	mc.LastActivity = time.Now()
 	mc.mu.Unlock()
@@ -71,51 +110,17 @@ func handleMessageEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *
 		Msg("Received message event")
 
 	// Prepare webhook payload
-	payload := map[string]interface{}{
-		"event":      "message",
-		"message_id": messageInfo.ID,
-		"from":       messageInfo.Sender.String(),
-		"chat":       messageInfo.Chat.String(),
-		"timestamp":  messageInfo.Timestamp.Unix(),
-		"from_me":    messageInfo.IsFromMe,
+	message := webhooks.MessageEvent{
+		MessageID: messageInfo.ID,
+		From:      messageInfo.Sender.String(),
+		Chat:      messageInfo.Chat.String(),
+		Timestamp: messageInfo.Timestamp.Unix(),
+		FromMe:    messageInfo.IsFromMe,
 	}
 
-	// Add message content
-	if evt.Message != nil {
-		if evt.Message.Conversation != nil {
-			payload["text"] = *evt.Message.Conversation
-			payload["type"] = "text"
-		} else if evt.Message.ExtendedTextMessage != nil {
-			payload["text"] = evt.Message.ExtendedTextMessage.GetText()
-			payload["type"] = "text"
-		} else if evt.Message.ImageMessage != nil {
-			payload["type"] = "image"
-			payload["caption"] = evt.Message.ImageMessage.GetCaption()
-			payload["mime_type"] = evt.Message.ImageMessage.GetMimetype()
-		} else if evt.Message.VideoMessage != nil {
-			payload["type"] = "video"
-			payload["caption"] = evt.Message.VideoMessage.GetCaption()
-			payload["mime_type"] = evt.Message.VideoMessage.GetMimetype()
-		} else if evt.Message.AudioMessage != nil {
-			payload["type"] = "audio"
-			payload["ptt"] = evt.Message.AudioMessage.GetPTT()
-		} else if evt.Message.DocumentMessage != nil {
-			payload["type"] = "document"
-			payload["filename"] = evt.Message.DocumentMessage.GetFileName()
-			payload["mime_type"] = evt.Message.DocumentMessage.GetMimetype()
-		} else if evt.Message.StickerMessage != nil {
-			payload["type"] = "sticker"
-		} else if evt.Message.LocationMessage != nil {
-			payload["type"] = "location"
-			payload["latitude"] = evt.Message.LocationMessage.GetDegreesLatitude()
-			payload["longitude"] = evt.Message.LocationMessage.GetDegreesLongitude()
-		} else if evt.Message.ContactMessage != nil {
-			payload["type"] = "contact"
-			payload["vcard"] = evt.Message.ContactMessage.GetVcard()
-		} else {
-			payload["type"] = "unknown"
-		}
-	}
+	addMessageContent(&message, evt.Message)
+	checkDecryptRecovery(mc, sink, evt)
+	mc.saveMessage(messageInfo, message)
 
 	// Mark message as read if it's not from us
 	if !messageInfo.IsFromMe && messageInfo.IsGroup {
@@ -127,42 +132,77 @@ func handleMessageEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *
 		}
 	}
 
-	// Send webhook using Send method
-	webhookSender.Send("inbound", webhooks.WebhookPayload{
-		EventType:   "message",
-		WaAccountID: mc.WaAccountID,
-		Data:        payload,
-	})
+	// Publish to the configured event sinks
+	sink.Publish("inbound", webhooks.InboundPayload(mc.WaAccountID, "", message))
 }
 
-func handleReceiptEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *events.Receipt) {
+// addMessageContent sets the type and content fields of a message event
+func addMessageContent(message *webhooks.MessageEvent, msg *waE2E.Message) {
+	if msg == nil {
+		return
+	}
+
+	if msg.Conversation != nil {
+		message.Text = *msg.Conversation
+		message.Type = "text"
+	} else if msg.ExtendedTextMessage != nil {
+		message.Text = msg.ExtendedTextMessage.GetText()
+		message.Type = "text"
+	} else if msg.ImageMessage != nil {
+		message.Type = "image"
+		message.Caption = msg.ImageMessage.GetCaption()
+		message.MimeType = msg.ImageMessage.GetMimetype()
+	} else if msg.VideoMessage != nil {
+		message.Type = "video"
+		message.Caption = msg.VideoMessage.GetCaption()
+		message.MimeType = msg.VideoMessage.GetMimetype()
+	} else if msg.AudioMessage != nil {
+		message.Type = "audio"
+		message.PTT = proto.Bool(msg.AudioMessage.GetPTT())
+	} else if msg.DocumentMessage != nil {
+		message.Type = "document"
+		message.Filename = msg.DocumentMessage.GetFileName()
+		message.MimeType = msg.DocumentMessage.GetMimetype()
+	} else if msg.StickerMessage != nil {
+		message.Type = "sticker"
+	} else if msg.LocationMessage != nil {
+		message.Type = "location"
+		message.Latitude = proto.Float64(msg.LocationMessage.GetDegreesLatitude())
+		message.Longitude = proto.Float64(msg.LocationMessage.GetDegreesLongitude())
+	} else if msg.ContactMessage != nil {
+		message.Type = "contact"
+		message.VCard = msg.ContactMessage.GetVcard()
+	} else {
+		message.Type = "unknown"
+	}
+}
+
+func handleReceiptEvent(mc *ManagedClient, sink EventSink, evt *events.Receipt) {
 	log.Debug().
 		Str("wa_account_id", mc.WaAccountID).
 		Str("chat", evt.Chat.String()).
 		Str("type", string(evt.Type)).
 		Msg("Received receipt event")
 
-	payload := map[string]interface{}{
-		"event":       "receipt",
-		"chat":        evt.Chat.String(),
-		"type":        string(evt.Type),
-		"timestamp":   evt.Timestamp.Unix(),
-		"message_ids": evt.MessageIDs,
+	receipt := webhooks.ReceiptEvent{
+		Event:      "receipt",
+		Chat:       evt.Chat.String(),
+		Type:       string(evt.Type),
+		Timestamp:  evt.Timestamp.Unix(),
+		MessageIDs: evt.MessageIDs,
 	}
 
 	// Check if Sender is a valid JID (not empty)
 	if !evt.Sender.IsEmpty() {
-		payload["sender"] = evt.Sender.String()
+		receipt.Sender = evt.Sender.String()
 	}
 
-	webhookSender.Send("receipt", webhooks.WebhookPayload{
-		EventType:   "receipt",
-		WaAccountID: mc.WaAccountID,
-		Data:        payload,
-	})
+	sink.Publish("receipt", webhooks.NewPayload(mc.WaAccountID, "", receipt))
+
+	trackReceipt(mc, sink, evt)
 }
 
-func handleConnectedEvent(mc *ManagedClient, webhookSender *webhooks.Sender) {
+func handleConnectedEvent(mc *ManagedClient, sink EventSink) {
 	mc.mu.Lock()
 	mc.Connected = true
 	mc.LastActivity = time.Now()
@@ -172,10 +212,10 @@ func handleConnectedEvent(mc *ManagedClient, webhookSender *webhooks.Sender) {
 		Str("wa_account_id", mc.WaAccountID).
 		Msg("WhatsApp client connected")
 
-	webhookSender.SendStatus(mc.WaAccountID, "connected", "")
+	sink.Publish("status", webhooks.StatusPayload(mc.WaAccountID, "connected", ""))
 }
 
-func handleDisconnectedEvent(mc *ManagedClient, webhookSender *webhooks.Sender) {
+func handleDisconnectedEvent(mc *ManagedClient, sink EventSink) {
 	mc.mu.Lock()
 	mc.Connected = false
 	mc.mu.Unlock()
@@ -184,10 +224,10 @@ func handleDisconnectedEvent(mc *ManagedClient, webhookSender *webhooks.Sender)
 		Str("wa_account_id", mc.WaAccountID).
 		Msg("WhatsApp client disconnected")
 
-	webhookSender.SendStatus(mc.WaAccountID, "disconnected", "")
+	sink.Publish("status", webhooks.StatusPayload(mc.WaAccountID, "disconnected", ""))
 }
 
-func handleLoggedOutEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *events.LoggedOut) {
+func handleLoggedOutEvent(mc *ManagedClient, sink EventSink, evt *events.LoggedOut) {
 	mc.mu.Lock()
 	mc.Connected = false
 	mc.mu.Unlock()
@@ -203,10 +243,10 @@ func handleLoggedOutEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt
 		Str("reason", reason).
 		Msg("WhatsApp client logged out")
 
-	webhookSender.SendStatus(mc.WaAccountID, "logged_out", reason)
+	sink.Publish("status", webhooks.StatusPayload(mc.WaAccountID, "logged_out", reason))
 }
 
-func handleStreamReplacedEvent(mc *ManagedClient, webhookSender *webhooks.Sender) {
+func handleStreamReplacedEvent(mc *ManagedClient, sink EventSink) {
 	mc.mu.Lock()
 	mc.Connected = false
 	mc.mu.Unlock()
@@ -215,101 +255,186 @@ func handleStreamReplacedEvent(mc *ManagedClient, webhookSender *webhooks.Sender
 		Str("wa_account_id", mc.WaAccountID).
 		Msg("WhatsApp stream replaced (logged in from another device)")
 
-	webhookSender.SendStatus(mc.WaAccountID, "stream_replaced", "Logged in from another device")
+	sink.Publish("status", webhooks.StatusPayload(mc.WaAccountID, "stream_replaced", "Logged in from another device"))
 }
 
-func handleQREvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *events.QR) {
+func handleQREvent(mc *ManagedClient, sink EventSink, evt *events.QR) {
 	log.Debug().
 		Str("wa_account_id", mc.WaAccountID).
 		Msg("QR code event received")
 
-	payload := map[string]interface{}{
-		"event": "qr",
-		"codes": evt.Codes,
-	}
-
-	webhookSender.Send("qr", webhooks.WebhookPayload{
-		EventType:   "qr",
-		WaAccountID: mc.WaAccountID,
-		Data:        payload,
-	})
+	sink.Publish("qr", webhooks.NewPayload(mc.WaAccountID, "", webhooks.QREvent{
+		Event: "qr",
+		Codes: evt.Codes,
+	}))
 }
 
-func handlePairSuccessEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *events.PairSuccess) {
+func handlePairSuccessEvent(mc *ManagedClient, sink EventSink, evt *events.PairSuccess) {
 	log.Info().
 		Str("wa_account_id", mc.WaAccountID).
 		Str("jid", evt.ID.String()).
 		Str("business_name", evt.BusinessName).
 		Msg("Pairing successful")
 
-	payload := map[string]interface{}{
-		"event":         "pair_success",
-		"jid":           evt.ID.String(),
-		"business_name": evt.BusinessName,
-		"platform":      evt.Platform,
-	}
-
-	webhookSender.Send("pair_success", webhooks.WebhookPayload{
-		EventType:   "pair_success",
-		WaAccountID: mc.WaAccountID,
-		Data:        payload,
-	})
+	sink.Publish("pair_success", webhooks.NewPayload(mc.WaAccountID, "", webhooks.PairSuccessEvent{
+		Event:        "pair_success",
+		JID:          evt.ID.String(),
+		BusinessName: evt.BusinessName,
+		Platform:     evt.Platform,
+	}))
 }
 
-func handleGroupInfoEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *events.GroupInfo) {
+func handleGroupInfoEvent(mc *ManagedClient, sink EventSink, evt *events.GroupInfo) {
 	log.Debug().
 		Str("wa_account_id", mc.WaAccountID).
 		Str("group_jid", evt.JID.String()).
 		Msg("Group info event received")
 
-	// Based on actual types.GroupInfo usage in handlers
-	payload := map[string]interface{}{
-		"event":     "group_info",
-		"group_jid": evt.JID.String(),
-		"name":      evt.Name,
-		"topic":     evt.Topic,
+	info := webhooks.GroupInfoEvent{
+		Event:     "group_info",
+		GroupJID:  evt.JID.String(),
+		Chat:      evt.JID.String(),
+		Timestamp: evt.Timestamp.Unix(),
 	}
 
-	// Add optional fields if they have values
-	if !evt.OwnerJID.IsEmpty() {
-		payload["owner"] = evt.OwnerJID.String()
+	// Each GroupInfo event only carries the fields that changed
+	if evt.Sender != nil {
+		info.Sender = evt.Sender.String()
 	}
-
-	// GroupCreated is time.Time, check if it's zero
-	if !evt.GroupCreated.IsZero() {
-		payload["created"] = evt.GroupCreated.Unix()
+	if evt.Name != nil {
+		info.Name = &evt.Name.Name
 	}
-
-	// Participants is a slice
-	if len(evt.Participants) > 0 {
-		payload["participants_count"] = len(evt.Participants)
+	if evt.Topic != nil {
+		info.Topic = &evt.Topic.Topic
+	}
+	if len(evt.Join) > 0 {
+		info.Joined = jidStrings(evt.Join)
+	}
+	if len(evt.Leave) > 0 {
+		info.Left = jidStrings(evt.Leave)
+	}
+	if len(evt.Promote) > 0 {
+		info.Promoted = jidStrings(evt.Promote)
+	}
+	if len(evt.Demote) > 0 {
+		info.Demoted = jidStrings(evt.Demote)
 	}
 
-	webhookSender.Send("group_info", webhooks.WebhookPayload{
-		EventType:   "group_info",
-		WaAccountID: mc.WaAccountID,
-		Data:        payload,
-	})
+	sink.Publish("group_info", webhooks.NewPayload(mc.WaAccountID, "", info))
 }
 
-func handleJoinedGroupEvent(mc *ManagedClient, webhookSender *webhooks.Sender, evt *events.JoinedGroup) {
+func handleJoinedGroupEvent(mc *ManagedClient, sink EventSink, evt *events.JoinedGroup) {
 	log.Info().
 		Str("wa_account_id", mc.WaAccountID).
 		Str("group_jid", evt.JID.String()).
 		Msg("Joined group event")
 
-	payload := map[string]interface{}{
-		"event":     "joined_group",
-		"group_jid": evt.JID.String(),
+	joined := webhooks.JoinedGroupEvent{
+		Event:             "joined_group",
+		GroupJID:          evt.JID.String(),
+		Chat:              evt.JID.String(),
+		Name:              evt.Name,
+		ParticipantsCount: len(evt.Participants),
 	}
 
-	if !evt.CreateTime.IsZero() {
-		payload["created_at"] = evt.CreateTime.Unix()
+	if !evt.OwnerJID.IsEmpty() {
+		joined.Owner = evt.OwnerJID.String()
+	}
+	if !evt.GroupCreated.IsZero() {
+		joined.CreatedAt = evt.GroupCreated.Unix()
 	}
 
-	webhookSender.Send("joined_group", webhooks.WebhookPayload{
-		EventType:   "joined_group",
-		WaAccountID: mc.WaAccountID,
-		Data:        payload,
-	})
+	sink.Publish("joined_group", webhooks.NewPayload(mc.WaAccountID, "", joined))
+}
+
+func handleCallOfferEvent(mc *ManagedClient, sink EventSink, evt *events.CallOffer) {
+	log.Info().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("from", evt.From.String()).
+		Str("call_id", evt.CallID).
+		Msg("Incoming call")
+
+	call := callEvent("call_offer", evt.BasicCallMeta)
+	call.RemotePlatform = evt.RemotePlatform
+	call.RemoteVersion = evt.RemoteVersion
+
+	sink.Publish("call", webhooks.NewPayload(mc.WaAccountID, "", call))
+
+	// Rejecting involves network round trips, don't block the event loop
+	go applyCallPolicy(mc, sink, evt.BasicCallMeta)
+}
+
+func handleCallOfferNoticeEvent(mc *ManagedClient, sink EventSink, evt *events.CallOfferNotice) {
+	log.Info().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("from", evt.From.String()).
+		Str("call_id", evt.CallID).
+		Str("call_type", evt.Type).
+		Msg("Incoming call notice")
+
+	call := callEvent("call_offer", evt.BasicCallMeta)
+	call.Media = evt.Media
+	call.CallType = evt.Type
+
+	sink.Publish("call", webhooks.NewPayload(mc.WaAccountID, "", call))
+
+	go applyCallPolicy(mc, sink, evt.BasicCallMeta)
+}
+
+func handleCallAcceptEvent(mc *ManagedClient, sink EventSink, evt *events.CallAccept) {
+	log.Debug().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("call_id", evt.CallID).
+		Msg("Call accepted")
+
+	sink.Publish("call", webhooks.NewPayload(mc.WaAccountID, "", callEvent("call_accept", evt.BasicCallMeta)))
+}
+
+func handleCallTerminateEvent(mc *ManagedClient, sink EventSink, evt *events.CallTerminate) {
+	log.Debug().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("call_id", evt.CallID).
+		Str("reason", evt.Reason).
+		Msg("Call terminated")
+
+	call := callEvent("call_terminate", evt.BasicCallMeta)
+	call.Reason = evt.Reason
+
+	sink.Publish("call", webhooks.NewPayload(mc.WaAccountID, "", call))
+}
+
+func handleCallRejectEvent(mc *ManagedClient, sink EventSink, evt *events.CallReject) {
+	log.Debug().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("call_id", evt.CallID).
+		Msg("Call rejected by remote party")
+
+	sink.Publish("call", webhooks.NewPayload(mc.WaAccountID, "", callEvent("call_reject", evt.BasicCallMeta)))
+}
+
+func callEvent(event string, call types.BasicCallMeta) webhooks.CallEvent {
+	payload := webhooks.CallEvent{
+		Event:     event,
+		CallID:    call.CallID,
+		From:      call.From.String(),
+		Timestamp: call.Timestamp.Unix(),
+		IsGroup:   !call.GroupJID.IsEmpty(),
+	}
+
+	if !call.CallCreator.IsEmpty() {
+		payload.CallCreator = call.CallCreator.String()
+	}
+	if !call.GroupJID.IsEmpty() {
+		payload.GroupJID = call.GroupJID.String()
+	}
+
+	return payload
+}
+
+func jidStrings(jids []types.JID) []string {
+	out := make([]string, len(jids))
+	for i, jid := range jids {
+		out[i] = jid.String()
+	}
+	return out
 }
@@ -0,0 +1,190 @@
+package wa
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"go.mau.fi/whatsmeow"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// liveMessagePruneInterval is how often live messages past the retention are deleted
+const liveMessagePruneInterval = time.Hour
+
+// GetHistorySyncProgress returns the ingestion progress of each history sync type for an account
+func (cm *ClientManager) GetHistorySyncProgress(waAccountID string) ([]store.HistorySyncProgress, error) {
+	return cm.store.GetHistorySyncProgress(waAccountID)
+}
+
+// GetStoredMessage returns a message imported by history sync or sent or
+// received within LIVE_MESSAGE_RETENTION, or nil if it isn't stored
+func (cm *ClientManager) GetStoredMessage(waAccountID string, chat types.JID, messageID string) (*store.HistoryMessage, error) {
+	return cm.store.GetHistoryMessage(waAccountID, chat.String(), messageID)
+}
+
+// handleHistorySyncEvent ingests a history sync blob sent by the phone after
+// pairing and reports the import progress
+func handleHistorySyncEvent(mc *ManagedClient, sink EventSink, evt *events.HistorySync) {
+	data := evt.Data
+	syncType := strings.ToLower(data.GetSyncType().String())
+
+	chunk := &store.HistorySyncChunk{
+		WaAccountID: mc.WaAccountID,
+		SyncType:    syncType,
+		ChunkOrder:  int(data.GetChunkOrder()),
+		Progress:    int(data.GetProgress()),
+		PushNames:   make(map[string]string),
+	}
+
+	for _, conv := range data.GetConversations() {
+		chatJID, err := types.ParseJID(conv.GetID())
+		if err != nil {
+			log.Warn().Err(err).Str("chat", conv.GetID()).Msg("Skipping history conversation with invalid JID")
+			continue
+		}
+
+		hc := store.HistoryConversation{
+			ChatJID:     chatJID.String(),
+			Name:        conv.GetName(),
+			UnreadCount: int(conv.GetUnreadCount()),
+			Archived:    conv.GetArchived(),
+			Pinned:      conv.GetPinned() > 0,
+		}
+		if muteEnd := conv.GetMuteEndTime(); muteEnd > 0 {
+			t := time.Unix(int64(muteEnd), 0)
+			hc.Muted = t.After(time.Now())
+			hc.MutedUntil = &t
+		}
+		if ts := conv.GetConversationTimestamp(); ts > 0 {
+			t := time.Unix(int64(ts), 0)
+			hc.LastMessageAt = &t
+		}
+		chunk.Conversations = append(chunk.Conversations, hc)
+
+		for _, historyMsg := range conv.GetMessages() {
+			msg, err := mc.Client.ParseWebMessage(chatJID, historyMsg.GetMessage())
+			if err != nil {
+				log.Debug().Err(err).Str("chat", chatJID.String()).Msg("Skipping unparseable history message")
+				continue
+			}
+
+			var content webhooks.MessageEvent
+			addMessageContent(&content, msg.Message)
+			chunk.Messages = append(chunk.Messages, storedMessage(chatJID, msg.Info, content))
+		}
+	}
+
+	for _, pushName := range data.GetPushnames() {
+		if pushName.GetID() != "" && pushName.GetPushname() != "" {
+			chunk.PushNames[pushName.GetID()] = pushName.GetPushname()
+		}
+	}
+
+	progress, err := mc.manager.store.SaveHistorySyncChunk(chunk)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("wa_account_id", mc.WaAccountID).
+			Str("sync_type", syncType).
+			Msg("Failed to store history sync chunk")
+		return
+	}
+
+	log.Info().
+		Str("wa_account_id", mc.WaAccountID).
+		Str("sync_type", syncType).
+		Uint32("chunk_order", data.GetChunkOrder()).
+		Int("progress", progress.Progress).
+		Int("conversations", len(chunk.Conversations)).
+		Int("messages", len(chunk.Messages)).
+		Msg("History sync chunk ingested")
+
+	sink.Publish("history_sync", webhooks.NewPayload(mc.WaAccountID, "", webhooks.HistorySyncProgressEvent{
+		Event:              "history_sync_progress",
+		SyncType:           syncType,
+		ChunkOrder:         data.GetChunkOrder(),
+		Progress:           progress.Progress,
+		Conversations:      len(chunk.Conversations),
+		Messages:           len(chunk.Messages),
+		PushNames:          len(chunk.PushNames),
+		TotalChunks:        progress.Chunks,
+		TotalConversations: progress.Conversations,
+		TotalMessages:      progress.Messages,
+	}))
+}
+
+// storedMessage is the row kept for a message, with its text or caption so
+// replies can quote it
+func storedMessage(chat types.JID, info types.MessageInfo, content webhooks.MessageEvent) store.HistoryMessage {
+	text := content.Text
+	if text == "" {
+		text = content.Caption
+	}
+
+	return store.HistoryMessage{
+		ChatJID:     chat.String(),
+		MessageID:   info.ID,
+		SenderJID:   info.Sender.String(),
+		FromMe:      info.IsFromMe,
+		MessageType: content.Type,
+		Text:        text,
+		SentAt:      info.Timestamp,
+	}
+}
+
+// saveMessage stores a live message unless live messages aren't kept,
+// failing only costs quoting it later
+func (mc *ManagedClient) saveMessage(info types.MessageInfo, content webhooks.MessageEvent) {
+	if mc.manager.config.LiveMessageRetention <= 0 {
+		return
+	}
+
+	msg := storedMessage(info.Chat, info, content)
+	if err := mc.manager.store.SaveMessage(mc.WaAccountID, &msg); err != nil {
+		log.Error().Err(err).Str("wa_account_id", mc.WaAccountID).Str("message_id", info.ID).Msg("Failed to store message")
+	}
+}
+
+// pruneLiveMessages deletes live messages older than the retention hourly
+func (cm *ClientManager) pruneLiveMessages() {
+	defer cm.wg.Done()
+	ticker := time.NewTicker(liveMessagePruneInterval)
+	defer ticker.Stop()
+
+	for {
+		pruned, err := cm.store.PruneLiveMessages(time.Now().Add(-cm.config.LiveMessageRetention))
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to prune live messages")
+		} else if pruned > 0 {
+			log.Info().Int64("messages", pruned).Msg("Pruned live messages")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-cm.stopChan:
+			return
+		}
+	}
+}
+
+// SaveSentMessage stores a message sent through the API, messages sent by
+// this device don't come back as events
+func (mc *ManagedClient) SaveSentMessage(chat types.JID, resp whatsmeow.SendResponse, message *waE2E.Message) {
+	info := types.MessageInfo{
+		MessageSource: types.MessageSource{Chat: chat, IsFromMe: true},
+		ID:            resp.ID,
+		Timestamp:     resp.Timestamp,
+	}
+	if mc.Client.Store.ID != nil {
+		info.Sender = mc.Client.Store.ID.ToNonAD()
+	}
+
+	var content webhooks.MessageEvent
+	addMessageContent(&content, message)
+	mc.saveMessage(info, content)
+}
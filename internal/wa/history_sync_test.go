@@ -0,0 +1,39 @@
+package wa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestStoredMessage(t *testing.T) {
+	chat := types.NewJID("5511999990000", types.DefaultUserServer)
+	info := types.MessageInfo{
+		MessageSource: types.MessageSource{Chat: chat, Sender: chat, IsFromMe: false},
+		ID:            "3EB0A1",
+		Timestamp:     time.Unix(1700000000, 0),
+	}
+
+	tests := []struct {
+		name     string
+		content  webhooks.MessageEvent
+		wantType string
+		wantText string
+	}{
+		{"text", webhooks.MessageEvent{Type: "text", Text: "hello"}, "text", "hello"},
+		{"caption", webhooks.MessageEvent{Type: "image", Caption: "a photo"}, "image", "a photo"},
+		{"no text", webhooks.MessageEvent{Type: "sticker"}, "sticker", ""},
+	}
+
+	for _, tt := range tests {
+		got := storedMessage(chat, info, tt.content)
+		if got.MessageType != tt.wantType || got.Text != tt.wantText {
+			t.Errorf("%s: got type %q text %q, want %q %q", tt.name, got.MessageType, got.Text, tt.wantType, tt.wantText)
+		}
+		if got.ChatJID != chat.String() || got.MessageID != info.ID || got.SenderJID != chat.String() || !got.SentAt.Equal(info.Timestamp) {
+			t.Errorf("%s: got %+v, doesn't match the message info", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package wa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"go.mau.fi/whatsmeow"
+)
+
+const uploadCachePruneInterval = time.Hour
+
+// UploadCache remembers media uploaded to the WhatsApp servers by content
+// and media type, so sending the same file again references the earlier
+// upload instead of uploading it. Uploads are reused for the TTL, a TTL of
+// zero disables the cache.
+type UploadCache struct {
+	store *store.PostgresStore
+	ttl   time.Duration
+
+	// inflight holds the uploads in progress, concurrent sends of the same
+	// content wait for the first upload instead of starting their own
+	mu       sync.Mutex
+	inflight map[string]*pendingUpload
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type pendingUpload struct {
+	done     chan struct{}
+	response whatsmeow.UploadResponse
+	err      error
+}
+
+func NewUploadCache(store *store.PostgresStore, ttl time.Duration) *UploadCache {
+	c := &UploadCache{
+		store:    store,
+		ttl:      ttl,
+		inflight: make(map[string]*pendingUpload),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go c.run()
+
+	return c
+}
+
+// Upload uploads data with client, or returns the earlier upload of the same
+// content and media type. The cache failing only costs the upload.
+func (c *UploadCache) Upload(ctx context.Context, client *whatsmeow.Client, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
+	if c.ttl <= 0 {
+		return client.Upload(ctx, data, mediaType)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := hash + "/" + string(mediaType)
+
+	return c.once(ctx, key, func() (whatsmeow.UploadResponse, error) {
+		return c.upload(ctx, client, data, mediaType, hash)
+	})
+}
+
+// once runs upload unless an upload of the same key is in progress, then it
+// waits for that one. A failed upload may be down to the context of the
+// caller that ran it, so its waiters try again once: the first of them
+// uploads, the others wait for it.
+func (c *UploadCache) once(ctx context.Context, key string, upload func() (whatsmeow.UploadResponse, error)) (whatsmeow.UploadResponse, error) {
+	retried := false
+	for {
+		c.mu.Lock()
+		pending, ok := c.inflight[key]
+		if !ok {
+			pending = &pendingUpload{done: make(chan struct{})}
+			c.inflight[key] = pending
+			c.mu.Unlock()
+
+			pending.response, pending.err = upload()
+
+			c.mu.Lock()
+			delete(c.inflight, key)
+			c.mu.Unlock()
+			close(pending.done)
+
+			return pending.response, pending.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-pending.done:
+			if pending.err == nil || retried {
+				return pending.response, pending.err
+			}
+			retried = true
+		case <-ctx.Done():
+			return whatsmeow.UploadResponse{}, ctx.Err()
+		}
+	}
+}
+
+func (c *UploadCache) upload(ctx context.Context, client *whatsmeow.Client, data []byte, mediaType whatsmeow.MediaType, hash string) (whatsmeow.UploadResponse, error) {
+	cached, err := c.store.GetUploadedMedia(hash, string(mediaType), time.Now().Add(-c.ttl))
+	if err != nil {
+		log.Error().Err(err).Str("sha256", hash).Msg("Failed to look up uploaded media")
+	}
+	if cached != nil {
+		log.Debug().Str("sha256", hash).Str("media_type", string(mediaType)).Msg("Reusing uploaded media")
+		return whatsmeow.UploadResponse{
+			URL:           cached.URL,
+			DirectPath:    cached.DirectPath,
+			Handle:        cached.Handle,
+			ObjectID:      cached.ObjectID,
+			MediaKey:      cached.MediaKey,
+			FileEncSHA256: cached.FileEncSHA256,
+			FileSHA256:    cached.FileSHA256,
+			FileLength:    cached.FileLength,
+		}, nil
+	}
+
+	uploaded, err := client.Upload(ctx, data, mediaType)
+	if err != nil {
+		return uploaded, err
+	}
+
+	err = c.store.SaveUploadedMedia(&store.UploadedMedia{
+		SHA256:        hash,
+		MediaType:     string(mediaType),
+		URL:           uploaded.URL,
+		DirectPath:    uploaded.DirectPath,
+		Handle:        uploaded.Handle,
+		ObjectID:      uploaded.ObjectID,
+		MediaKey:      uploaded.MediaKey,
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    uploaded.FileLength,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("sha256", hash).Msg("Failed to cache uploaded media")
+	}
+
+	return uploaded, nil
+}
+
+func (c *UploadCache) run() {
+	defer close(c.done)
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(uploadCachePruneInterval)
+	defer ticker.Stop()
+
+	c.prune()
+	for {
+		select {
+		case <-ticker.C:
+			c.prune()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *UploadCache) prune() {
+	pruned, err := c.store.PruneUploadedMedia(time.Now().Add(-c.ttl))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune uploaded media")
+		return
+	}
+	if pruned > 0 {
+		log.Info().Int64("uploads", pruned).Msg("Pruned uploaded media cache")
+	}
+}
+
+// Close stops pruning expired uploads
+func (c *UploadCache) Close() error {
+	close(c.stop)
+	<-c.done
+	return nil
+}
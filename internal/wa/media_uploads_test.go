@@ -0,0 +1,106 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+)
+
+func TestUploadCacheOnce(t *testing.T) {
+	errUpload := errors.New("upload failed")
+
+	tests := []struct {
+		name      string
+		failures  int32 // uploads failing before one succeeds
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"first succeeds", 0, 1, false},
+		{"retried once", 1, 2, false},
+		{"retry fails", 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		c := &UploadCache{inflight: make(map[string]*pendingUpload)}
+		var calls atomic.Int32
+		release := make(chan struct{})
+		upload := func() (whatsmeow.UploadResponse, error) {
+			n := calls.Add(1)
+			if n == 1 {
+				<-release
+			} else {
+				// Slow enough for the other waiters to find the retry
+				time.Sleep(50 * time.Millisecond)
+			}
+			if n <= tt.failures {
+				return whatsmeow.UploadResponse{}, errUpload
+			}
+			return whatsmeow.UploadResponse{DirectPath: "/path"}, nil
+		}
+
+		go c.once(context.Background(), "key", upload)
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := c.once(context.Background(), "key", upload)
+				if err == nil && resp.DirectPath != "/path" {
+					err = errors.New("wrong response")
+				}
+				errs <- err
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s: waiter got %v, want error %v", tt.name, err, tt.wantErr)
+			}
+		}
+		if got := calls.Load(); got != tt.wantCalls {
+			t.Errorf("%s: %d uploads, want %d", tt.name, got, tt.wantCalls)
+		}
+		if len(c.inflight) != 0 {
+			t.Errorf("%s: %d uploads left in flight", tt.name, len(c.inflight))
+		}
+	}
+}
+
+func TestUploadCacheOnceCanceled(t *testing.T) {
+	c := &UploadCache{inflight: make(map[string]*pendingUpload)}
+	release := make(chan struct{})
+	defer close(release)
+	go c.once(context.Background(), "key", func() (whatsmeow.UploadResponse, error) {
+		<-release
+		return whatsmeow.UploadResponse{}, nil
+	})
+	for {
+		c.mu.Lock()
+		n := len(c.inflight)
+		c.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.once(ctx, "key", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiting with an expired context = %v, want DeadlineExceeded", err)
+	}
+}
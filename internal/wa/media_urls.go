@@ -0,0 +1,188 @@
+package wa
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/fetch"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+)
+
+// MediaURLCache keeps the content downloaded from media URLs, so sending the
+// same URL again within the TTL reuses it without a request, or only asks the
+// server whether it changed when it sent Cache-Control no-cache. Content is
+// kept in dir by its hash, the URLs and their validators in the database, for
+// the TTL since they were last fetched or revalidated.
+type MediaURLCache struct {
+	store *store.PostgresStore
+	dir   string
+	ttl   time.Duration
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func NewMediaURLCache(store *store.PostgresStore, dir string, ttl time.Duration) (*MediaURLCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create media URL cache directory: %w", err)
+	}
+
+	c := &MediaURLCache{
+		store: store,
+		dir:   dir,
+		ttl:   ttl,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	go c.run()
+
+	return c, nil
+}
+
+// Lookup returns the content last downloaded from rawURL, if it's still kept
+func (c *MediaURLCache) Lookup(rawURL string) *fetch.Cached {
+	cached, err := c.store.GetCachedMediaURL(rawURL, time.Now().Add(-c.ttl))
+	if err != nil {
+		log.Error().Err(err).Str("url", rawURL).Msg("Failed to look up cached media URL")
+		return nil
+	}
+	if cached == nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path(cached.SHA256))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Error().Err(err).Str("sha256", cached.SHA256).Msg("Failed to read cached media")
+		}
+		return nil
+	}
+
+	return &fetch.Cached{
+		ContentType:  cached.ContentType,
+		ETag:         cached.ETag,
+		LastModified: cached.LastModified,
+		Revalidate:   cached.Revalidate,
+		Data:         data,
+	}
+}
+
+// Store keeps the content downloaded from rawURL, or marks the content kept
+// as current when it was revalidated
+func (c *MediaURLCache) Store(rawURL string, response *fetch.Cached) {
+	sum := sha256.Sum256(response.Data)
+	hash := hex.EncodeToString(sum[:])
+
+	if err := c.writeContent(hash, response.Data); err != nil {
+		log.Error().Err(err).Str("sha256", hash).Msg("Failed to cache media content")
+		return
+	}
+
+	err := c.store.SaveCachedMediaURL(&store.CachedMediaURL{
+		URL:          rawURL,
+		SHA256:       hash,
+		ContentType:  response.ContentType,
+		ETag:         response.ETag,
+		LastModified: response.LastModified,
+		Revalidate:   response.Revalidate,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("url", rawURL).Msg("Failed to cache media URL")
+	}
+}
+
+// writeContent writes content under its hash, content already there is only
+// touched so pruning keeps it
+func (c *MediaURLCache) writeContent(hash string, data []byte) error {
+	path := c.path(hash)
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err == nil {
+		return nil
+	}
+
+	file, err := os.CreateTemp(c.dir, hash+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (c *MediaURLCache) path(hash string) string {
+	return filepath.Join(c.dir, hash)
+}
+
+func (c *MediaURLCache) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(uploadCachePruneInterval)
+	defer ticker.Stop()
+
+	c.prune()
+	for {
+		select {
+		case <-ticker.C:
+			c.prune()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// prune forgets URLs not fetched within the TTL and removes the content no
+// URL refers to anymore. Content written since the cutoff is left alone, its
+// URL may not be saved yet.
+func (c *MediaURLCache) prune() {
+	cutoff := time.Now().Add(-c.ttl)
+	pruned, referenced, err := c.store.PruneCachedMediaURLs(cutoff)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune cached media URLs")
+		return
+	}
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		log.Error().Err(err).Str("dir", c.dir).Msg("Failed to list media URL cache directory")
+		return
+	}
+	removed := 0
+	for _, entry := range entries {
+		if referenced[entry.Name()] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil {
+			log.Warn().Err(err).Str("file", entry.Name()).Msg("Failed to remove cached media")
+			continue
+		}
+		removed++
+	}
+
+	if pruned > 0 || removed > 0 {
+		log.Info().Int64("urls", pruned).Int("files", removed).Msg("Pruned media URL cache")
+	}
+}
+
+// Close stops pruning expired downloads
+func (c *MediaURLCache) Close() error {
+	close(c.stop)
+	<-c.done
+	return nil
+}
@@ -0,0 +1,33 @@
+package wa
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMediaURLCacheWriteContent(t *testing.T) {
+	c := &MediaURLCache{dir: t.TempDir()}
+
+	if err := c.writeContent("abc", []byte("content")); err != nil {
+		t.Fatalf("writeContent: %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(c.path("abc"), old, old); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	// Writing the same content again keeps the file and marks it as current
+	if err := c.writeContent("abc", []byte("content")); err != nil {
+		t.Fatalf("writeContent again: %v", err)
+	}
+	info, err := os.Stat(c.path("abc"))
+	if err != nil || time.Since(info.ModTime()) > time.Minute {
+		t.Errorf("content file = %v, %v, want it touched", info, err)
+	}
+	data, _ := os.ReadFile(c.path("abc"))
+	entries, _ := os.ReadDir(c.dir)
+	if string(data) != "content" || len(entries) != 1 {
+		t.Errorf("cache holds %q in %d files, want the content in one", data, len(entries))
+	}
+}
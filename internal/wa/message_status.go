@@ -0,0 +1,184 @@
+package wa
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	wastore "go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Outbound message statuses, in the order a message progresses through them
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusServerAck = "server_ack"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+	MessageStatusPlayed    = "played"
+	MessageStatusFailed    = "failed"
+)
+
+// statusRanks orders statuses so late or duplicate receipts never move a
+// recipient backwards. Failed is terminal.
+var statusRanks = map[string]int{
+	MessageStatusSent:      1,
+	MessageStatusServerAck: 2,
+	MessageStatusDelivered: 3,
+	MessageStatusRead:      4,
+	MessageStatusPlayed:    5,
+	MessageStatusFailed:    6,
+}
+
+// replacedRank is the highest rank a status moves a recipient on from. A
+// failure only applies before the message reached the recipient, a server
+// error receipt after a delivery or read doesn't undo it.
+func replacedRank(status string) int {
+	if status == MessageStatusFailed {
+		return statusRanks[MessageStatusServerAck]
+	}
+	return statusRanks[status] - 1
+}
+
+// perParticipant reports whether receipts for messages sent to chat come from
+// each participant rather than from the chat itself
+func perParticipant(chat types.JID) bool {
+	return chat.Server == types.GroupServer || chat.Server == types.BroadcastServer
+}
+
+// TrackOutboundMessage starts delivery tracking for a message about to be sent.
+// Recipients of group and broadcast messages are added as their receipts arrive.
+func (cm *ClientManager) TrackOutboundMessage(waAccountID, messageID string, chat types.JID, messageType string) error {
+	err := cm.store.CreateOutboundMessage(&store.OutboundMessage{
+		WaAccountID: waAccountID,
+		MessageID:   messageID,
+		ChatJID:     chat.String(),
+		MessageType: messageType,
+		Status:      MessageStatusSent,
+	}, statusRanks[MessageStatusSent])
+	if err != nil || perParticipant(chat) {
+		return err
+	}
+
+	return cm.advanceStatus(waAccountID, messageID, cm.recipientJID(waAccountID, chat), MessageStatusSent)
+}
+
+// SetOutboundMessageStatus records a chat-level status change reported by the
+// send path itself (server ack or failure). For group and broadcast messages
+// it only applies to the message, its recipients are unknown until they send
+// receipts.
+func (cm *ClientManager) SetOutboundMessageStatus(waAccountID, messageID string, chat types.JID, status string) error {
+	if perParticipant(chat) {
+		return cm.store.AdvanceOutboundMessage(waAccountID, messageID, status, statusRanks[status], replacedRank(status), time.Now())
+	}
+
+	return cm.advanceStatus(waAccountID, messageID, cm.recipientJID(waAccountID, chat), status)
+}
+
+func (cm *ClientManager) advanceStatus(waAccountID, messageID string, recipient types.JID, status string) error {
+	_, err := cm.store.AdvanceMessageStatus(waAccountID, messageID, recipient.String(), status, statusRanks[status], replacedRank(status), time.Now())
+	return err
+}
+
+// recipientJID returns the JID a recipient's status is kept under, see phoneJID
+func (cm *ClientManager) recipientJID(waAccountID string, jid types.JID) types.JID {
+	cm.mu.RLock()
+	mc, ok := cm.clients[waAccountID]
+	cm.mu.RUnlock()
+	if !ok || mc.Client.Store == nil {
+		return jid
+	}
+
+	return phoneJID(mc.Client.Store.LIDs, jid)
+}
+
+// phoneJID returns the phone number JID of a LID user, or the JID itself.
+// WhatsApp sends receipts from either form of a recipient, statuses are kept
+// under the phone number one so they match the recipient messages were sent to.
+func phoneJID(lids wastore.LIDStore, jid types.JID) types.JID {
+	if jid.Server != types.HiddenUserServer || lids == nil {
+		return jid
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pn, err := lids.GetPNForLID(ctx, jid)
+	if err != nil {
+		log.Warn().Err(err).Str("lid", jid.String()).Msg("Failed to look up phone number of LID")
+		return jid
+	}
+	if pn.IsEmpty() {
+		return jid
+	}
+	return pn
+}
+
+// GetMessageStatus returns the delivery report of an outbound message, or nil if it is not tracked
+func (cm *ClientManager) GetMessageStatus(waAccountID, messageID string) (*store.MessageStatusReport, error) {
+	return cm.store.GetMessageStatusReport(waAccountID, messageID)
+}
+
+func receiptStatus(receiptType types.ReceiptType) (string, bool) {
+	switch receiptType {
+	case types.ReceiptTypeDelivered:
+		return MessageStatusDelivered, true
+	case types.ReceiptTypeRead:
+		return MessageStatusRead, true
+	case types.ReceiptTypePlayed:
+		return MessageStatusPlayed, true
+	case types.ReceiptTypeServerError:
+		return MessageStatusFailed, true
+	default:
+		return "", false
+	}
+}
+
+// trackReceipt applies a receipt to the per-recipient status of tracked outbound
+// messages and emits delivery/read webhooks the first time a recipient reaches them
+func trackReceipt(mc *ManagedClient, sink EventSink, evt *events.Receipt) {
+	// Receipts from our own devices say nothing about the recipient
+	if evt.IsFromMe {
+		return
+	}
+
+	status, ok := receiptStatus(evt.Type)
+	if !ok {
+		return
+	}
+	rank, replaces := statusRanks[status], replacedRank(status)
+
+	recipient := evt.Sender.ToNonAD()
+	if recipient.IsEmpty() {
+		recipient = evt.Chat
+	}
+	recipient = phoneJID(mc.Client.Store.LIDs, recipient)
+
+	for _, messageID := range evt.MessageIDs {
+		result, err := mc.manager.store.AdvanceMessageStatus(mc.WaAccountID, messageID, recipient.String(), status, rank, replaces, evt.Timestamp)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("wa_account_id", mc.WaAccountID).
+				Str("message_id", messageID).
+				Msg("Failed to record message status")
+			continue
+		}
+
+		if !result.Tracked || !result.Advanced || status == MessageStatusFailed {
+			continue
+		}
+
+		// A played receipt can arrive without a read receipt, so emit every
+		// milestone crossed by this transition
+		if result.PreviousRank < statusRanks[MessageStatusDelivered] {
+			sink.Publish("delivery", webhooks.DeliveryPayload(mc.WaAccountID, "", messageID, evt.Chat.String(), recipient.String()))
+		}
+		if rank >= statusRanks[MessageStatusRead] && result.PreviousRank < statusRanks[MessageStatusRead] {
+			sink.Publish("read", webhooks.ReadPayload(mc.WaAccountID, "", messageID, evt.Chat.String(), recipient.String()))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	wastore "go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// fakeLIDs maps LIDs to phone numbers, other LIDStore methods are unused
+type fakeLIDs struct {
+	wastore.LIDStore
+	pns map[types.JID]types.JID
+	err error
+}
+
+func (f *fakeLIDs) GetPNForLID(_ context.Context, lid types.JID) (types.JID, error) {
+	return f.pns[lid], f.err
+}
+
+func TestPhoneJID(t *testing.T) {
+	pn := types.NewJID("5511999999999", types.DefaultUserServer)
+	lid := types.NewJID("123456789012345", types.HiddenUserServer)
+	unknownLID := types.NewJID("999", types.HiddenUserServer)
+	group := types.NewJID("120363000000000000", types.GroupServer)
+	lids := &fakeLIDs{pns: map[types.JID]types.JID{lid: pn}}
+
+	tests := []struct {
+		name string
+		lids wastore.LIDStore
+		jid  types.JID
+		want types.JID
+	}{
+		{"LID with a mapping", lids, lid, pn},
+		{"LID without a mapping", lids, unknownLID, unknownLID},
+		{"phone number", lids, pn, pn},
+		{"group", lids, group, group},
+		{"lookup error", &fakeLIDs{err: errors.New("db down")}, lid, lid},
+		{"no LID store", nil, lid, lid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := phoneJID(tt.lids, tt.jid); got != tt.want {
+				t.Errorf("phoneJID(%s) = %s, want %s", tt.jid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiptStatus(t *testing.T) {
+	tests := []struct {
+		receipt types.ReceiptType
+		status  string
+		ok      bool
+	}{
+		{types.ReceiptTypeDelivered, MessageStatusDelivered, true},
+		{types.ReceiptTypeRead, MessageStatusRead, true},
+		{types.ReceiptTypePlayed, MessageStatusPlayed, true},
+		{types.ReceiptTypeServerError, MessageStatusFailed, true},
+		{types.ReceiptTypeRetry, "", false},
+		{types.ReceiptTypeReadSelf, "", false},
+	}
+
+	for _, tt := range tests {
+		status, ok := receiptStatus(tt.receipt)
+		if status != tt.status || ok != tt.ok {
+			t.Errorf("receiptStatus(%q) = %q, %v, want %q, %v", tt.receipt, status, ok, tt.status, tt.ok)
+		}
+	}
+}
+
+func TestStatusRanks(t *testing.T) {
+	// Statuses only move forward, failed ends tracking
+	order := []string{
+		MessageStatusSent,
+		MessageStatusServerAck,
+		MessageStatusDelivered,
+		MessageStatusRead,
+		MessageStatusPlayed,
+		MessageStatusFailed,
+	}
+
+	for i := 1; i < len(order); i++ {
+		if statusRanks[order[i-1]] >= statusRanks[order[i]] {
+			t.Errorf("%s ranks %d, not below %s at %d", order[i-1], statusRanks[order[i-1]], order[i], statusRanks[order[i]])
+		}
+	}
+	if len(statusRanks) != len(order) {
+		t.Errorf("statusRanks has %d statuses, want %d", len(statusRanks), len(order))
+	}
+}
+
+func TestReplacedRank(t *testing.T) {
+	// Whether a recipient at the first status moves on to the second
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{MessageStatusSent, MessageStatusServerAck, true},
+		{MessageStatusServerAck, MessageStatusDelivered, true},
+		{MessageStatusSent, MessageStatusRead, true},
+		{MessageStatusRead, MessageStatusPlayed, true},
+		{MessageStatusRead, MessageStatusDelivered, false},
+		{MessageStatusDelivered, MessageStatusDelivered, false},
+		{MessageStatusSent, MessageStatusFailed, true},
+		{MessageStatusServerAck, MessageStatusFailed, true},
+		{MessageStatusDelivered, MessageStatusFailed, false},
+		{MessageStatusRead, MessageStatusFailed, false},
+		{MessageStatusFailed, MessageStatusRead, false},
+	}
+
+	for _, tt := range tests {
+		from, to := statusRanks[tt.from], statusRanks[tt.to]
+		if got := from < to && from <= replacedRank(tt.to); got != tt.want {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPerParticipant(t *testing.T) {
+	tests := []struct {
+		jid  types.JID
+		want bool
+	}{
+		{types.NewJID("120363000000000000", types.GroupServer), true},
+		{types.NewJID("1700000000", types.BroadcastServer), true},
+		{types.NewJID("5511999999999", types.DefaultUserServer), false},
+		{types.NewJID("123456789012345", types.HiddenUserServer), false},
+	}
+
+	for _, tt := range tests {
+		if got := perParticipant(tt.jid); got != tt.want {
+			t.Errorf("perParticipant(%s) = %v, want %v", tt.jid, got, tt.want)
+		}
+	}
+}
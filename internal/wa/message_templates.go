@@ -0,0 +1,83 @@
+package wa
+
+import (
+	"slices"
+
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/templates"
+)
+
+// TemplateTypes are the message types templates can fill in: the text, the
+// media caption or the link caption, or the poll question and options
+var TemplateTypes = []string{"text", "image", "video", "document", "link", "poll"}
+
+// RenderedTemplate is a template filled in with the variables of a message
+type RenderedTemplate struct {
+	Name    string
+	Version int
+	Type    string
+	Locale  string
+	Body    string
+	Options []string
+}
+
+// CreateTemplate stores a template version, it reports false if the version
+// already exists
+func (cm *ClientManager) CreateTemplate(t *store.MessageTemplate) (bool, error) {
+	return cm.store.CreateMessageTemplate(t)
+}
+
+// GetTemplate returns a version of a template, the latest if version is 0,
+// or nil if it doesn't exist
+func (cm *ClientManager) GetTemplate(name string, version int) (*store.MessageTemplate, error) {
+	return cm.store.GetMessageTemplate(name, version)
+}
+
+func (cm *ClientManager) ListTemplates() ([]*store.MessageTemplate, error) {
+	return cm.store.ListMessageTemplates()
+}
+
+func (cm *ClientManager) ListTemplateVersions(name string) ([]*store.MessageTemplate, error) {
+	return cm.store.ListMessageTemplateVersions(name)
+}
+
+func (cm *ClientManager) DeleteTemplate(name string) (bool, error) {
+	return cm.store.DeleteMessageTemplate(name)
+}
+
+// RenderTemplate fills in the variant of a template closest to the locale. It
+// returns nil if the template doesn't exist and a
+// *templates.MissingVariablesError if variables are missing.
+func (cm *ClientManager) RenderTemplate(name string, version int, locale string, variables map[string]string) (*RenderedTemplate, error) {
+	t, err := cm.store.GetMessageTemplate(name, version)
+	if err != nil || t == nil {
+		return nil, err
+	}
+
+	return renderTemplate(t, locale, variables)
+}
+
+// renderTemplate fills in the variant of t closest to the locale
+func renderTemplate(t *store.MessageTemplate, locale string, variables map[string]string) (*RenderedTemplate, error) {
+	locales := make([]string, 0, len(t.Variants))
+	for l := range t.Variants {
+		locales = append(locales, l)
+	}
+	locale, _ = templates.NormalizeLocale(locale)
+	locale = templates.MatchLocale(locales, locale, t.DefaultLocale)
+	variant := t.Variants[locale]
+
+	rendered, err := templates.Render(variables, slices.Concat([]string{variant.Body}, variant.Options)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RenderedTemplate{
+		Name:    t.Name,
+		Version: t.Version,
+		Type:    t.Type,
+		Locale:  locale,
+		Body:    rendered[0],
+		Options: rendered[1:],
+	}, nil
+}
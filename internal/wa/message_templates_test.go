@@ -0,0 +1,60 @@
+package wa
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/templates"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	template := &store.MessageTemplate{
+		Name:          "lunch",
+		Version:       3,
+		Type:          "poll",
+		DefaultLocale: "en",
+		Variants: map[string]store.TemplateVariant{
+			"en":    {Body: "Lunch, {{name}}?", Options: []string{"Yes", "No, {{name}} is busy"}},
+			"pt":    {Body: "Almoço, {{name}}?", Options: []string{"Sim", "Não"}},
+			"pt-br": {Body: "Bora almoçar, {{name}}?", Options: []string{"Bora", "Não"}},
+		},
+	}
+	variables := map[string]string{"name": "Ana"}
+
+	tests := []struct {
+		name        string
+		locale      string
+		wantLocale  string
+		wantBody    string
+		wantOptions []string
+	}{
+		{"exact", "pt-br", "pt-br", "Bora almoçar, Ana?", []string{"Bora", "Não"}},
+		{"unnormalized", "pt_BR", "pt-br", "Bora almoçar, Ana?", []string{"Bora", "Não"}},
+		{"language", "pt-PT", "pt", "Almoço, Ana?", []string{"Sim", "Não"}},
+		{"default", "fr", "en", "Lunch, Ana?", []string{"Yes", "No, Ana is busy"}},
+		{"none requested", "", "en", "Lunch, Ana?", []string{"Yes", "No, Ana is busy"}},
+		{"malformed", "not a locale", "en", "Lunch, Ana?", []string{"Yes", "No, Ana is busy"}},
+	}
+
+	for _, tt := range tests {
+		got, err := renderTemplate(template, tt.locale, variables)
+		if err != nil {
+			t.Errorf("%s: renderTemplate: %v", tt.name, err)
+			continue
+		}
+		if got.Locale != tt.wantLocale || got.Body != tt.wantBody || !slices.Equal(got.Options, tt.wantOptions) {
+			t.Errorf("%s: rendered %s %q %q, want %s %q %q", tt.name, got.Locale, got.Body, got.Options,
+				tt.wantLocale, tt.wantBody, tt.wantOptions)
+		}
+		if got.Name != "lunch" || got.Version != 3 || got.Type != "poll" {
+			t.Errorf("%s: rendered %s v%d %s, want lunch v3 poll", tt.name, got.Name, got.Version, got.Type)
+		}
+	}
+
+	var missing *templates.MissingVariablesError
+	if _, err := renderTemplate(template, "pt", nil); !errors.As(err, &missing) || !slices.Equal(missing.Names, []string{"name"}) {
+		t.Errorf("renderTemplate without variables = %v, want name missing", err)
+	}
+}
@@ -0,0 +1,23 @@
+package wa
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	undecryptableMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wa_undecryptable_messages_total",
+		Help: "Messages that could not be decrypted, by whether the ciphertext was unavailable.",
+	}, []string{"unavailable"})
+
+	decryptRecoveries = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wa_decrypt_recoveries_total",
+		Help: "Previously undecryptable messages that were later delivered successfully.",
+	})
+
+	identityChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wa_identity_changes_total",
+		Help: "Contact identity key changes, by whether they were detected implicitly.",
+	}, []string{"implicit"})
+)
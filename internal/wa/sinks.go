@@ -0,0 +1,181 @@
+package wa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/eventbus"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+)
+
+// EventSink consumes account events. The endpoint is the routing key events
+// have always been sent under (the webhook path), the payload is the
+// normalized event envelope. Sinks are called synchronously from the
+// whatsmeow event loop and should return quickly.
+type EventSink interface {
+	Name() string
+	Publish(endpoint string, payload webhooks.WebhookPayload) error
+}
+
+// EventDispatcher fans events out to every configured sink. It is an
+// EventSink itself so event handlers don't care how many consumers there are.
+type EventDispatcher struct {
+	sinks []EventSink
+}
+
+func NewEventDispatcher(sinks ...EventSink) *EventDispatcher {
+	return &EventDispatcher{sinks: sinks}
+}
+
+func (d *EventDispatcher) Name() string {
+	return "dispatcher"
+}
+
+// Publish stamps the event once so every sink sees the same request ID and
+// timestamp, then hands it to each sink in order. Sink failures are logged
+// and don't stop delivery to the remaining sinks.
+func (d *EventDispatcher) Publish(endpoint string, payload webhooks.WebhookPayload) error {
+	if payload.RequestID == "" {
+		payload.RequestID = uuid.New().String()
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+
+	for _, sink := range d.sinks {
+		if err := sink.Publish(endpoint, payload); err != nil {
+			log.Warn().
+				Err(err).
+				Str("sink", sink.Name()).
+				Str("endpoint", endpoint).
+				Str("event_type", payload.EventType).
+				Msg("Event sink failed")
+		}
+	}
+
+	return nil
+}
+
+// Close closes every sink that holds resources
+func (d *EventDispatcher) Close() error {
+	var firstErr error
+	for _, sink := range d.sinks {
+		if c, ok := sink.(io.Closer); ok {
+			if err := c.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// PublishEvent publishes an event that doesn't originate from the event loop,
+// e.g. status changes observed by HTTP handlers
+func (cm *ClientManager) PublishEvent(endpoint string, payload webhooks.WebhookPayload) {
+	cm.events.Publish(endpoint, payload)
+}
+
+func (cm *ClientManager) CloseEventSinks() error {
+	return cm.events.Close()
+}
+
+// WebhookSink delivers events to the Laravel webhook endpoints, one request
+// per event or in batches when batching is enabled
+type WebhookSink struct {
+	sender  *webhooks.Sender
+	batcher *webhooks.Batcher
+}
+
+func NewWebhookSink(sender *webhooks.Sender, batch webhooks.BatchConfig) *WebhookSink {
+	s := &WebhookSink{sender: sender}
+	if batch.Enabled() {
+		s.batcher = webhooks.NewBatcher(sender, batch)
+	}
+	return s
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Publish(endpoint string, payload webhooks.WebhookPayload) error {
+	if s.batcher != nil {
+		return s.batcher.Send(endpoint, payload)
+	}
+	return s.sender.Send(endpoint, payload)
+}
+
+// Close sends the events still waiting in batches
+func (s *WebhookSink) Close() error {
+	if s.batcher != nil {
+		return s.batcher.Close()
+	}
+	return nil
+}
+
+// BusSink publishes events to the in-process bus backing the SSE and
+// WebSocket APIs
+type BusSink struct {
+	bus *eventbus.Bus
+}
+
+func NewBusSink(bus *eventbus.Bus) *BusSink {
+	return &BusSink{bus: bus}
+}
+
+func (s *BusSink) Name() string {
+	return "bus"
+}
+
+func (s *BusSink) Publish(endpoint string, payload webhooks.WebhookPayload) error {
+	s.bus.Publish(payload.WaAccountID, payload.EventType, payload.SchemaVersion, payload.Timestamp, payload.Data)
+	return nil
+}
+
+// FileSink appends every event as a JSON line to a file, meant for debugging
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+type fileSinkRecord struct {
+	Endpoint string `json:"endpoint"`
+	webhooks.WebhookPayload
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+
+	return &FileSink{
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+func (s *FileSink) Publish(endpoint string, payload webhooks.WebhookPayload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Encode writes the record and its newline in a single write
+	return s.enc.Encode(fileSinkRecord{Endpoint: endpoint, WebhookPayload: payload})
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
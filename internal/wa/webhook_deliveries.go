@@ -0,0 +1,141 @@
+package wa
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+)
+
+const (
+	// deliveryLogQueueSize bounds the delivery attempts waiting to be written;
+	// attempts beyond that are dropped rather than slowing down deliveries
+	deliveryLogQueueSize     = 5000
+	deliveryLogPruneInterval = time.Hour
+)
+
+// DeliveryLog records every webhook delivery attempt in the store so
+// deliveries can be inspected later. Attempts are written by a single worker
+// off the delivery path and kept for the retention period.
+type DeliveryLog struct {
+	store     *store.PostgresStore
+	retention time.Duration
+	queue     chan webhooks.Delivery
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+}
+
+func NewDeliveryLog(store *store.PostgresStore, retention time.Duration) *DeliveryLog {
+	l := &DeliveryLog{
+		store:     store,
+		retention: retention,
+		queue:     make(chan webhooks.Delivery, deliveryLogQueueSize),
+		done:      make(chan struct{}),
+	}
+
+	go l.run()
+
+	return l
+}
+
+// RecordDelivery queues a delivery attempt to be written
+func (l *DeliveryLog) RecordDelivery(delivery webhooks.Delivery) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return
+	}
+
+	select {
+	case l.queue <- delivery:
+	default:
+		log.Warn().Str("url", delivery.URL).Str("request_id", delivery.RequestID).Msg("Webhook delivery log queue full, dropping record")
+	}
+}
+
+func (l *DeliveryLog) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(deliveryLogPruneInterval)
+	defer ticker.Stop()
+
+	l.prune()
+	for {
+		select {
+		case delivery, ok := <-l.queue:
+			if !ok {
+				return
+			}
+			l.write(delivery)
+		case <-ticker.C:
+			l.prune()
+		}
+	}
+}
+
+func (l *DeliveryLog) write(delivery webhooks.Delivery) {
+	record := &store.WebhookDelivery{
+		URL:          delivery.URL,
+		RequestID:    delivery.RequestID,
+		RequestIDs:   delivery.RequestIDs,
+		Format:       string(delivery.Format),
+		Headers:      delivery.Headers,
+		WaAccountIDs: delivery.WaAccountIDs,
+		EventTypes:   delivery.EventTypes,
+		BatchSize:    delivery.BatchSize,
+		Payload:      string(delivery.Payload),
+		Signature:    delivery.Signature,
+		StatusCode:   delivery.StatusCode,
+		Succeeded:    delivery.Succeeded(),
+		LatencyMS:    delivery.Latency.Milliseconds(),
+		Response:     delivery.Response,
+		Error:        delivery.Error,
+		AttemptedAt:  delivery.AttemptedAt,
+	}
+
+	if err := l.store.CreateWebhookDelivery(record); err != nil {
+		log.Error().Err(err).Str("request_id", delivery.RequestID).Msg("Failed to record webhook delivery")
+	}
+}
+
+func (l *DeliveryLog) prune() {
+	if l.retention <= 0 {
+		return
+	}
+
+	pruned, err := l.store.PruneWebhookDeliveries(time.Now().Add(-l.retention))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune webhook delivery log")
+		return
+	}
+	if pruned > 0 {
+		log.Info().Int64("deliveries", pruned).Msg("Pruned webhook delivery log")
+	}
+}
+
+// Close writes the queued attempts and stops the worker
+func (l *DeliveryLog) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.queue)
+	l.mu.Unlock()
+
+	<-l.done
+	return nil
+}
+
+func (l *DeliveryLog) List(filter store.WebhookDeliveryFilter, limit, offset int) ([]store.WebhookDelivery, int, error) {
+	return l.store.ListWebhookDeliveries(filter, limit, offset)
+}
+
+func (l *DeliveryLog) Get(id int64) (*store.WebhookDelivery, error) {
+	return l.store.GetWebhookDelivery(id)
+}
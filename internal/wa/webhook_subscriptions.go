@@ -0,0 +1,312 @@
+package wa
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	// subscriptionQueueSize bounds the events buffered per subscription while
+	// its endpoint is slow; events beyond that are dropped
+	subscriptionQueueSize       = 1000
+	subscriptionRefreshInterval = time.Minute
+)
+
+// payloadJIDFields are the event data fields matched against a subscription's JID filter
+var payloadJIDFields = []string{"from", "jid", "sender", "recipient"}
+
+// SubscriptionSink delivers events to the registered webhook subscriptions
+// whose scope and filters match. Every subscription has its own queue and
+// worker, so a slow endpoint doesn't delay the others and per-subscription
+// order is kept, also when events are batched. Events of accounts assigned to
+// a tenant carry its ID, so subscriptions can be scoped to a tenant.
+type SubscriptionSink struct {
+	store   *store.PostgresStore
+	sender  *webhooks.Sender
+	batch   webhooks.BatchConfig
+	mu      sync.RWMutex
+	workers map[string]*subscriptionWorker
+	tenants map[string]string // tenant ID by account ID
+	stop    chan struct{}
+	once    sync.Once
+}
+
+type subscriptionWorker struct {
+	sub   *store.WebhookSubscription
+	queue chan webhooks.WebhookPayload
+}
+
+func NewSubscriptionSink(store *store.PostgresStore, sender *webhooks.Sender, batch webhooks.BatchConfig) *SubscriptionSink {
+	s := &SubscriptionSink{
+		store:   store,
+		sender:  sender,
+		batch:   batch,
+		workers: make(map[string]*subscriptionWorker),
+		tenants: make(map[string]string),
+		stop:    make(chan struct{}),
+	}
+
+	if err := s.Reload(); err != nil {
+		log.Error().Err(err).Msg("Failed to load webhook subscriptions")
+	}
+
+	// Pick up changes made through other instances
+	go s.refresh()
+
+	return s
+}
+
+func (s *SubscriptionSink) Name() string {
+	return "subscriptions"
+}
+
+func (s *SubscriptionSink) Publish(endpoint string, payload webhooks.WebhookPayload) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if payload.TenantID == "" {
+		payload.TenantID = s.tenants[payload.WaAccountID]
+	}
+
+	// The generic field view is only built if a subscription filters on JIDs
+	var fields map[string]interface{}
+	for _, w := range s.workers {
+		if fields == nil && (len(w.sub.ChatJIDs) > 0 || len(w.sub.JIDs) > 0) {
+			fields = payload.Fields()
+		}
+		if !subscriptionMatches(w.sub, payload, fields) {
+			continue
+		}
+
+		select {
+		case w.queue <- payload:
+		default:
+			log.Warn().
+				Str("subscription_id", w.sub.ID).
+				Str("event_type", payload.EventType).
+				Msg("Webhook subscription queue full, dropping event")
+		}
+	}
+
+	return nil
+}
+
+// Reload replaces the in-memory subscriptions and account tenants with the
+// ones in the store. Workers of unchanged subscriptions keep running.
+func (s *SubscriptionSink) Reload() error {
+	subs, err := s.store.ListActiveWebhookSubscriptions()
+	if err != nil {
+		return err
+	}
+	tenants, err := s.store.ListAccountTenants()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.stop:
+		// Closed while loading, don't start new workers
+		return nil
+	default:
+	}
+
+	s.tenants = tenants
+
+	active := make(map[string]bool, len(subs))
+	for _, sub := range subs {
+		active[sub.ID] = true
+
+		if w, ok := s.workers[sub.ID]; ok {
+			if w.sub.UpdatedAt.Equal(sub.UpdatedAt) {
+				continue
+			}
+			close(w.queue)
+		}
+
+		w := &subscriptionWorker{
+			sub:   sub,
+			queue: make(chan webhooks.WebhookPayload, subscriptionQueueSize),
+		}
+		s.workers[sub.ID] = w
+		go s.deliver(w)
+	}
+
+	for id, w := range s.workers {
+		if !active[id] {
+			close(w.queue)
+			delete(s.workers, id)
+		}
+	}
+
+	return nil
+}
+
+// deliver sends queued events until the worker's queue is closed; events
+// already queued for a replaced subscription still go out with the old settings
+func (s *SubscriptionSink) deliver(w *subscriptionWorker) {
+	if s.batch.Enabled() {
+		for {
+			batch, ok := webhooks.NextBatch(w.queue, s.batch)
+			if !ok {
+				return
+			}
+			if err := s.sender.SendBatchToURL(w.sub.URL, w.sub.Secret, webhooks.Format(w.sub.Format), batch); err != nil {
+				log.Debug().Err(err).Str("subscription_id", w.sub.ID).Int("events", len(batch)).Msg("Webhook subscription batch delivery failed")
+			}
+		}
+	}
+
+	for payload := range w.queue {
+		if err := s.sender.SendToURL(w.sub.URL, w.sub.Secret, webhooks.Format(w.sub.Format), payload); err != nil {
+			log.Debug().Err(err).Str("subscription_id", w.sub.ID).Msg("Webhook subscription delivery failed")
+		}
+	}
+}
+
+func (s *SubscriptionSink) refresh() {
+	ticker := time.NewTicker(subscriptionRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Reload(); err != nil {
+				log.Error().Err(err).Msg("Failed to refresh webhook subscriptions")
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *SubscriptionSink) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for id, w := range s.workers {
+			close(w.queue)
+			delete(s.workers, id)
+		}
+	})
+	return nil
+}
+
+func (s *SubscriptionSink) List(waAccountID, tenantID string) ([]*store.WebhookSubscription, error) {
+	return s.store.ListWebhookSubscriptions(waAccountID, tenantID)
+}
+
+func (s *SubscriptionSink) Get(id string) (*store.WebhookSubscription, error) {
+	return s.store.GetWebhookSubscription(id)
+}
+
+func (s *SubscriptionSink) Create(sub *store.WebhookSubscription) error {
+	sub.ID = uuid.New().String()
+	normalizeSubscription(sub)
+
+	if err := s.store.CreateWebhookSubscription(sub); err != nil {
+		return err
+	}
+
+	return s.Reload()
+}
+
+// Update replaces a subscription, it reports false if it doesn't exist
+func (s *SubscriptionSink) Update(sub *store.WebhookSubscription) (bool, error) {
+	normalizeSubscription(sub)
+
+	found, err := s.store.UpdateWebhookSubscription(sub)
+	if err != nil || !found {
+		return found, err
+	}
+
+	return true, s.Reload()
+}
+
+func (s *SubscriptionSink) Delete(id string) (bool, error) {
+	found, err := s.store.DeleteWebhookSubscription(id)
+	if err != nil || !found {
+		return found, err
+	}
+
+	return true, s.Reload()
+}
+
+// AccountTenant returns the tenant an account is assigned to, or "" if none
+func (s *SubscriptionSink) AccountTenant(waAccountID string) (string, error) {
+	return s.store.GetAccountTenant(waAccountID)
+}
+
+// SetAccountTenant assigns an account to a tenant, an empty tenant removes
+// the assignment
+func (s *SubscriptionSink) SetAccountTenant(waAccountID, tenantID string) error {
+	if err := s.store.SetAccountTenant(waAccountID, tenantID); err != nil {
+		return err
+	}
+
+	return s.Reload()
+}
+
+// normalizeSubscription strips device parts from JID filters so they match
+// events from any of the contact's devices
+func normalizeSubscription(sub *store.WebhookSubscription) {
+	for i, jid := range sub.ChatJIDs {
+		sub.ChatJIDs[i] = normalizeJID(jid)
+	}
+	for i, jid := range sub.JIDs {
+		sub.JIDs[i] = normalizeJID(jid)
+	}
+}
+
+func normalizeJID(jid string) string {
+	parsed, err := types.ParseJID(jid)
+	if err != nil {
+		return jid
+	}
+	return parsed.ToNonAD().String()
+}
+
+func subscriptionMatches(sub *store.WebhookSubscription, payload webhooks.WebhookPayload, fields map[string]interface{}) bool {
+	if sub.WaAccountID != "" && sub.WaAccountID != payload.WaAccountID {
+		return false
+	}
+	if sub.TenantID != "" && sub.TenantID != payload.TenantID {
+		return false
+	}
+	if len(sub.EventTypes) > 0 && !slices.Contains(sub.EventTypes, payload.EventType) {
+		return false
+	}
+
+	if len(sub.ChatJIDs) > 0 {
+		chat, _ := fields["chat"].(string)
+		if chat == "" || !slices.Contains(sub.ChatJIDs, normalizeJID(chat)) {
+			return false
+		}
+	}
+
+	if len(sub.JIDs) > 0 {
+		matched := false
+		for _, field := range payloadJIDFields {
+			if jid, ok := fields[field].(string); ok && jid != "" && slices.Contains(sub.JIDs, normalizeJID(jid)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
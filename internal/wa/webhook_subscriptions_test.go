@@ -0,0 +1,89 @@
+package wa
+
+import (
+	"testing"
+
+	"github.com/whatsapp-api/go-whatsapp-service/internal/store"
+	"github.com/whatsapp-api/go-whatsapp-service/internal/webhooks"
+)
+
+func TestSubscriptionMatches(t *testing.T) {
+	payload := webhooks.WebhookPayload{WaAccountID: "acc-1", TenantID: "tenant-1", EventType: "message"}
+	fields := map[string]interface{}{
+		"chat":   "5511999990000@s.whatsapp.net",
+		"sender": "5511999990000:12@s.whatsapp.net",
+	}
+
+	tests := []struct {
+		name string
+		sub  store.WebhookSubscription
+		want bool
+	}{
+		{"no scope or filters", store.WebhookSubscription{}, true},
+		{"same account", store.WebhookSubscription{WaAccountID: "acc-1"}, true},
+		{"other account", store.WebhookSubscription{WaAccountID: "acc-2"}, false},
+		{"same tenant", store.WebhookSubscription{TenantID: "tenant-1"}, true},
+		{"other tenant", store.WebhookSubscription{TenantID: "tenant-2"}, false},
+		{"event type listed", store.WebhookSubscription{EventTypes: []string{"receipt", "message"}}, true},
+		{"event type not listed", store.WebhookSubscription{EventTypes: []string{"receipt"}}, false},
+		{"chat listed", store.WebhookSubscription{ChatJIDs: []string{"5511999990000@s.whatsapp.net"}}, true},
+		{"chat not listed", store.WebhookSubscription{ChatJIDs: []string{"5511888880000@s.whatsapp.net"}}, false},
+		{"sender device matches JID", store.WebhookSubscription{JIDs: []string{"5511999990000@s.whatsapp.net"}}, true},
+		{"no JID field matches", store.WebhookSubscription{JIDs: []string{"5511888880000@s.whatsapp.net"}}, false},
+		{"all filters match", store.WebhookSubscription{
+			WaAccountID: "acc-1",
+			TenantID:    "tenant-1",
+			EventTypes:  []string{"message"},
+			ChatJIDs:    []string{"5511999990000@s.whatsapp.net"},
+			JIDs:        []string{"5511999990000@s.whatsapp.net"},
+		}, true},
+		{"one filter fails", store.WebhookSubscription{
+			WaAccountID: "acc-1",
+			EventTypes:  []string{"message"},
+			ChatJIDs:    []string{"5511888880000@s.whatsapp.net"},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		if got := subscriptionMatches(&tt.sub, payload, fields); got != tt.want {
+			t.Errorf("%s: subscriptionMatches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubscriptionMatchesWithoutChat(t *testing.T) {
+	payload := webhooks.WebhookPayload{WaAccountID: "acc-1", EventType: "status"}
+	sub := store.WebhookSubscription{ChatJIDs: []string{"5511999990000@s.whatsapp.net"}}
+
+	if subscriptionMatches(&sub, payload, map[string]interface{}{}) {
+		t.Error("subscription with a chat filter matched an event without a chat")
+	}
+}
+
+func TestSubscriptionSinkScopesTenants(t *testing.T) {
+	worker := func(sub store.WebhookSubscription) *subscriptionWorker {
+		return &subscriptionWorker{sub: &sub, queue: make(chan webhooks.WebhookPayload, 10)}
+	}
+	tenant := worker(store.WebhookSubscription{TenantID: "tenant-1"})
+	everyone := worker(store.WebhookSubscription{})
+	sink := &SubscriptionSink{
+		workers: map[string]*subscriptionWorker{"tenant": tenant, "everyone": everyone},
+		tenants: map[string]string{"acc-1": "tenant-1"},
+	}
+
+	for _, waAccountID := range []string{"acc-1", "acc-2"} {
+		sink.Publish("receipt", webhooks.WebhookPayload{WaAccountID: waAccountID, EventType: "receipt"})
+	}
+
+	if len(tenant.queue) != 1 || len(everyone.queue) != 2 {
+		t.Fatalf("queued %d events for the tenant and %d for everyone, want 1 and 2", len(tenant.queue), len(everyone.queue))
+	}
+	if payload := <-tenant.queue; payload.WaAccountID != "acc-1" || payload.TenantID != "tenant-1" {
+		t.Errorf("tenant subscription got an event of %s in tenant %q", payload.WaAccountID, payload.TenantID)
+	}
+	for range 2 {
+		if payload := <-everyone.queue; payload.WaAccountID == "acc-2" && payload.TenantID != "" {
+			t.Errorf("event of an unassigned account has tenant %q", payload.TenantID)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package webhooks
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+// batchQueueSize bounds the events waiting per destination, events beyond
+// that are dropped rather than holding up the publisher
+const batchQueueSize = 1000
+
+var droppedBatchEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "wa_webhook_batch_dropped_total",
+	Help: "Webhook events dropped because the endpoint's batch queue was full, by endpoint.",
+}, []string{"endpoint"})
+
+// BatchConfig controls webhook batching. A batch is sent once it holds
+// MaxItems events or MaxDelay passed since its first event.
+type BatchConfig struct {
+	MaxItems int
+	MaxDelay time.Duration
+}
+
+// Enabled reports whether events are batched at all
+func (c BatchConfig) Enabled() bool {
+	return c.MaxItems > 1 && c.MaxDelay > 0
+}
+
+// NextBatch waits for the next event on the queue and collects the events
+// following it into a batch. It returns false once the queue is closed and
+// drained.
+func NextBatch(queue <-chan WebhookPayload, config BatchConfig) ([]WebhookPayload, bool) {
+	first, ok := <-queue
+	if !ok {
+		return nil, false
+	}
+
+	batch := []WebhookPayload{first}
+	timer := time.NewTimer(config.MaxDelay)
+	defer timer.Stop()
+
+	for len(batch) < config.MaxItems {
+		select {
+		case payload, ok := <-queue:
+			if !ok {
+				return batch, true
+			}
+			batch = append(batch, payload)
+		case <-timer.C:
+			return batch, true
+		}
+	}
+
+	return batch, true
+}
+
+// Batcher batches the events sent to the Laravel webhook endpoints. Every
+// endpoint has its own queue and worker that sends one batch at a time, so
+// events reach an endpoint in the order they were published.
+type Batcher struct {
+	sender *Sender
+	config BatchConfig
+	mu     sync.RWMutex
+	queues map[string]chan WebhookPayload
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewBatcher(sender *Sender, config BatchConfig) *Batcher {
+	return &Batcher{
+		sender: sender,
+		config: config,
+		queues: make(map[string]chan WebhookPayload),
+	}
+}
+
+// Send queues a payload for an endpoint. Events are published from the
+// WhatsApp event loop, so the payload is dropped when the endpoint's queue is
+// full instead of waiting for a slow endpoint.
+func (b *Batcher) Send(endpoint string, payload WebhookPayload) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return fmt.Errorf("webhook batcher closed")
+	}
+
+	queue, ok := b.queues[endpoint]
+	if !ok {
+		// Upgrade to the write lock to start the endpoint's worker
+		b.mu.RUnlock()
+		queue = b.queue(endpoint)
+		b.mu.RLock()
+		if queue == nil || b.closed {
+			return fmt.Errorf("webhook batcher closed")
+		}
+	}
+
+	select {
+	case queue <- payload:
+	default:
+		droppedBatchEvents.WithLabelValues(endpoint).Inc()
+		log.Warn().
+			Str("endpoint", endpoint).
+			Str("event_type", payload.EventType).
+			Msg("Webhook batch queue full, dropping event")
+	}
+	return nil
+}
+
+func (b *Batcher) queue(endpoint string) chan WebhookPayload {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	if queue, ok := b.queues[endpoint]; ok {
+		return queue
+	}
+
+	queue := make(chan WebhookPayload, batchQueueSize)
+	b.queues[endpoint] = queue
+	b.wg.Add(1)
+	go b.deliver(endpoint, queue)
+	return queue
+}
+
+func (b *Batcher) deliver(endpoint string, queue <-chan WebhookPayload) {
+	defer b.wg.Done()
+
+	url := b.sender.endpointURL(endpoint)
+	for {
+		batch, ok := NextBatch(queue, b.config)
+		if !ok {
+			return
+		}
+		if err := b.sender.SendBatchToURL(url, b.sender.signingSecret, b.sender.format, batch); err != nil {
+			log.Debug().Err(err).Str("endpoint", endpoint).Int("events", len(batch)).Msg("Webhook batch delivery failed")
+		}
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be sent
+func (b *Batcher) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for _, queue := range b.queues {
+		close(queue)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	return nil
+}
@@ -0,0 +1,172 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestBatchConfigEnabled(t *testing.T) {
+	tests := []struct {
+		config BatchConfig
+		want   bool
+	}{
+		{BatchConfig{}, false},
+		{BatchConfig{MaxItems: 1, MaxDelay: time.Second}, false},
+		{BatchConfig{MaxItems: 10, MaxDelay: 0}, false},
+		{BatchConfig{MaxItems: 2, MaxDelay: time.Millisecond}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.config.Enabled(); got != tt.want {
+			t.Errorf("%+v.Enabled() = %v, want %v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func testPayloads(n int) []WebhookPayload {
+	payloads := make([]WebhookPayload, n)
+	for i := range payloads {
+		payloads[i] = WebhookPayload{EventType: "message.received", RequestID: strconv.Itoa(i)}
+	}
+	return payloads
+}
+
+func TestNextBatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		queued int
+		close  bool
+		config BatchConfig
+		want   []int
+	}{
+		{"full batches", 5, true, BatchConfig{MaxItems: 2, MaxDelay: time.Hour}, []int{2, 2, 1}},
+		{"queue smaller than a batch", 3, true, BatchConfig{MaxItems: 10, MaxDelay: time.Hour}, []int{3}},
+		{"delay ends the batch", 3, false, BatchConfig{MaxItems: 10, MaxDelay: 10 * time.Millisecond}, []int{3}},
+		{"closed empty queue", 0, true, BatchConfig{MaxItems: 10, MaxDelay: time.Hour}, nil},
+	}
+
+	for _, tt := range tests {
+		queue := make(chan WebhookPayload, tt.queued)
+		for _, payload := range testPayloads(tt.queued) {
+			queue <- payload
+		}
+		if tt.close {
+			close(queue)
+		}
+
+		var sizes []int
+		next := 0
+		for len(sizes) < len(tt.want) || tt.close {
+			batch, ok := NextBatch(queue, tt.config)
+			if !ok {
+				break
+			}
+			for _, payload := range batch {
+				if payload.RequestID != strconv.Itoa(next) {
+					t.Errorf("%s: got payload %s, want %d", tt.name, payload.RequestID, next)
+				}
+				next++
+			}
+			sizes = append(sizes, len(batch))
+		}
+
+		if fmt.Sprint(sizes) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: batch sizes = %v, want %v", tt.name, sizes, tt.want)
+		}
+	}
+}
+
+func TestBatcherKeepsOrderPerEndpoint(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = map[string][]string{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []struct {
+			RequestID string `json:"request_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("decoding batch: %v", err)
+		}
+		if r.Header.Get("X-WA-Batch-Size") != strconv.Itoa(len(batch)) {
+			t.Errorf("X-WA-Batch-Size = %q, batch has %d events", r.Header.Get("X-WA-Batch-Size"), len(batch))
+		}
+
+		mu.Lock()
+		for _, payload := range batch {
+			received[r.URL.Path] = append(received[r.URL.Path], payload.RequestID)
+		}
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	batcher := NewBatcher(NewSender(server.URL, "secret"), BatchConfig{MaxItems: 3, MaxDelay: 5 * time.Millisecond})
+	payloads := testPayloads(10)
+	for _, payload := range payloads {
+		for _, endpoint := range []string{"inbound", "delivery"} {
+			if err := batcher.Send(endpoint, payload); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+		}
+	}
+	batcher.Close()
+
+	if err := batcher.Send("inbound", payloads[0]); err == nil {
+		t.Error("Send after Close succeeded")
+	}
+
+	for _, path := range []string{"/inbound", "/delivery"} {
+		got := received[path]
+		if len(got) != len(payloads) {
+			t.Fatalf("%s received %d events, want %d", path, len(got), len(payloads))
+		}
+		for i, id := range got {
+			if id != strconv.Itoa(i) {
+				t.Errorf("%s event %d has request ID %s, want %d", path, i, id, i)
+			}
+		}
+	}
+}
+
+func TestBatcherDropsWhenQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	batcher := NewBatcher(NewSender(server.URL, "secret"), BatchConfig{MaxItems: 2, MaxDelay: time.Millisecond})
+	sent := batchQueueSize + 100
+
+	// The worker holds at most one batch while the endpoint hangs, the rest
+	// has to be dropped without blocking
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, payload := range testPayloads(sent) {
+			if err := batcher.Send("slow", payload); err != nil {
+				t.Errorf("Send: %v", err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send blocked on a full queue")
+	}
+
+	if dropped := testutil.ToFloat64(droppedBatchEvents.WithLabelValues("slow")); dropped < float64(sent-batchQueueSize-2) {
+		t.Errorf("dropped %v events, want at least %d", dropped, sent-batchQueueSize-2)
+	}
+
+	close(release)
+	batcher.Close()
+}
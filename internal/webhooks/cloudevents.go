@@ -0,0 +1,136 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Format is the wire format of the events sent to a destination
+type Format string
+
+const (
+	// FormatNative sends the WebhookPayload envelope
+	FormatNative Format = "native"
+	// FormatCloudEvents sends CloudEvents 1.0 in structured mode
+	FormatCloudEvents Format = "cloudevents"
+	// FormatCloudEventsBinary sends CloudEvents 1.0 in binary mode, the
+	// attributes as ce- headers and the event data as the body
+	FormatCloudEventsBinary Format = "cloudevents-binary"
+)
+
+// DefaultEventSource is the CloudEvents source used unless configured otherwise
+const DefaultEventSource = "/go-whatsapp-service"
+
+// ParseFormat parses a format name, empty means native
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case "":
+		return FormatNative, nil
+	case FormatNative, FormatCloudEvents, FormatCloudEventsBinary:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown webhook format %q, must be native, cloudevents or cloudevents-binary", name)
+	}
+}
+
+// CloudEvent is a payload as a CloudEvents 1.0 event in structured mode. The
+// tenant ID and schema version travel as extension attributes.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	DataSchema      string    `json:"dataschema"`
+	TenantID        string    `json:"tenantid,omitempty"`
+	SchemaVersion   int       `json:"schemaversion"`
+	Data            EventData `json:"data"`
+}
+
+// NewCloudEvent maps a payload onto CloudEvents attributes: event_type to
+// type, wa_account_id to subject and request_id to id
+func NewCloudEvent(source string, payload WebhookPayload) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              payload.RequestID,
+		Source:          source,
+		Type:            payload.EventType,
+		Subject:         payload.WaAccountID,
+		Time:            payload.Timestamp,
+		DataContentType: "application/json",
+		DataSchema:      fmt.Sprintf("/v1/schemas/%d/%s", payload.SchemaVersion, payload.EventType),
+		TenantID:        payload.TenantID,
+		SchemaVersion:   payload.SchemaVersion,
+		Data:            payload.Data,
+	}
+}
+
+// encode returns the body and headers of a single payload in a format
+func (s *Sender) encode(format Format, payload WebhookPayload) ([]byte, map[string]string, error) {
+	var (
+		body        interface{}
+		contentType = "application/json"
+		headers     = map[string]string{}
+	)
+
+	switch format {
+	case FormatCloudEvents:
+		body = NewCloudEvent(s.eventSource, payload)
+		contentType = "application/cloudevents+json"
+	case FormatCloudEventsBinary:
+		event := NewCloudEvent(s.eventSource, payload)
+		body = event.Data
+		headers["ce-specversion"] = event.SpecVersion
+		headers["ce-id"] = event.ID
+		headers["ce-source"] = event.Source
+		headers["ce-type"] = event.Type
+		headers["ce-time"] = event.Time.Format(time.RFC3339Nano)
+		headers["ce-dataschema"] = event.DataSchema
+		headers["ce-schemaversion"] = strconv.Itoa(event.SchemaVersion)
+		if event.Subject != "" {
+			headers["ce-subject"] = event.Subject
+		}
+		if event.TenantID != "" {
+			headers["ce-tenantid"] = event.TenantID
+		}
+	default:
+		body = payload
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	headers["Content-Type"] = contentType
+	return data, headers, nil
+}
+
+// encodeBatch returns the body and headers of a batch in a format that
+// supports batching
+func (s *Sender) encodeBatch(format Format, payloads []WebhookPayload) ([]byte, map[string]string, error) {
+	var (
+		body        interface{} = payloads
+		contentType             = "application/json"
+	)
+
+	if format == FormatCloudEvents {
+		events := make([]CloudEvent, len(payloads))
+		for i, payload := range payloads {
+			events[i] = NewCloudEvent(s.eventSource, payload)
+		}
+		body = events
+		contentType = "application/cloudevents-batch+json"
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	return data, map[string]string{"Content-Type": contentType}, nil
+}
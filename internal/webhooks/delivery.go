@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"time"
+)
+
+// responseSnippetSize bounds how much of a response body is kept with a delivery
+const responseSnippetSize = 1024
+
+// Delivery is one attempt to deliver a webhook request, a single event or a
+// batch. Payload is the exact body that was signed, Headers the headers sent
+// with it. RequestIDs holds the request ID of the delivery followed by those
+// of its events, which are also their CloudEvents IDs.
+type Delivery struct {
+	URL          string
+	RequestID    string
+	RequestIDs   []string
+	Format       Format
+	Headers      map[string]string
+	WaAccountIDs []string
+	EventTypes   []string
+	BatchSize    int
+	Payload      []byte
+	Signature    string
+	StatusCode   int
+	Latency      time.Duration
+	Response     string
+	Error        string
+	AttemptedAt  time.Time
+}
+
+// Succeeded reports whether the endpoint accepted the request
+func (d Delivery) Succeeded() bool {
+	return d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+}
+
+// DeliveryRecorder is told about every delivery attempt. It is called on the
+// delivery path and must not block.
+type DeliveryRecorder interface {
+	RecordDelivery(delivery Delivery)
+}
+
+// SetRecorder registers the recorder told about every delivery attempt
+func (s *Sender) SetRecorder(recorder DeliveryRecorder) {
+	s.recorder = recorder
+}
+
+// payloadScope lists the distinct accounts and event types of the payloads
+func payloadScope(payloads []WebhookPayload) (accounts, eventTypes []string) {
+	seenAccounts := map[string]bool{}
+	seenTypes := map[string]bool{}
+	for _, payload := range payloads {
+		if !seenAccounts[payload.WaAccountID] {
+			seenAccounts[payload.WaAccountID] = true
+			accounts = append(accounts, payload.WaAccountID)
+		}
+		if !seenTypes[payload.EventType] {
+			seenTypes[payload.EventType] = true
+			eventTypes = append(eventTypes, payload.EventType)
+		}
+	}
+	return accounts, eventTypes
+}
+
+// deliveryRequestIDs lists the request ID of a delivery and the distinct
+// ones of its payloads
+func deliveryRequestIDs(requestID string, payloads []WebhookPayload) []string {
+	ids := []string{requestID}
+	seen := map[string]bool{requestID: true}
+	for _, payload := range payloads {
+		if !seen[payload.RequestID] {
+			seen[payload.RequestID] = true
+			ids = append(ids, payload.RequestID)
+		}
+	}
+	return ids
+}
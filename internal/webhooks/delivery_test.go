@@ -0,0 +1,63 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+type recorderFunc func(Delivery)
+
+func (f recorderFunc) RecordDelivery(delivery Delivery) {
+	f(delivery)
+}
+
+func TestDeliveryRequestIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		payloads  []string
+		want      []string
+	}{
+		{"single event", "a", []string{"a"}, []string{"a"}},
+		{"batch", "batch", []string{"a", "b"}, []string{"batch", "a", "b"}},
+		{"repeated event", "batch", []string{"a", "a", "b"}, []string{"batch", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		payloads := make([]WebhookPayload, len(tt.payloads))
+		for i, id := range tt.payloads {
+			payloads[i] = WebhookPayload{RequestID: id}
+		}
+		if got := deliveryRequestIDs(tt.requestID, payloads); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: deliveryRequestIDs = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRecordedDeliveryRequestIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var recorded []Delivery
+	sender := NewSender(server.URL, "secret")
+	sender.SetRecorder(recorderFunc(func(d Delivery) { recorded = append(recorded, d) }))
+
+	for _, format := range []Format{FormatNative, FormatCloudEvents} {
+		recorded = nil
+		payloads := []WebhookPayload{{RequestID: "event-1"}, {RequestID: "event-2"}}
+		if err := sender.SendBatchToURL(server.URL, "secret", format, payloads); err != nil {
+			t.Fatalf("%s: SendBatchToURL: %v", format, err)
+		}
+
+		if len(recorded) != 1 {
+			t.Fatalf("%s: recorded %d deliveries, want 1", format, len(recorded))
+		}
+		d := recorded[0]
+		want := []string{d.RequestID, "event-1", "event-2"}
+		if !slices.Equal(d.RequestIDs, want) {
+			t.Errorf("%s: RequestIDs = %v, want %v", format, d.RequestIDs, want)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package webhooks
+
+// Typed event data, one struct per event type. The JSON field names are the
+// webhook contract: existing fields are never renamed or removed. A field added
+// after version 1 carries a schema:"since=N" tag with the schema version that
+// introduced it, so the schemas of older versions can still be served.
+
+// EventData is the data of a webhook event
+type EventData interface {
+	EventType() string
+}
+
+// MessageEvent is an incoming or own-device message. The content fields
+// present depend on the message type.
+type MessageEvent struct {
+	Event     string   `json:"event"`
+	MessageID string   `json:"message_id"`
+	From      string   `json:"from"`
+	Chat      string   `json:"chat"`
+	Timestamp int64    `json:"timestamp"`
+	FromMe    bool     `json:"from_me"`
+	Type      string   `json:"type,omitempty" schema:"enum=text|image|video|audio|document|sticker|location|contact|unknown"`
+	Text      string   `json:"text,omitempty"`
+	Caption   string   `json:"caption,omitempty"`
+	MimeType  string   `json:"mime_type,omitempty"`
+	Filename  string   `json:"filename,omitempty"`
+	PTT       *bool    `json:"ptt,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	VCard     string   `json:"vcard,omitempty"`
+}
+
+func (MessageEvent) EventType() string { return "message" }
+
+type ReceiptEvent struct {
+	Event      string   `json:"event"`
+	Chat       string   `json:"chat"`
+	Type       string   `json:"type"`
+	Timestamp  int64    `json:"timestamp"`
+	MessageIDs []string `json:"message_ids"`
+	Sender     string   `json:"sender,omitempty"`
+}
+
+func (ReceiptEvent) EventType() string { return "receipt" }
+
+// DeliveryEvent is a tracked outbound message reaching a recipient
+type DeliveryEvent struct {
+	MessageID string `json:"message_id"`
+	Chat      string `json:"chat"`
+	Recipient string `json:"recipient"`
+	Status    string `json:"status"`
+}
+
+func (DeliveryEvent) EventType() string { return "delivery" }
+
+// ReadEvent is a tracked outbound message read by a recipient
+type ReadEvent struct {
+	MessageID string `json:"message_id"`
+	Chat      string `json:"chat"`
+	Recipient string `json:"recipient"`
+}
+
+func (ReadEvent) EventType() string { return "read" }
+
+// StatusEvent is a connection status change of the account
+type StatusEvent struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func (StatusEvent) EventType() string { return "status" }
+
+type ErrorEvent struct {
+	ErrorCode    string                 `json:"error_code"`
+	ErrorMessage string                 `json:"error_message"`
+	Context      map[string]interface{} `json:"context"`
+}
+
+func (ErrorEvent) EventType() string { return "error" }
+
+type QREvent struct {
+	Event string   `json:"event"`
+	Codes []string `json:"codes"`
+}
+
+func (QREvent) EventType() string { return "qr" }
+
+type PairSuccessEvent struct {
+	Event        string `json:"event"`
+	JID          string `json:"jid"`
+	BusinessName string `json:"business_name"`
+	Platform     string `json:"platform"`
+}
+
+func (PairSuccessEvent) EventType() string { return "pair_success" }
+
+// GroupInfoEvent is a group change, it only carries the fields that changed.
+//
+// Before schema version 1, group_info also had owner, created and
+// participants_count. whatsmeow only reports those when joining a group, they
+// moved to joined_group (created as created_at), and name and topic became the
+// plain new name and topic. The old handler read them from fields
+// events.GroupInfo doesn't have and didn't compile.
+type GroupInfoEvent struct {
+	Event     string   `json:"event"`
+	GroupJID  string   `json:"group_jid"`
+	Chat      string   `json:"chat" schema:"since=2"`
+	Timestamp int64    `json:"timestamp"`
+	Sender    string   `json:"sender,omitempty"`
+	Name      *string  `json:"name,omitempty"`
+	Topic     *string  `json:"topic,omitempty"`
+	Joined    []string `json:"joined,omitempty"`
+	Left      []string `json:"left,omitempty"`
+	Promoted  []string `json:"promoted,omitempty"`
+	Demoted   []string `json:"demoted,omitempty"`
+}
+
+func (GroupInfoEvent) EventType() string { return "group_info" }
+
+// JoinedGroupEvent is sent when the account is added to a group, with the
+// group's name, owner, creation time and participant count
+type JoinedGroupEvent struct {
+	Event             string `json:"event"`
+	GroupJID          string `json:"group_jid"`
+	Chat              string `json:"chat" schema:"since=2"`
+	Name              string `json:"name,omitempty"`
+	Owner             string `json:"owner,omitempty"`
+	CreatedAt         int64  `json:"created_at,omitempty"`
+	ParticipantsCount int    `json:"participants_count,omitempty"`
+}
+
+func (JoinedGroupEvent) EventType() string { return "joined_group" }
+
+// CallEvent is shared by call_offer, call_accept, call_terminate and call_reject
+type CallEvent struct {
+	Event          string `json:"event"`
+	CallID         string `json:"call_id"`
+	From           string `json:"from"`
+	Timestamp      int64  `json:"timestamp"`
+	IsGroup        bool   `json:"is_group"`
+	CallCreator    string `json:"call_creator,omitempty"`
+	GroupJID       string `json:"group_jid,omitempty"`
+	RemotePlatform string `json:"remote_platform,omitempty"`
+	RemoteVersion  string `json:"remote_version,omitempty"`
+	Media          string `json:"media,omitempty"`
+	CallType       string `json:"call_type,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+func (e CallEvent) EventType() string { return e.Event }
+
+type CallAutoRejectedEvent struct {
+	Event     string `json:"event"`
+	CallID    string `json:"call_id"`
+	From      string `json:"from"`
+	Timestamp int64  `json:"timestamp"`
+	ReplySent bool   `json:"reply_sent"`
+}
+
+func (CallAutoRejectedEvent) EventType() string { return "call_auto_rejected" }
+
+type HistorySyncProgressEvent struct {
+	Event              string `json:"event"`
+	SyncType           string `json:"sync_type"`
+	ChunkOrder         uint32 `json:"chunk_order"`
+	Progress           int    `json:"progress"`
+	Conversations      int    `json:"conversations"`
+	Messages           int    `json:"messages"`
+	PushNames          int    `json:"push_names"`
+	TotalChunks        int    `json:"total_chunks"`
+	TotalConversations int    `json:"total_conversations"`
+	TotalMessages      int    `json:"total_messages"`
+}
+
+func (HistorySyncProgressEvent) EventType() string { return "history_sync_progress" }
+
+type ContactUpdatedEvent struct {
+	Event         string `json:"event"`
+	JID           string `json:"jid"`
+	Changed       string `json:"changed"`
+	Name          string `json:"name"`
+	NameSource    string `json:"name_source"`
+	FullName      string `json:"full_name"`
+	FirstName     string `json:"first_name"`
+	PushName      string `json:"push_name"`
+	BusinessName  string `json:"business_name"`
+	LastChangedAt int64  `json:"last_changed_at"`
+	Previous      string `json:"previous,omitempty"`
+}
+
+func (ContactUpdatedEvent) EventType() string { return "contact_updated" }
+
+type UndecryptableMessageEvent struct {
+	Event           string `json:"event"`
+	MessageID       string `json:"message_id"`
+	From            string `json:"from"`
+	Chat            string `json:"chat"`
+	Timestamp       int64  `json:"timestamp"`
+	IsGroup         bool   `json:"is_group"`
+	Unavailable     bool   `json:"unavailable"`
+	UnavailableType string `json:"unavailable_type"`
+	Hidden          bool   `json:"hidden"`
+	Failures        int    `json:"failures,omitempty"`
+}
+
+func (UndecryptableMessageEvent) EventType() string { return "undecryptable_message" }
+
+type IdentityChangedEvent struct {
+	Event     string `json:"event"`
+	JID       string `json:"jid"`
+	Implicit  bool   `json:"implicit"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+func (IdentityChangedEvent) EventType() string { return "identity_changed" }
+
+type DecryptRecoveredEvent struct {
+	Event        string `json:"event"`
+	MessageID    string `json:"message_id"`
+	From         string `json:"from"`
+	Chat         string `json:"chat"`
+	Failures     int    `json:"failures"`
+	RetryCount   int    `json:"retry_count"`
+	FailedAt     int64  `json:"failed_at"`
+	RecoveredAt  int64  `json:"recovered_at"`
+	DelaySeconds int64  `json:"delay_seconds"`
+}
+
+func (DecryptRecoveredEvent) EventType() string { return "decrypt_recovered" }
+
+type LabelUpdatedEvent struct {
+	Event        string `json:"event"`
+	LabelID      string `json:"label_id"`
+	Name         string `json:"name"`
+	Color        int    `json:"color"`
+	Deleted      bool   `json:"deleted"`
+	Timestamp    int64  `json:"timestamp"`
+	FromFullSync bool   `json:"from_full_sync"`
+}
+
+func (LabelUpdatedEvent) EventType() string { return "label_updated" }
+
+// ChatStateChangedEvent describes one chat change, the fields of the change
+// kind, together with the full current state of the chat
+type ChatStateChangedEvent struct {
+	Event        string     `json:"event"`
+	Chat         string     `json:"chat"`
+	Change       string     `json:"change" schema:"enum=archive|pin|mute|star|label|message_label"`
+	Timestamp    int64      `json:"timestamp"`
+	FromFullSync bool       `json:"from_full_sync"`
+	Archived     *bool      `json:"archived,omitempty"`
+	Pinned       *bool      `json:"pinned,omitempty"`
+	Muted        *bool      `json:"muted,omitempty"`
+	MutedUntil   int64      `json:"muted_until,omitempty"`
+	MessageID    string     `json:"message_id,omitempty"`
+	FromMe       *bool      `json:"from_me,omitempty"`
+	Starred      *bool      `json:"starred,omitempty"`
+	Sender       string     `json:"sender,omitempty"`
+	LabelID      string     `json:"label_id,omitempty"`
+	Labeled      *bool      `json:"labeled,omitempty"`
+	State        *ChatState `json:"state,omitempty"`
+}
+
+func (ChatStateChangedEvent) EventType() string { return "chat_state_changed" }
+
+type ChatState struct {
+	Archived   bool     `json:"archived"`
+	Pinned     bool     `json:"pinned"`
+	Muted      bool     `json:"muted"`
+	Labels     []string `json:"labels"`
+	MutedUntil int64    `json:"muted_until,omitempty"`
+}
@@ -0,0 +1,169 @@
+package webhooks
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is stamped on every payload. Bump it whenever a field is
+// added to an event and tag the field with the new version.
+//
+// Version history:
+//  1. initial event schemas
+//  2. chat on group_info and joined_group
+const SchemaVersion = 2
+
+// eventTypes maps every event type to its data struct
+var eventTypes = map[string]EventData{
+	"message":               MessageEvent{},
+	"receipt":               ReceiptEvent{},
+	"delivery":              DeliveryEvent{},
+	"read":                  ReadEvent{},
+	"status":                StatusEvent{},
+	"error":                 ErrorEvent{},
+	"qr":                    QREvent{},
+	"pair_success":          PairSuccessEvent{},
+	"group_info":            GroupInfoEvent{},
+	"joined_group":          JoinedGroupEvent{},
+	"call_offer":            CallEvent{},
+	"call_accept":           CallEvent{},
+	"call_terminate":        CallEvent{},
+	"call_reject":           CallEvent{},
+	"call_auto_rejected":    CallAutoRejectedEvent{},
+	"history_sync_progress": HistorySyncProgressEvent{},
+	"contact_updated":       ContactUpdatedEvent{},
+	"undecryptable_message": UndecryptableMessageEvent{},
+	"identity_changed":      IdentityChangedEvent{},
+	"decrypt_recovered":     DecryptRecoveredEvent{},
+	"label_updated":         LabelUpdatedEvent{},
+	"chat_state_changed":    ChatStateChangedEvent{},
+}
+
+// EventTypes returns every event type with a schema, sorted
+func EventTypes() []string {
+	types := make([]string, 0, len(eventTypes))
+	for eventType := range eventTypes {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// JSONSchema returns the JSON Schema of the payload of an event type as of a
+// schema version. Schemas allow additional properties, so payloads of newer
+// versions still validate against the schema an older consumer was built for.
+func JSONSchema(eventType string, version int) (map[string]interface{}, error) {
+	data, ok := eventTypes[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+	if version < 1 || version > SchemaVersion {
+		return nil, fmt.Errorf("unknown schema version %d", version)
+	}
+
+	dataSchema := structSchema(reflect.TypeOf(data), version)
+	if props, ok := dataSchema["properties"].(map[string]interface{}); ok {
+		if event, ok := props["event"].(map[string]interface{}); ok {
+			event["const"] = eventType
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     fmt.Sprintf("/v1/schemas/%d/%s", version, eventType),
+		"title":   fmt.Sprintf("%s webhook payload (schema version %d)", eventType, version),
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"event_type":     map[string]interface{}{"const": eventType},
+			"schema_version": map[string]interface{}{"type": "integer", "minimum": version},
+			"wa_account_id":  map[string]interface{}{"type": "string"},
+			"tenant_id":      map[string]interface{}{"type": "string"},
+			"timestamp":      map[string]interface{}{"type": "string", "format": "date-time"},
+			"request_id":     map[string]interface{}{"type": "string"},
+			"data":           dataSchema,
+		},
+		"required":             []string{"event_type", "schema_version", "wa_account_id", "timestamp", "request_id", "data"},
+		"additionalProperties": true,
+	}, nil
+}
+
+func structSchema(t reflect.Type, version int) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		tags := schemaTags(field.Tag.Get("schema"))
+		if since, err := strconv.Atoi(tags["since"]); err == nil && since > version {
+			continue
+		}
+
+		prop := typeSchema(field.Type, version)
+		if enum, ok := tags["enum"]; ok {
+			prop["enum"] = strings.Split(enum, "|")
+		}
+		properties[name] = prop
+
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": true,
+	}
+}
+
+func typeSchema(t reflect.Type, version int) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), version)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// nil slices and maps are encoded as null
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": typeSchema(t.Elem(), version)}
+	case reflect.Map:
+		return map[string]interface{}{"type": []string{"object", "null"}}
+	case reflect.Struct:
+		return structSchema(t, version)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// schemaTags parses a schema:"since=2,enum=a|b" struct tag
+func schemaTags(tag string) map[string]string {
+	tags := map[string]string{}
+	for _, part := range strings.Split(tag, ",") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			tags[key] = value
+		}
+	}
+	return tags
+}
@@ -12,7 +12,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,56 +24,177 @@ import (
 type Sender struct {
 	baseURL       string
 	signingSecret string
+	format        Format
+	eventSource   string
 	httpClient    *http.Client
+	recorder      DeliveryRecorder
 }
 
 func NewSender(baseURL, signingSecret string) *Sender {
 	return &Sender{
 		baseURL:       baseURL,
 		signingSecret: signingSecret,
+		format:        FormatNative,
+		eventSource:   DefaultEventSource,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
 }
 
+// SetFormat sets the format of the events sent to the Laravel webhook endpoints
+func (s *Sender) SetFormat(format Format) {
+	s.format = format
+}
+
+// SetTransport sets the transport of the deliveries, like one that refuses
+// internal addresses for endpoints registered through the API
+func (s *Sender) SetTransport(transport http.RoundTripper) {
+	s.httpClient.Transport = transport
+}
+
+// SetEventSource sets the CloudEvents source attribute of every event sent
+func (s *Sender) SetEventSource(source string) {
+	s.eventSource = source
+}
+
 type WebhookPayload struct {
-	EventType   string                 `json:"event_type"`
-	WaAccountID string                 `json:"wa_account_id"`
-	TenantID    string                 `json:"tenant_id,omitempty"`
-	Timestamp   time.Time              `json:"timestamp"`
-	Data        map[string]interface{} `json:"data"`
-	RequestID   string                 `json:"request_id"`
+	EventType     string    `json:"event_type"`
+	SchemaVersion int       `json:"schema_version"`
+	WaAccountID   string    `json:"wa_account_id"`
+	TenantID      string    `json:"tenant_id,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	Data          EventData `json:"data"`
+	RequestID     string    `json:"request_id"`
+}
+
+// NewPayload wraps typed event data in a payload of the current schema version
+func NewPayload(waAccountID, tenantID string, data EventData) WebhookPayload {
+	return WebhookPayload{
+		EventType:     data.EventType(),
+		SchemaVersion: SchemaVersion,
+		WaAccountID:   waAccountID,
+		TenantID:      tenantID,
+		Data:          data,
+	}
+}
+
+// Fields returns the event data as a generic map, for consumers that match
+// on field values regardless of the event type
+func (p WebhookPayload) Fields() map[string]interface{} {
+	fields := map[string]interface{}{}
+	raw, err := json.Marshal(p.Data)
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return map[string]interface{}{}
+	}
+	return fields
 }
 
 func (s *Sender) Send(endpoint string, payload WebhookPayload) error {
-	if payload.RequestID == "" {
-		payload.RequestID = uuid.New().String()
+	return s.SendToURL(s.endpointURL(endpoint), s.signingSecret, s.format, payload)
+}
+
+// endpointURL returns the URL of a Laravel webhook endpoint
+func (s *Sender) endpointURL(endpoint string) string {
+	return fmt.Sprintf("%s/%s", s.baseURL, endpoint)
+}
+
+// SendToURL delivers a payload in the given format to an arbitrary URL signed
+// with the given secret, used for webhook subscriptions
+func (s *Sender) SendToURL(url, secret string, format Format, payload WebhookPayload) error {
+	payload = withDefaults(payload)
+
+	body, headers, err := s.encode(format, payload)
+	if err != nil {
+		return err
 	}
-	if payload.Timestamp.IsZero() {
-		payload.Timestamp = time.Now()
+	headers["X-Request-ID"] = payload.RequestID
+
+	return s.post(url, secret, format, body, headers, []WebhookPayload{payload}, false)
+}
+
+// SendBatchToURL delivers several payloads as one signed JSON array, in the
+// given order. The X-Request-ID header identifies the batch, every payload
+// keeps its own request ID. CloudEvents binary mode has no batches, so the
+// payloads are sent one by one in that format.
+func (s *Sender) SendBatchToURL(url, secret string, format Format, payloads []WebhookPayload) error {
+	if format == FormatCloudEventsBinary {
+		var firstErr error
+		for _, payload := range payloads {
+			if err := s.SendToURL(url, secret, format, payload); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	batch := make([]WebhookPayload, len(payloads))
+	for i, payload := range payloads {
+		batch[i] = withDefaults(payload)
+	}
+
+	body, headers, err := s.encodeBatch(format, batch)
+	if err != nil {
+		return err
+	}
+	headers["X-Request-ID"] = uuid.New().String()
+	headers["X-WA-Batch-Size"] = strconv.Itoa(len(batch))
+
+	return s.post(url, secret, format, body, headers, batch, true)
+}
+
+// post signs and sends the body of the given payloads, then hands the
+// attempt to the recorder
+func (s *Sender) post(url, secret string, format Format, body []byte, headers map[string]string, payloads []WebhookPayload, batch bool) error {
+	signature := sign(secret, body)
+	headers["X-WA-Signature"] = signature
+
+	delivery := Delivery{
+		URL:         url,
+		RequestID:   headers["X-Request-ID"],
+		Format:      format,
+		Headers:     headers,
+		Payload:     body,
+		Signature:   signature,
+		AttemptedAt: time.Now(),
+	}
+	delivery.WaAccountIDs, delivery.EventTypes = payloadScope(payloads)
+	delivery.RequestIDs = deliveryRequestIDs(delivery.RequestID, payloads)
+
+	eventType := payloads[0].EventType
+	if batch {
+		delivery.BatchSize = len(payloads)
+		eventType = "batch"
 	}
 
-	jsonData, err := json.Marshal(payload)
+	err := s.do(&delivery, eventType)
+	delivery.Latency = time.Since(delivery.AttemptedAt)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		delivery.Error = err.Error()
+	}
+	if s.recorder != nil {
+		s.recorder.RecordDelivery(delivery)
 	}
 
-	// Create HMAC signature
-	signature := s.sign(jsonData)
+	return err
+}
 
-	// Build full URL
-	url := fmt.Sprintf("%s/%s", s.baseURL, endpoint)
+// do performs the request of a delivery and fills in the response
+func (s *Sender) do(delivery *Delivery, eventType string) error {
+	url := delivery.URL
 
 	// Create request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(delivery.Payload))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-WA-Signature", signature)
-	req.Header.Set("X-Request-ID", payload.RequestID)
+	for name, value := range delivery.Headers {
+		req.Header.Set(name, value)
+	}
 
 	// Send request
 	resp, err := s.httpClient.Do(req)
@@ -79,90 +202,104 @@ func (s *Sender) Send(endpoint string, payload WebhookPayload) error {
 		log.Error().
 			Err(err).
 			Str("url", url).
-			Str("event_type", payload.EventType).
+			Str("event_type", eventType).
 			Msg("Failed to send webhook")
 		return fmt.Errorf("failed to send webhook: %w", err)
 	}
 	defer resp.Body.Close()
 
+	delivery.StatusCode = resp.StatusCode
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, responseSnippetSize))
+	delivery.Response = string(snippet)
+
 	if resp.StatusCode >= 300 {
 		log.Warn().
 			Int("status", resp.StatusCode).
 			Str("url", url).
-			Str("event_type", payload.EventType).
+			Str("event_type", eventType).
 			Msg("Webhook returned non-2xx status")
 		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
 	}
 
 	log.Info().
 		Str("url", url).
-		Str("event_type", payload.EventType).
+		Str("event_type", eventType).
 		Int("status", resp.StatusCode).
 		Msg("Webhook sent successfully")
 
 	return nil
 }
 
-func (s *Sender) sign(data []byte) string {
-	h := hmac.New(sha256.New, []byte(s.signingSecret))
+// withDefaults fills in the request ID, timestamp and schema version of a
+// payload that wasn't stamped by the event dispatcher
+func withDefaults(payload WebhookPayload) WebhookPayload {
+	if payload.RequestID == "" {
+		payload.RequestID = uuid.New().String()
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if payload.SchemaVersion == 0 {
+		payload.SchemaVersion = SchemaVersion
+	}
+	return payload
+}
+
+func sign(secret string, data []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
 	h.Write(data)
 	return "sha256=" + hex.EncodeToString(h.Sum(nil))
 }
 
 // Convenience methods for specific webhook types
 
-func (s *Sender) SendInbound(waAccountID, tenantID string, message map[string]interface{}) error {
-	return s.Send("inbound", WebhookPayload{
-		EventType:   "inbound",
-		WaAccountID: waAccountID,
-		TenantID:    tenantID,
-		Data:        message,
-	})
+func (s *Sender) SendInbound(waAccountID, tenantID string, message MessageEvent) error {
+	return s.Send("inbound", InboundPayload(waAccountID, tenantID, message))
 }
 
-func (s *Sender) SendDelivery(waAccountID, tenantID, messageID string) error {
-	return s.Send("delivery", WebhookPayload{
-		EventType:   "delivery",
-		WaAccountID: waAccountID,
-		TenantID:    tenantID,
-		Data: map[string]interface{}{
-			"message_id": messageID,
-			"status":     "delivered",
-		},
+func (s *Sender) SendStatus(waAccountID, status, message string) error {
+	return s.Send("status", StatusPayload(waAccountID, status, message))
+}
+
+func (s *Sender) SendError(waAccountID, tenantID, errorCode, errorMessage string, context map[string]interface{}) error {
+	return s.Send("errors", ErrorPayload(waAccountID, tenantID, errorCode, errorMessage, context))
+}
+
+// Payload builders, shared with other event sinks so every consumer sees the same shape
+
+func InboundPayload(waAccountID, tenantID string, message MessageEvent) WebhookPayload {
+	message.Event = message.EventType()
+	return NewPayload(waAccountID, tenantID, message)
+}
+
+func DeliveryPayload(waAccountID, tenantID, messageID, chatJID, recipientJID string) WebhookPayload {
+	return NewPayload(waAccountID, tenantID, DeliveryEvent{
+		MessageID: messageID,
+		Chat:      chatJID,
+		Recipient: recipientJID,
+		Status:    "delivered",
 	})
 }
 
-func (s *Sender) SendRead(waAccountID, tenantID, messageID string) error {
-	return s.Send("read", WebhookPayload{
-		EventType:   "read",
-		WaAccountID: waAccountID,
-		TenantID:    tenantID,
-		Data: map[string]interface{}{
-			"message_id": messageID,
-		},
+func ReadPayload(waAccountID, tenantID, messageID, chatJID, recipientJID string) WebhookPayload {
+	return NewPayload(waAccountID, tenantID, ReadEvent{
+		MessageID: messageID,
+		Chat:      chatJID,
+		Recipient: recipientJID,
 	})
 }
 
-func (s *Sender) SendStatus(waAccountID, status, message string) error {
-	return s.Send("status", WebhookPayload{
-		EventType:   "status",
-		WaAccountID: waAccountID,
-		Data: map[string]interface{}{
-			"status":  status,
-			"message": message,
-		},
+func StatusPayload(waAccountID, status, message string) WebhookPayload {
+	return NewPayload(waAccountID, "", StatusEvent{
+		Status:  status,
+		Message: message,
 	})
 }
 
-func (s *Sender) SendError(waAccountID, tenantID, errorCode, errorMessage string, context map[string]interface{}) error {
-	return s.Send("errors", WebhookPayload{
-		EventType:   "error",
-		WaAccountID: waAccountID,
-		TenantID:    tenantID,
-		Data: map[string]interface{}{
-			"error_code":    errorCode,
-			"error_message": errorMessage,
-			"context":       context,
-		},
+func ErrorPayload(waAccountID, tenantID, errorCode, errorMessage string, context map[string]interface{}) WebhookPayload {
+	return NewPayload(waAccountID, tenantID, ErrorEvent{
+		ErrorCode:    errorCode,
+		ErrorMessage: errorMessage,
+		Context:      context,
 	})
 }